@@ -23,8 +23,10 @@ import (
 // Badger provider type.
 type Badger struct {
 	*badger.DB
-	stale  time.Duration
-	logger core.Logger
+	stale          time.Duration
+	logger         core.Logger
+	vlogGCInterval time.Duration
+	vlogGCRatio    float64
 }
 
 var (
@@ -46,6 +48,34 @@ func Factory(badgerConfiguration core.CacheProvider, logger core.Logger, stale t
 	badgerOptions.SyncWrites = true
 	badgerOptions.MemTableSize = 64 << 22
 
+	// Value-log GC is disabled unless configured. MemTableSize,
+	// BlockCacheSize and Compression are merged below from the user
+	// configuration directly into the badger options.
+	var vlogGCInterval time.Duration
+
+	vlogGCRatio := 0.5
+
+	if badgerConfig, ok := badgerConfiguration.Configuration.(map[string]interface{}); ok && badgerConfig != nil {
+		if value, ok := badgerConfig["vlogGCInterval"]; ok {
+			if v, ok := value.(string); ok {
+				if d, err := time.ParseDuration(v); err == nil {
+					vlogGCInterval = d
+				} else {
+					logger.Errorf("Impossible to parse vlogGCInterval %q: %v", v, err)
+				}
+			}
+		}
+
+		if value, ok := badgerConfig["vlogGCRatio"]; ok {
+			switch v := value.(type) {
+			case float64:
+				vlogGCRatio = v
+			case int:
+				vlogGCRatio = float64(v)
+			}
+		}
+	}
+
 	if badgerConfiguration.Configuration != nil {
 		var parsedBadger badger.Options
 		if b, e := json.Marshal(badgerConfiguration.Configuration); e == nil {
@@ -90,7 +120,7 @@ func Factory(badgerConfiguration core.CacheProvider, logger core.Logger, stale t
 		logger.Error("Impossible to open the Badger DB.", e)
 	}
 
-	i := &Badger{DB: db, logger: logger, stale: stale}
+	i := &Badger{DB: db, logger: logger, stale: stale, vlogGCInterval: vlogGCInterval, vlogGCRatio: vlogGCRatio}
 	enabledBadgerInstances.Store(uid, i)
 
 	return i, nil
@@ -265,7 +295,7 @@ func (provider *Badger) SetMultiLevel(baseKey, variedKey string, value []byte, v
 			})
 		}
 
-		val, err = core.MappingUpdater(variedKey, val, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+		val, err = core.MappingUpdaterWithLastModified(variedKey, val, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, core.ResponseLastModified(value), realKey)
 		if err != nil {
 			return err
 		}
@@ -324,10 +354,45 @@ func (provider *Badger) DeleteMany(key string) {
 
 		return nil
 	})
+
+	core.CleanMappings(provider, rgKey.MatchString, provider.logger)
+}
+
+// RunValueLogGC triggers value-log GC passes until there is nothing left to
+// rewrite, so admin endpoints can reclaim disk space on demand.
+func (provider *Badger) RunValueLogGC(ratio float64) error {
+	var err error
+
+	for err == nil {
+		err = provider.DB.RunValueLogGC(ratio)
+	}
+
+	if errors.Is(err, badger.ErrNoRewrite) {
+		return nil
+	}
+
+	return err
+}
+
+// valueLogGCLoop periodically reclaims space from the value log so disk usage
+// stays bounded under heavy churn.
+func (provider *Badger) valueLogGCLoop() {
+	ticker := time.NewTicker(provider.vlogGCInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := provider.RunValueLogGC(provider.vlogGCRatio); err != nil {
+			provider.logger.Debugf("Value-log GC pass ended: %v", err)
+		}
+	}
 }
 
 // Init method will.
 func (provider *Badger) Init() error {
+	if provider.vlogGCInterval > 0 && !provider.DB.Opts().InMemory {
+		go provider.valueLogGCLoop()
+	}
+
 	return nil
 }
 
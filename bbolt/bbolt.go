@@ -0,0 +1,398 @@
+package bbolt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	lz4 "github.com/pierrec/lz4/v4"
+	bolt "go.etcd.io/bbolt"
+)
+
+var bboltInstanceMap = sync.Map{}
+
+// Bbolt provider type.
+type Bbolt struct {
+	*bolt.DB
+	stale  time.Duration
+	logger core.Logger
+	uuid   string
+}
+
+const (
+	// mappingsBucket holds the IDX_ mapping keys, separate from the entry
+	// buckets so elections and key listings stay one bucket cursor away.
+	mappingsBucket = "souin-mappings"
+	// defaultBucket holds the keys that do not follow the usual
+	// method-scheme-host shape.
+	defaultBucket = "souin-bucket"
+	// expiryPrefixLen is the size of the big endian expires-at (unix
+	// nanoseconds) prefix stored before each value; zero means no expiry.
+	expiryPrefixLen = 8
+	// cleanupInterval is the pause between two expired keys cleanup passes.
+	cleanupInterval = time.Minute
+	// cleanupBatch bounds the deletions per cleanup pass so the single
+	// writer lock is never held long.
+	cleanupBatch = 4096
+)
+
+// bucketFor returns the bucket name holding the given key: one bucket per
+// method-scheme-host prefix group, plus a dedicated bucket for the mappings.
+func bucketFor(key string) []byte {
+	if strings.Contains(key, core.MappingKeyPrefix) {
+		return []byte(mappingsBucket)
+	}
+
+	parts := strings.SplitN(key, "-", 4)
+	if len(parts) < 4 {
+		return []byte(defaultBucket)
+	}
+
+	return []byte("souin-" + strings.Join(parts[:3], "-"))
+}
+
+// encodeValue prepends the expires-at prefix to the payload.
+func encodeValue(payload []byte, duration time.Duration) []byte {
+	value := make([]byte, expiryPrefixLen+len(payload))
+	if duration > 0 {
+		binary.BigEndian.PutUint64(value, uint64(time.Now().Add(duration).UnixNano())) //nolint:gosec
+	}
+
+	copy(value[expiryPrefixLen:], payload)
+
+	return value
+}
+
+// decodeValue strips the expires-at prefix, reporting whether the value is
+// still alive.
+func decodeValue(value []byte) ([]byte, bool) {
+	if len(value) < expiryPrefixLen {
+		return nil, false
+	}
+
+	expiry := binary.BigEndian.Uint64(value)
+	if expiry != 0 && uint64(time.Now().UnixNano()) > expiry { //nolint:gosec
+		return nil, false
+	}
+
+	return value[expiryPrefixLen:], true
+}
+
+// Factory function create new Bbolt instance.
+func Factory(bboltConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	path := "/tmp/souin-bbolt.db"
+	if bboltConfiguration.Path != "" {
+		path = bboltConfiguration.Path
+	}
+
+	if bboltConfig, ok := bboltConfiguration.Configuration.(map[string]interface{}); ok && bboltConfig != nil {
+		if value, ok := bboltConfig["path"]; ok {
+			if v, ok := value.(string); ok {
+				path = v
+			}
+		}
+	}
+
+	// bbolt locks the database file, share one handle per path.
+	if instance, ok := bboltInstanceMap.Load(path); ok && instance != nil {
+		return &Bbolt{
+			DB:     instance.(*bolt.DB),
+			stale:  stale,
+			logger: logger,
+			uuid:   fmt.Sprintf("%s-%s", path, stale),
+		}, nil
+	}
+
+	database, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		logger.Error("Impossible to open the Bbolt DB.", err)
+
+		return nil, err
+	}
+
+	instance := &Bbolt{
+		DB:     database,
+		stale:  stale,
+		logger: logger,
+		uuid:   fmt.Sprintf("%s-%s", path, stale),
+	}
+	bboltInstanceMap.Store(path, instance.DB)
+
+	instance.startCleanup()
+
+	return instance, nil
+}
+
+// startCleanup launches the background loop removing expired keys, batched
+// per pass to keep the writer lock short.
+func (provider *Bbolt) startCleanup() {
+	go func() {
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			provider.cleanupExpired()
+		}
+	}()
+}
+
+// cleanupExpired removes up to cleanupBatch expired keys in one transaction.
+func (provider *Bbolt) cleanupExpired() {
+	removed := 0
+
+	_ = provider.DB.Update(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(_ []byte, bucket *bolt.Bucket) error {
+			cursor := bucket.Cursor()
+			for k, v := cursor.First(); k != nil && removed < cleanupBatch; k, v = cursor.Next() {
+				if _, alive := decodeValue(v); !alive {
+					_ = cursor.Delete()
+					removed++
+				}
+			}
+
+			return nil
+		})
+	})
+
+	if removed > 0 {
+		provider.logger.Debugf("Removed %d expired keys from Bbolt", removed)
+	}
+}
+
+// Name returns the storer name.
+func (provider *Bbolt) Name() string {
+	return "BBOLT"
+}
+
+// Uuid returns an unique identifier.
+func (provider *Bbolt) Uuid() string {
+	return provider.uuid
+}
+
+// ListKeys method returns the list of existing keys.
+func (provider *Bbolt) ListKeys() []string {
+	keys := []string{}
+
+	_ = provider.DB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(mappingsBucket))
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(_, v []byte) error {
+			payload, alive := decodeValue(v)
+			if !alive {
+				return nil
+			}
+
+			mapping, err := core.DecodeMapping(payload)
+			if err == nil {
+				for _, v := range mapping.GetMapping() {
+					keys = append(keys, v.GetRealKey())
+				}
+			}
+
+			return nil
+		})
+	})
+
+	return keys
+}
+
+// MapKeys method returns the map of existing keys.
+func (provider *Bbolt) MapKeys(prefix string) map[string]string {
+	keys := map[string]string{}
+
+	_ = provider.DB.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(_ []byte, bucket *bolt.Bucket) error {
+			return bucket.ForEach(func(k, v []byte) error {
+				key := string(k)
+				if !strings.HasPrefix(key, prefix) {
+					return nil
+				}
+
+				payload, alive := decodeValue(v)
+				if !alive {
+					return nil
+				}
+
+				nk, _ := strings.CutPrefix(key, prefix)
+				keys[nk] = string(payload)
+
+				return nil
+			})
+		})
+	})
+
+	return keys
+}
+
+// Get method returns the populated response if exists, empty response then.
+func (provider *Bbolt) Get(key string) []byte {
+	var item []byte
+
+	_ = provider.DB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketFor(key))
+		if bucket == nil {
+			return nil
+		}
+
+		if payload, alive := decodeValue(bucket.Get([]byte(key))); alive {
+			item = make([]byte, len(payload))
+			copy(item, payload)
+		}
+
+		return nil
+	})
+
+	return item
+}
+
+// Set method will store the response in Bbolt provider.
+func (provider *Bbolt) Set(key string, value []byte, duration time.Duration) error {
+	err := provider.DB.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(bucketFor(key))
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(key), encodeValue(value, duration))
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s into Bbolt, %v", key, err)
+	}
+
+	return err
+}
+
+// Delete method will delete the response in Bbolt provider if exists
+// corresponding to key param.
+func (provider *Bbolt) Delete(key string) {
+	_ = provider.DB.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketFor(key))
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.Delete([]byte(key))
+	})
+}
+
+// DeleteMany method will delete the responses in Bbolt provider if exists
+// corresponding to the regex key param.
+func (provider *Bbolt) DeleteMany(key string) {
+	rgKey, e := regexp.Compile(key)
+	if e != nil {
+		return
+	}
+
+	_ = provider.DB.Update(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(_ []byte, bucket *bolt.Bucket) error {
+			cursor := bucket.Cursor()
+			for k, _ := cursor.First(); k != nil; k, _ = cursor.Next() {
+				if rgKey.Match(k) {
+					_ = cursor.Delete()
+				}
+			}
+
+			return nil
+		})
+	})
+
+	core.CleanMappings(provider, rgKey.MatchString, provider.logger)
+}
+
+// Init method will.
+func (provider *Bbolt) Init() error {
+	return nil
+}
+
+// Reset method will reset or close provider.
+func (provider *Bbolt) Reset() error {
+	return provider.DB.Update(func(tx *bolt.Tx) error {
+		names := [][]byte{}
+
+		if err := tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			names = append(names, append([]byte{}, name...))
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
+func (provider *Bbolt) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	value := provider.Get(core.MappingKeyPrefix + key)
+	if len(value) == 0 {
+		return fresh, stale
+	}
+
+	fresh, stale, _ = core.MappingElection(provider, value, req, validator, provider.logger)
+
+	return fresh, stale
+}
+
+// SetMultiLevel tries to store the key with the given value and update the
+// mapping key to store metadata. Both writes share one transaction, so a
+// crash can never leave a mapping pointing at a missing entry.
+func (provider *Bbolt) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	now := time.Now()
+
+	compressed := new(bytes.Buffer)
+	if _, err := lz4.NewWriter(compressed).ReadFrom(bytes.NewReader(value)); err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into Bbolt, %v", variedKey, err)
+
+		return err
+	}
+
+	err := provider.DB.Update(func(tx *bolt.Tx) error {
+		entryBucket, err := tx.CreateBucketIfNotExists(bucketFor(variedKey))
+		if err != nil {
+			return err
+		}
+
+		if err := entryBucket.Put([]byte(variedKey), encodeValue(compressed.Bytes(), duration+provider.stale)); err != nil {
+			return err
+		}
+
+		mappingBucket, err := tx.CreateBucketIfNotExists([]byte(mappingsBucket))
+		if err != nil {
+			return err
+		}
+
+		mappingKey := core.MappingKeyPrefix + baseKey
+
+		var item []byte
+		if payload, alive := decodeValue(mappingBucket.Get([]byte(mappingKey))); alive {
+			item = payload
+		}
+
+		val, err := core.MappingUpdaterWithLastModified(variedKey, item, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, core.ResponseLastModified(value), realKey)
+		if err != nil {
+			return err
+		}
+
+		return mappingBucket.Put([]byte(mappingKey), encodeValue(val, 0))
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to set value into Bbolt, %v", err)
+	}
+
+	return err
+}
@@ -0,0 +1,99 @@
+package bbolt_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/darkweak/storages/bbolt"
+	"github.com/darkweak/storages/core"
+	"go.uber.org/zap"
+)
+
+const (
+	byteKey        = "MyByteKey"
+	nonExistentKey = "NonExistentKey"
+	baseValue      = "My first data"
+)
+
+func getBboltInstance(t *testing.T) (core.Storer, error) {
+	t.Helper()
+
+	return bbolt.Factory(core.CacheProvider{Path: filepath.Join(os.TempDir(), "souin-bbolt-test.db")}, zap.NewNop().Sugar(), 0)
+}
+
+func TestBboltConnectionFactory(t *testing.T) {
+	instance, err := getBboltInstance(t)
+
+	if nil != err {
+		t.Error("Shouldn't have panic")
+	}
+
+	if nil == instance {
+		t.Error("Bbolt should be instanciated")
+	}
+
+	if nil == instance.(*bbolt.Bbolt).DB {
+		t.Error("Bbolt database should be accesible")
+	}
+}
+
+func TestIShouldBeAbleToReadAndWriteDataInBbolt(t *testing.T) {
+	client, _ := getBboltInstance(t)
+
+	_ = client.Set("Test", []byte(baseValue), time.Duration(20)*time.Second)
+
+	res := client.Get("Test")
+	if len(res) == 0 {
+		t.Errorf("Key %s should exist", baseValue)
+	}
+
+	if baseValue != string(res) {
+		t.Errorf("%s not corresponding to %s", string(res), baseValue)
+	}
+}
+
+func TestBbolt_GetRequestInCache(t *testing.T) {
+	client, _ := getBboltInstance(t)
+	res := client.Get(nonExistentKey)
+
+	if 0 < len(res) {
+		t.Errorf("Key %s should not exist", nonExistentKey)
+	}
+}
+
+func TestBbolt_GetSetRequestInCache_OneByte(t *testing.T) {
+	client, _ := getBboltInstance(t)
+	_ = client.Set(byteKey, []byte("A"), time.Duration(20)*time.Second)
+
+	res := client.Get(byteKey)
+	if len(res) == 0 {
+		t.Errorf("Key %s should exist", byteKey)
+	}
+
+	if string(res) != "A" {
+		t.Errorf("%s not corresponding to %v", res, 65)
+	}
+}
+
+func TestBbolt_DeleteRequestInCache(t *testing.T) {
+	client, _ := getBboltInstance(t)
+	_ = client.Set(byteKey, []byte("A"), time.Duration(20)*time.Second)
+
+	client.Delete(byteKey)
+
+	if res := client.Get(byteKey); 0 < len(res) {
+		t.Errorf("Key %s should not exist", byteKey)
+	}
+}
+
+func TestBbolt_ExpiredKeyIsNotServed(t *testing.T) {
+	client, _ := getBboltInstance(t)
+	_ = client.Set("ExpiredKey", []byte(baseValue), time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+
+	if res := client.Get("ExpiredKey"); 0 < len(res) {
+		t.Error("Key ExpiredKey should have expired")
+	}
+}
@@ -0,0 +1,55 @@
+package caddy
+
+import (
+	"net/http"
+
+	caddy "github.com/caddyserver/caddy/v2"
+	"github.com/caddyserver/caddy/v2/modules/caddyhttp"
+	"github.com/darkweak/storages/bbolt"
+	"github.com/darkweak/storages/core"
+)
+
+const moduleName = "bbolt"
+
+// Bbolt storage.
+type Bbolt struct {
+	// Keep the handler configuration.
+	core.Configuration
+}
+
+//nolint:gochecknoinits
+func init() {
+	caddy.RegisterModule(Bbolt{})
+}
+
+// CaddyModule returns the Caddy module information.
+func (Bbolt) CaddyModule() caddy.ModuleInfo {
+	return caddy.ModuleInfo{
+		ID:  "storages.cache.bbolt",
+		New: func() caddy.Module { return new(Bbolt) },
+	}
+}
+
+// Provision to do the provisioning part.
+func (b *Bbolt) Provision(ctx caddy.Context) error {
+	logger := ctx.Logger(b)
+	storer, err := bbolt.Factory(b.Configuration.Provider, logger.Sugar(), b.Configuration.Stale)
+
+	if err != nil {
+		return err
+	}
+
+	core.RegisterStorage(storer)
+
+	return nil
+}
+
+func (b *Bbolt) ServeHTTP(rw http.ResponseWriter, rq *http.Request, next caddyhttp.Handler) error {
+	return next.ServeHTTP(rw, rq)
+}
+
+// Interface guards.
+var (
+	_ caddy.Provisioner           = (*Bbolt)(nil)
+	_ caddyhttp.MiddlewareHandler = (*Bbolt)(nil)
+)
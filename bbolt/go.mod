@@ -0,0 +1,18 @@
+module github.com/darkweak/storages/bbolt
+
+go 1.22.1
+
+replace github.com/darkweak/storages/core => ../core
+
+require (
+	github.com/darkweak/storages/core v0.0.13
+	github.com/pierrec/lz4/v4 v4.1.22
+	go.etcd.io/bbolt v1.4.0
+	go.uber.org/zap v1.27.0
+)
+
+require (
+	go.uber.org/multierr v1.10.0 // indirect
+	golang.org/x/sys v0.32.0 // indirect
+	google.golang.org/protobuf v1.36.5 // indirect
+)
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pierrec/lz4/v4"
+)
+
+// analyzedEntry describes a single file of the cache directory.
+type analyzedEntry struct {
+	name             string
+	size             int64
+	uncompressedSize int64
+}
+
+// runAnalyze scans a cache directory and reports the largest entries, the
+// compression ratio and the total usage, as input for the TTL and size tuning.
+func runAnalyze(args []string) error {
+	flags := flag.NewFlagSet("analyze", flag.ExitOnError)
+	path := flags.String("path", ".", "simplefs storage directory")
+	top := flags.Int("top", 20, "number of largest entries to report")
+	compression := flags.String("compression", "lz4", "compression method used by the storage (lz4, gzip, none)")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	files, err := os.ReadDir(*path)
+	if err != nil {
+		return err
+	}
+
+	var entries []analyzedEntry
+
+	var totalSize int64
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, analyzedEntry{name: file.Name(), size: info.Size()})
+		totalSize += info.Size()
+	}
+
+	// Sort by size, descending.
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].size > entries[j].size
+	})
+
+	if *top < len(entries) {
+		entries = entries[:*top]
+	}
+
+	// Only compute the uncompressed size for the reported entries.
+	for i := range entries {
+		entries[i].uncompressedSize = uncompressedSize(filepath.Join(*path, entries[i].name), *compression)
+	}
+
+	fmt.Printf("Directory %s: %d files, %s total\n\n", *path, len(files), humanize.IBytes(uint64(totalSize)))
+	fmt.Printf("%-12s %-12s %-8s %s\n", "size", "expanded", "ratio", "file")
+
+	for _, entry := range entries {
+		ratio := "-"
+		if entry.uncompressedSize > 0 {
+			ratio = fmt.Sprintf("%.2f", float64(entry.size)/float64(entry.uncompressedSize))
+		}
+
+		uncompressed := "-"
+		if entry.uncompressedSize > 0 {
+			uncompressed = humanize.IBytes(uint64(entry.uncompressedSize))
+		}
+
+		fmt.Printf("%-12s %-12s %-8s %s\n", humanize.IBytes(uint64(entry.size)), uncompressed, ratio, entry.name)
+	}
+
+	return nil
+}
+
+// uncompressedSize returns the size of the file once expanded with the given
+// compression method, 0 on failure.
+func uncompressedSize(path, compression string) int64 {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	switch compression {
+	case "lz4":
+		n, err := io.Copy(io.Discard, lz4.NewReader(bytes.NewReader(content)))
+		if err != nil {
+			return 0
+		}
+
+		return n
+	case "gzip":
+		reader, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return 0
+		}
+
+		defer reader.Close()
+
+		n, err := io.Copy(io.Discard, reader)
+		if err != nil {
+			return 0
+		}
+
+		return n
+	default:
+		return int64(len(content))
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/WJQSERVER/souin-storages/simplefs"
+)
+
+// runCheck runs the startup self-test on the target provider: it writes,
+// reads back and deletes a probe entry, then reports the read-write latency,
+// the permissions and the free space, exposing configuration or disk issues
+// before going live.
+func runCheck(args []string) error {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	dsn := flags.String("path", "", "provider DSN, e.g. simplefs:/var/cache/souin")
+	stale := flags.Duration("stale", time.Hour, "stale duration passed to the provider")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *dsn == "" {
+		return fmt.Errorf("a provider DSN must be given with -path")
+	}
+
+	storer, err := openProvider(*dsn, *stale)
+	if err != nil {
+		return err
+	}
+
+	provider, ok := storer.(*simplefs.Simplefs)
+	if !ok {
+		return fmt.Errorf("check only supports the simplefs provider for now")
+	}
+
+	report, err := provider.SelfTest()
+
+	payload, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Fprintln(os.Stdout, string(payload))
+
+	if err != nil {
+		return fmt.Errorf("the self-test failed: %w", err)
+	}
+
+	fmt.Fprintln(os.Stdout, "self-test passed")
+
+	return nil
+}
@@ -0,0 +1,117 @@
+// storage-exporter opens a storage provider read-only and exposes its
+// statistics in the Prometheus text format on a dedicated port, for
+// deployments where the Caddy metrics endpoint cannot be reached by the
+// monitoring system.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/WJQSERVER/souin-storages/simplefs"
+	"github.com/darkweak/storages/core"
+	"go.uber.org/zap"
+)
+
+// exporter holds the observed provider.
+type exporter struct {
+	provider *simplefs.Simplefs
+	path     string
+}
+
+// directorySize computes the total bytes of the storage directory.
+func (e *exporter) directorySize() int64 {
+	var total int64
+
+	files, err := os.ReadDir(e.path)
+	if err != nil {
+		return 0
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		if info, err := file.Info(); err == nil {
+			total += info.Size()
+		}
+	}
+
+	return total
+}
+
+// metricsHandler collects the statistics on each scrape and writes them in
+// the Prometheus text format.
+func (e *exporter) metricsHandler(rw http.ResponseWriter, _ *http.Request) {
+	rw.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	fmt.Fprintln(rw, "# HELP souin_storage_entries Number of entries in the storage")
+	fmt.Fprintln(rw, "# TYPE souin_storage_entries gauge")
+	fmt.Fprintf(rw, "souin_storage_entries{storage=%q} %d\n", e.provider.Name(), len(e.provider.ListKeys()))
+
+	fmt.Fprintln(rw, "# HELP souin_storage_size_bytes Bytes used by the storage directory")
+	fmt.Fprintln(rw, "# TYPE souin_storage_size_bytes gauge")
+	fmt.Fprintf(rw, "souin_storage_size_bytes{storage=%q} %d\n", e.provider.Name(), e.directorySize())
+
+	fmt.Fprintln(rw, "# HELP souin_storage_op_latency_nanos Disk operation latency in nanoseconds")
+	fmt.Fprintln(rw, "# TYPE souin_storage_op_latency_nanos gauge")
+
+	for operation, snapshot := range e.provider.LatencyStats() {
+		fmt.Fprintf(rw, "souin_storage_op_latency_nanos{storage=%q,op=%q,stat=\"avg\"} %d\n", e.provider.Name(), operation, snapshot.AverageNanos)
+		fmt.Fprintf(rw, "souin_storage_op_latency_nanos{storage=%q,op=%q,stat=\"max\"} %d\n", e.provider.Name(), operation, snapshot.MaxNanos)
+	}
+
+	scrub := e.provider.ScrubberStats()
+
+	fmt.Fprintln(rw, "# HELP souin_storage_scrub_corrupted_total Corrupted entries found by the integrity check")
+	fmt.Fprintln(rw, "# TYPE souin_storage_scrub_corrupted_total counter")
+	fmt.Fprintf(rw, "souin_storage_scrub_corrupted_total{storage=%q} %d\n", e.provider.Name(), scrub.Corrupted)
+}
+
+func main() {
+	path := flag.String("path", ".", "simplefs storage directory")
+	listen := flag.String("listen", ":9405", "listen address")
+	stale := flag.Duration("stale", time.Hour, "stale window (should match the provider configuration)")
+	flag.Parse()
+
+	logger := zap.NewNop().Sugar()
+
+	storer, err := simplefs.Factory(core.CacheProvider{Path: *path}, logger, *stale)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+
+	provider, ok := storer.(*simplefs.Simplefs)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "error: unknown provider type")
+		os.Exit(1)
+	}
+
+	if err := provider.Init(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+
+	metricsExporter := &exporter{provider: provider, path: *path}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", metricsExporter.metricsHandler)
+
+	fmt.Printf("storage-exporter is serving /metrics on %s\n", *listen)
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if err := server.ListenAndServe(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,242 @@
+// storage-stress continuously hammers a storage provider (runs can last
+// hours) with a configurable concurrency, key cardinality, value size
+// distribution, purge storms and TTL jitter, while checking the invariants:
+// reads must match what was written, the size accounting stays within the
+// tolerance, no goroutine or file descriptor leaks, and reports the
+// throughput of each operation at the end.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/WJQSERVER/souin-storages/simplefs"
+	"github.com/darkweak/storages/core"
+	"go.uber.org/zap"
+)
+
+// counters aggregates the operation counts during the stress run.
+type counters struct {
+	sets      atomic.Int64
+	gets      atomic.Int64
+	hits      atomic.Int64
+	deletes   atomic.Int64
+	purges    atomic.Int64
+	corrupt   atomic.Int64
+	setErrors atomic.Int64
+}
+
+// stressKey returns the n-th stress key, the key space is driven by -keys.
+func stressKey(n int) string {
+	return fmt.Sprintf("GET-http-stress.local-/entry/%d", n)
+}
+
+// stressPayload generates a deterministic payload: the key bytes repeated in
+// a cycle. Any concurrent reader can verify the content against it without
+// knowing the length picked by the writer.
+func stressPayload(key string, size int) []byte {
+	payload := make([]byte, size)
+	for i := range payload {
+		payload[i] = key[i%len(key)]
+	}
+
+	return payload
+}
+
+// verifyPayload checks that the read content follows the stressPayload pattern.
+func verifyPayload(key string, content []byte) bool {
+	for i, b := range content {
+		if b != key[i%len(key)] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// openFDCount returns the number of file descriptors opened by the current
+// process, -1 when unavailable.
+func openFDCount() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+
+	return len(entries)
+}
+
+func main() {
+	path := flag.String("path", "", "simplefs storage directory (required)")
+	duration := flag.Duration("duration", time.Minute, "stress duration, e.g. 2h")
+	concurrency := flag.Int("concurrency", 8, "number of concurrent workers")
+	keys := flag.Int("keys", 1000, "key cardinality")
+	minSize := flag.Int("min-size", 128, "minimum value size in bytes")
+	maxSize := flag.Int("max-size", 64<<10, "maximum value size in bytes")
+	minTTL := flag.Duration("min-ttl", time.Second, "minimum entry TTL")
+	maxTTL := flag.Duration("max-ttl", time.Minute, "maximum entry TTL")
+	purgeEvery := flag.Duration("purge-every", 30*time.Second, "purge storm interval, 0 to disable")
+	tolerance := flag.Float64("size-tolerance", 0.05, "allowed relative deviation of the size accounting")
+	stale := flag.Duration("stale", time.Hour, "stale window (should match the provider configuration)")
+	flag.Parse()
+
+	if *path == "" {
+		fmt.Fprintln(os.Stderr, "error: -path must be given")
+		os.Exit(2)
+	}
+
+	logger := zap.NewNop().Sugar()
+
+	storer, err := simplefs.Factory(core.CacheProvider{Path: *path}, logger, *stale)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+
+	provider, ok := storer.(*simplefs.Simplefs)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "error: unknown provider type")
+		os.Exit(1)
+	}
+
+	if err := provider.Init(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+
+	baselineGoroutines := runtime.NumGoroutine()
+	baselineFDs := openFDCount()
+	stats := &counters{}
+	deadline := time.Now().Add(*duration)
+
+	var wg sync.WaitGroup
+
+	for worker := 0; worker < *concurrency; worker++ {
+		wg.Add(1)
+
+		go func(seed int64) {
+			defer wg.Done()
+
+			rng := rand.New(rand.NewSource(seed)) //nolint:gosec
+
+			for time.Now().Before(deadline) {
+				key := stressKey(rng.Intn(*keys))
+
+				switch rng.Intn(10) {
+				case 0: // Delete, to churn the key space outside the TTLs.
+					provider.Delete(key)
+					stats.deletes.Add(1)
+				case 1, 2, 3: // Write with a random size and TTL.
+					size := *minSize
+					if *maxSize > *minSize {
+						size += rng.Intn(*maxSize - *minSize)
+					}
+
+					ttl := *minTTL + time.Duration(rng.Int63n(int64(*maxTTL-*minTTL)+1))
+					if err := provider.Set(key, stressPayload(key, size), ttl); err != nil {
+						stats.setErrors.Add(1)
+					}
+
+					stats.sets.Add(1)
+				default: // Read and verify the content.
+					stats.gets.Add(1)
+
+					if content := provider.Get(key); content != nil {
+						stats.hits.Add(1)
+
+						if !verifyPayload(key, content) {
+							stats.corrupt.Add(1)
+							fmt.Fprintf(os.Stderr, "invariant violation: the key %s read corrupted content (%d bytes)\n", key, len(content))
+						}
+					}
+				}
+			}
+		}(int64(worker) + time.Now().UnixNano())
+	}
+
+	// Periodic purge storm: delete every entry under a prefix at once.
+	if *purgeEvery > 0 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			ticker := time.NewTicker(*purgeEvery)
+			defer ticker.Stop()
+
+			for time.Now().Before(deadline) {
+				<-ticker.C
+				provider.DeleteMany("GET-http-stress.local-/entry/1.*")
+				stats.purges.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// Let the background eviction and callbacks settle before checking the invariants.
+	time.Sleep(2 * time.Second)
+
+	failed := false
+
+	if corrupt := stats.corrupt.Load(); corrupt > 0 {
+		fmt.Fprintf(os.Stderr, "invariant violation: %d corrupted reads in total\n", corrupt)
+
+		failed = true
+	}
+
+	// The size accounting must match the on-disk usage within the tolerance.
+	snapshot := provider.Snapshot()
+	actual := int64(0)
+
+	if files, err := os.ReadDir(*path); err == nil {
+		for _, file := range files {
+			if info, err := file.Info(); err == nil && !file.IsDir() {
+				actual += info.Size()
+			}
+		}
+	}
+
+	if snapshot.ActualSize > 0 && actual > 0 {
+		deviation := float64(snapshot.ActualSize-actual) / float64(actual)
+		if deviation < 0 {
+			deviation = -deviation
+		}
+
+		if deviation > *tolerance {
+			fmt.Fprintf(os.Stderr, "invariant violation: the size accounting %d deviates %.1f%% from the disk usage %d, above the tolerance\n",
+				snapshot.ActualSize, deviation*100, actual)
+
+			failed = true
+		}
+	}
+
+	if leaked := runtime.NumGoroutine() - baselineGoroutines - 1; leaked > *concurrency {
+		fmt.Fprintf(os.Stderr, "invariant violation: goroutines grew from %d to %d\n", baselineGoroutines, runtime.NumGoroutine())
+
+		failed = true
+	}
+
+	if baselineFDs > 0 {
+		if fds := openFDCount(); fds > baselineFDs*2+8 {
+			fmt.Fprintf(os.Stderr, "invariant violation: file descriptors grew from %d to %d\n", baselineFDs, fds)
+
+			failed = true
+		}
+	}
+
+	seconds := duration.Seconds()
+	fmt.Printf("stress done: %s, concurrency %d, key cardinality %d\n", *duration, *concurrency, *keys)
+	fmt.Printf("  sets: %d (%.0f/s, %d failed)\n", stats.sets.Load(), float64(stats.sets.Load())/seconds, stats.setErrors.Load())
+	fmt.Printf("  gets: %d (%.0f/s, %d hits)\n", stats.gets.Load(), float64(stats.gets.Load())/seconds, stats.hits.Load())
+	fmt.Printf("  deletes: %d, purge storms: %d\n", stats.deletes.Load(), stats.purges.Load())
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,22 @@
+module github.com/WJQSERVER/souin-storages/cli
+
+go 1.24
+
+replace github.com/darkweak/storages/core => ../core
+
+replace github.com/WJQSERVER/souin-storages/simplefs => ../simplefs
+
+require (
+	github.com/WJQSERVER/souin-storages/simplefs v0.0.0
+	github.com/darkweak/storages/core v0.0.14
+	github.com/dustin/go-humanize v1.0.1
+	github.com/pierrec/lz4/v4 v4.1.22
+	go.uber.org/zap v1.27.0
+)
+
+require (
+	github.com/jellydator/ttlcache/v3 v3.3.0 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	golang.org/x/sync v0.13.0 // indirect
+	google.golang.org/protobuf v1.36.6 // indirect
+)
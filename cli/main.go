@@ -0,0 +1,45 @@
+// storages-cli provides operational subcommands for the storage providers.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// usage prints the available subcommands.
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: storages-cli <subcommand> [options]")
+	fmt.Fprintln(os.Stderr, "Subcommands:")
+	fmt.Fprintln(os.Stderr, "  analyze   analyze a cache directory: largest entries, compression ratio and total usage")
+	fmt.Fprintln(os.Stderr, "  warm      warm the cache from a URL list or an access log")
+	fmt.Fprintln(os.Stderr, "  migrate   copy the alive entries between two providers")
+	fmt.Fprintln(os.Stderr, "  check     run a read-write self-test on a provider and report the latency and free space")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+
+	switch os.Args[1] {
+	case "analyze":
+		err = runAnalyze(os.Args[2:])
+	case "warm":
+		err = runWarm(os.Args[2:])
+	case "migrate":
+		err = runMigrate(os.Args[2:])
+	case "check":
+		err = runCheck(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
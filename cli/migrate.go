@@ -0,0 +1,164 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/WJQSERVER/souin-storages/simplefs"
+	"github.com/darkweak/storages/core"
+	"go.uber.org/zap"
+)
+
+// runMigrate copies every alive entry (key, body, remaining TTL and mapping)
+// between two providers, with rate limiting and resume support, e.g. from an
+// old simplefs directory to a new one.
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	from := flags.String("from", "", "source provider DSN, e.g. simplefs:/old?compression=lz4")
+	to := flags.String("to", "", "destination provider DSN, e.g. simplefs:/new")
+	rate := flags.Int("rate", 100, "maximum number of entries migrated per second")
+	state := flags.String("state", "", "resume file recording the last completed base key")
+	stale := flags.Duration("stale", time.Hour, "stale window (should match the provider configuration)")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *from == "" || *to == "" {
+		return fmt.Errorf("both -from and -to must be given")
+	}
+
+	source, err := openProvider(*from, *stale)
+	if err != nil {
+		return err
+	}
+
+	destination, err := openProvider(*to, *stale)
+	if err != nil {
+		return err
+	}
+
+	// Restore the last completed position from the resume file.
+	resumeAfter := ""
+
+	if *state != "" {
+		if content, err := os.ReadFile(*state); err == nil {
+			resumeAfter = strings.TrimSpace(string(content))
+		}
+	}
+
+	// Migrate by sorted base keys so the resume order is stable.
+	baseKeys := make([]string, 0)
+
+	for key := range source.MapKeys(core.MappingKeyPrefix) {
+		baseKeys = append(baseKeys, key)
+	}
+
+	sort.Strings(baseKeys)
+
+	ticker := time.NewTicker(time.Second / time.Duration(max(*rate, 1)))
+	defer ticker.Stop()
+
+	var migrated, skipped int
+
+	now := time.Now()
+
+	for _, baseKey := range baseKeys {
+		if resumeAfter != "" && baseKey <= resumeAfter {
+			continue
+		}
+
+		mapping, err := core.DecodeMapping(source.Get(core.MappingKeyPrefix + baseKey))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: impossible to decode the mapping of %s: %v\n", baseKey, err)
+
+			continue
+		}
+
+		for variedKey, keyIndex := range mapping.GetMapping() {
+			// Only migrate the entries still inside the stale window.
+			if !keyIndex.GetStaleTime().AsTime().After(now) {
+				skipped++
+
+				continue
+			}
+
+			value := source.Get(variedKey)
+			if len(value) == 0 {
+				skipped++
+
+				continue
+			}
+
+			<-ticker.C
+
+			// The remaining TTL follows the freshness of the source entry.
+			remaining := time.Until(keyIndex.GetFreshTime().AsTime())
+			if remaining < 0 {
+				remaining = 0
+			}
+
+			variedHeaders := http.Header{}
+			for name, values := range keyIndex.GetVariedHeaders() {
+				variedHeaders[name] = values.GetHeaderValue()
+			}
+
+			if err := destination.SetMultiLevel(baseKey, variedKey, value, variedHeaders, keyIndex.GetEtag(), remaining, keyIndex.GetRealKey()); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: impossible to write %s: %v\n", variedKey, err)
+
+				continue
+			}
+
+			migrated++
+		}
+
+		if *state != "" {
+			if err := os.WriteFile(*state, []byte(baseKey+"\n"), 0o644); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Printf("migration done: %d entries copied, %d skipped\n", migrated, skipped)
+
+	return nil
+}
+
+// openProvider opens a provider from a DSN. Only simplefs is supported for
+// now; other providers can be registered here.
+func openProvider(dsn string, stale time.Duration) (core.Storer, error) {
+	scheme, rest, found := strings.Cut(dsn, ":")
+	if !found {
+		return nil, fmt.Errorf("invalid DSN %q, expected <provider>:<path>", dsn)
+	}
+
+	path, rawQuery, _ := strings.Cut(rest, "?")
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	switch scheme {
+	case "simplefs":
+		configuration := map[string]interface{}{}
+		if compression := query.Get("compression"); compression != "" {
+			configuration["compression"] = compression
+		}
+
+		storer, err := simplefs.Factory(core.CacheProvider{Path: path, Configuration: configuration}, zap.NewNop().Sugar(), stale)
+		if err != nil {
+			return nil, err
+		}
+
+		return storer, storer.Init()
+	default:
+		return nil, fmt.Errorf("unsupported provider %q", scheme)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WJQSERVER/souin-storages/simplefs"
+	"github.com/darkweak/storages/core"
+	"go.uber.org/zap"
+)
+
+// caddyLogLine only parses the fields we need from a Caddy JSON access log.
+type caddyLogLine struct {
+	Request struct {
+		URI string `json:"uri"`
+	} `json:"request"`
+}
+
+// runWarm reads a URL list or an access log, fetches each path from the
+// origin and writes it to the storage through SetMultiLevel, so a new node
+// comes up with hot data already in place.
+func runWarm(args []string) error {
+	flags := flag.NewFlagSet("warm", flag.ExitOnError)
+	path := flags.String("path", ".", "simplefs storage directory")
+	input := flags.String("input", "-", "URL list or access log file (- for the standard input)")
+	origin := flags.String("origin", "", "origin base address, e.g. http://127.0.0.1:8080")
+	concurrency := flags.Int("concurrency", 4, "number of concurrent fetches")
+	ttl := flags.Duration("ttl", time.Hour, "default TTL used without a Cache-Control header")
+	stale := flags.Duration("stale", time.Hour, "stale window (should match the provider configuration)")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *origin == "" {
+		return fmt.Errorf("-origin must be given")
+	}
+
+	storer, err := simplefs.Factory(core.CacheProvider{Path: *path}, zap.NewNop().Sugar(), *stale)
+	if err != nil {
+		return err
+	}
+
+	if err := storer.Init(); err != nil {
+		return err
+	}
+
+	reader := os.Stdin
+	if *input != "-" {
+		file, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+		reader = file
+	}
+
+	urls := make(chan string)
+
+	var wg sync.WaitGroup
+
+	var fetched, failed int
+
+	var mu sync.Mutex
+
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for target := range urls {
+				err := warmOne(storer, *origin, target, *ttl)
+
+				mu.Lock()
+				if err != nil {
+					failed++
+					fmt.Fprintf(os.Stderr, "warning: %s: %v\n", target, err)
+				} else {
+					fetched++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		if target := extractURI(scanner.Text()); target != "" {
+			urls <- target
+		}
+	}
+
+	close(urls)
+	wg.Wait()
+
+	fmt.Printf("warming done: %d succeeded, %d failed\n", fetched, failed)
+
+	return scanner.Err()
+}
+
+// extractURI extracts the request path from an input line; plain URLs, Caddy
+// JSON logs and the common access log format are supported.
+func extractURI(line string) string {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ""
+	}
+
+	// Caddy JSON log.
+	if strings.HasPrefix(line, "{") {
+		var parsed caddyLogLine
+		if err := json.Unmarshal([]byte(line), &parsed); err == nil && parsed.Request.URI != "" {
+			return parsed.Request.URI
+		}
+
+		return ""
+	}
+
+	// Common log format: ... "GET /path HTTP/1.1" ...
+	if start := strings.Index(line, `"`); start >= 0 {
+		if end := strings.Index(line[start+1:], `"`); end > 0 {
+			request := line[start+1 : start+1+end]
+
+			parts := strings.Fields(request)
+			if len(parts) >= 2 && parts[0] == http.MethodGet {
+				return parts[1]
+			}
+
+			return ""
+		}
+	}
+
+	return line
+}
+
+// warmOne fetches a single path and writes it to the storage.
+func warmOne(storer core.Storer, origin, target string, defaultTTL time.Duration) error {
+	full := target
+	if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+		full = strings.TrimSuffix(origin, "/") + "/" + strings.TrimPrefix(target, "/")
+	}
+
+	response, err := http.Get(full)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("the origin returned %d", response.StatusCode)
+	}
+
+	dumped, err := httputil.DumpResponse(response, true)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := url.Parse(full)
+	if err != nil {
+		return err
+	}
+
+	key := http.MethodGet + "-" + parsed.Scheme + "-" + parsed.Host + "-" + parsed.Path
+
+	return storer.SetMultiLevel(key, key, dumped, response.Header, response.Header.Get("Etag"), responseTTL(response, defaultTTL), key)
+}
+
+// responseTTL derives the TTL from the Cache-Control max-age directive,
+// falling back to the default when missing.
+func responseTTL(response *http.Response, defaultTTL time.Duration) time.Duration {
+	for _, directive := range strings.Split(response.Header.Get("Cache-Control"), ",") {
+		directive = strings.TrimSpace(directive)
+		if value, found := strings.CutPrefix(directive, "max-age="); found {
+			if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+
+	return defaultTTL
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// InitialAge computes the corrected initial age of a response at storage
+// time, following RFC 9111 section 4.2.3: the maximum of the apparent age
+// (receipt time minus the Date header) and the upstream Age header value.
+// It returns 0 when neither header yields a usable value.
+func InitialAge(headers http.Header, receivedAt time.Time) time.Duration {
+	var apparentAge time.Duration
+
+	if date, err := http.ParseTime(headers.Get("Date")); err == nil {
+		if delta := receivedAt.Sub(date); delta > 0 {
+			apparentAge = delta
+		}
+	}
+
+	var upstreamAge time.Duration
+
+	if seconds, err := strconv.ParseInt(headers.Get("Age"), 10, 64); err == nil && seconds > 0 {
+		upstreamAge = time.Duration(seconds) * time.Second
+	}
+
+	if upstreamAge > apparentAge {
+		return upstreamAge
+	}
+
+	return apparentAge
+}
+
+// CurrentAge returns the age a stored response has reached by now: its
+// initial age at storage time plus the time it has spent in the cache.
+// storedAt is the mapping StoredAt timestamp persisted with the entry.
+func CurrentAge(headers http.Header, storedAt time.Time) time.Duration {
+	age := InitialAge(headers, storedAt) + Since(storedAt)
+	if age < 0 {
+		return 0
+	}
+
+	return age
+}
+
+// SetAgeHeader writes the current Age of a stored response into its headers,
+// so every provider reports Age consistently instead of each middleware
+// recomputing it per backend.
+func SetAgeHeader(response *http.Response, storedAt time.Time) {
+	if response == nil {
+		return
+	}
+
+	age := CurrentAge(response.Header, storedAt)
+	response.Header.Set("Age", strconv.FormatInt(int64(age/time.Second), 10))
+}
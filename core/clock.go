@@ -0,0 +1,48 @@
+package core
+
+import "time"
+
+// Clock abstracts the time source used for TTL handling, stale windows,
+// early-refresh jitter and mapping timestamps, so tests and simulations can
+// fast-forward time deterministically instead of sleeping.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock is the default Clock backed by time.Now.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// clock is the active time source, defaulting to the real clock.
+var clock Clock = realClock{}
+
+// SetClock replaces the active time source. Passing nil restores the real
+// clock. It is intended for tests and simulations and must not be called
+// concurrently with cache operations.
+func SetClock(c Clock) {
+	if c == nil {
+		clock = realClock{}
+
+		return
+	}
+
+	clock = c
+}
+
+// Now returns the current time from the active clock.
+func Now() time.Time {
+	return clock.Now()
+}
+
+// Since returns the time elapsed since t according to the active clock.
+func Since(t time.Time) time.Duration {
+	return clock.Now().Sub(t)
+}
+
+// Until returns the duration until t according to the active clock.
+func Until(t time.Time) time.Duration {
+	return t.Sub(clock.Now())
+}
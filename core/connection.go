@@ -0,0 +1,62 @@
+package core
+
+import "time"
+
+// ConnectionTuning standardizes the pool sizing and timeout fields accepted
+// by the network-backed providers under a common "connection" block, instead
+// of each backend exposing its client's own spelling of the same knobs.
+type ConnectionTuning struct {
+	PoolSize            int           `json:"pool_size" yaml:"pool_size"`
+	MinIdleConns        int           `json:"min_idle_conns" yaml:"min_idle_conns"`
+	DialTimeout         time.Duration `json:"dial_timeout" yaml:"dial_timeout"`
+	ReadTimeout         time.Duration `json:"read_timeout" yaml:"read_timeout"`
+	WriteTimeout        time.Duration `json:"write_timeout" yaml:"write_timeout"`
+	HealthCheckInterval time.Duration `json:"health_check_interval" yaml:"health_check_interval"`
+}
+
+// ParseConnectionTuning reads the "connection" block from a provider
+// configuration map. Absent fields stay zero so providers keep their client
+// defaults; the second return value tells whether a block was present.
+func ParseConnectionTuning(configuration interface{}) (ConnectionTuning, bool) {
+	tuning := ConnectionTuning{}
+
+	configMap, ok := configuration.(map[string]interface{})
+	if !ok || configMap == nil {
+		return tuning, false
+	}
+
+	blockMap, ok := configMap["connection"].(map[string]interface{})
+	if !ok {
+		return tuning, false
+	}
+
+	readInt := func(key string) int {
+		switch v := blockMap[key].(type) {
+		case int:
+			return v
+		case float64:
+			return int(v)
+		default:
+			return 0
+		}
+	}
+
+	readDuration := func(key string) time.Duration {
+		if s, ok := blockMap[key].(string); ok {
+			if d, err := time.ParseDuration(s); err == nil {
+				return d
+			}
+		}
+
+		return 0
+	}
+
+	tuning.PoolSize = readInt("pool_size")
+	tuning.MinIdleConns = readInt("min_idle_conns")
+	tuning.DialTimeout = readDuration("dial_timeout")
+	tuning.ReadTimeout = readDuration("read_timeout")
+	tuning.WriteTimeout = readDuration("write_timeout")
+	tuning.HealthCheckInterval = readDuration("health_check_interval")
+
+	return tuning, true
+}
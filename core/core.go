@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pierrec/lz4/v4"
@@ -53,6 +54,78 @@ func DecodeMapping(item []byte) (*StorageMapper, error) {
 	return mapping, e
 }
 
+// matchesVariedHeaders checks the request headers against the varied headers
+// stored alongside the given key, unless the vary check is bypassed by context.
+func matchesVariedHeaders(req *http.Request, keyItem *KeyIndex) bool {
+	if req.Context().Value(DISABLE_VARY_CTX) == nil || !req.Context().Value(DISABLE_VARY_CTX).(bool) {
+		for hname, hval := range keyItem.GetVariedHeaders() {
+			if req.Header.Get(hname) != strings.Join(hval.GetHeaderValue(), ", ") {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// BatchGetter is an optional interface a Storer can implement to read several
+// keys in one round trip (e.g. a pipelined MGET). The mapping election uses it
+// instead of one Get per candidate when available, which matters for network
+// backends where every Get is a full round trip.
+type BatchGetter interface {
+	GetMany(keys []string) map[string][]byte
+}
+
+// prefetchCandidates reads the bodies of every fresh/stale candidate key
+// while the election itself runs, to avoid paying one sequential disk/network
+// round trip per variant on Vary-heavy responses. Providers implementing
+// BatchGetter get a single batched read; the others fall back to concurrent
+// individual Gets.
+func prefetchCandidates(provider Storer, mapping *StorageMapper, req *http.Request) map[string][]byte {
+	candidates := []string{}
+
+	for keyName, keyItem := range mapping.GetMapping() {
+		if !matchesVariedHeaders(req, keyItem) {
+			continue
+		}
+
+		// Only fetch keys still within their fresh or stale window.
+		if Since(keyItem.GetStaleTime().AsTime()) >= 0 {
+			continue
+		}
+
+		candidates = append(candidates, keyName)
+	}
+
+	if batcher, ok := provider.(BatchGetter); ok && len(candidates) > 1 {
+		return batcher.GetMany(candidates)
+	}
+
+	prefetched := map[string][]byte{}
+
+	var wg sync.WaitGroup
+
+	var mu sync.Mutex
+
+	for _, keyName := range candidates {
+		wg.Add(1)
+
+		go func(name string) {
+			defer wg.Done()
+
+			response := provider.Get(name)
+
+			mu.Lock()
+			prefetched[name] = response
+			mu.Unlock()
+		}(keyName)
+	}
+
+	wg.Wait()
+
+	return prefetched
+}
+
 func MappingElection(provider Storer, item []byte, req *http.Request, validator *Revalidator, logger Logger) (resultFresh *http.Response, resultStale *http.Response, e error) {
 	mapping := &StorageMapper{}
 
@@ -63,29 +136,22 @@ func MappingElection(provider Storer, item []byte, req *http.Request, validator
 		}
 	}
 
-	for keyName, keyItem := range mapping.GetMapping() {
-		valid := true
+	prefetched := prefetchCandidates(provider, mapping, req)
 
-		if req.Context().Value(DISABLE_VARY_CTX) == nil || !req.Context().Value(DISABLE_VARY_CTX).(bool) {
-			for hname, hval := range keyItem.GetVariedHeaders() {
-				if req.Header.Get(hname) != strings.Join(hval.GetHeaderValue(), ", ") {
-					valid = false
+	RecordDecision(req, "Backend", provider.Name())
 
-					break
-				}
-			}
-		}
-
-		if !valid {
+	for keyName, keyItem := range mapping.GetMapping() {
+		if !matchesVariedHeaders(req, keyItem) {
 			continue
 		}
 
 		ValidateETagFromHeader(keyItem.GetEtag(), validator)
+		ValidateLastModifiedFromHeader(keyItem.GetLastModified(), validator)
 
 		if validator.Matched {
 			// If the key is fresh enough.
-			if time.Since(keyItem.GetFreshTime().AsTime()) < 0 {
-				response := provider.Get(keyName)
+			if Since(keyItem.GetFreshTime().AsTime()) < 0 {
+				response := prefetched[keyName]
 				if response != nil {
 					bufW := new(bytes.Buffer)
 					reader := lz4.NewReader(bytes.NewBuffer(response))
@@ -99,13 +165,26 @@ func MappingElection(provider Storer, item []byte, req *http.Request, validator
 
 					logger.Debugf("The stored key %s matched the current iteration key ETag %+v", keyName, validator)
 
+					SetAgeHeader(resultFresh, keyItem.GetStoredAt().AsTime())
+
+					if shouldRefreshEarly(keyItem.GetStoredAt().AsTime(), keyItem.GetFreshTime().AsTime()) {
+						validator.EarlyRefresh = true
+
+						RecordDecision(req, "Early-Refresh", "true")
+					}
+
+					RecordDecision(req, "Hit", "fresh")
+					RecordDecision(req, "Key", keyItem.GetRealKey())
+					RecordDecision(req, "Stored-At", keyItem.GetStoredAt().AsTime().Format(time.RFC3339))
+					RecordDecision(req, "Remaining-TTL", Until(keyItem.GetFreshTime().AsTime()).Truncate(time.Second).String())
+
 					return resultFresh, resultStale, e
 				}
 			}
 
 			// If the key is still stale.
-			if time.Since(keyItem.GetStaleTime().AsTime()) < 0 {
-				response := provider.Get(keyName)
+			if Since(keyItem.GetStaleTime().AsTime()) < 0 {
+				response := prefetched[keyName]
 				if response != nil {
 					bufW := new(bytes.Buffer)
 					reader := lz4.NewReader(bytes.NewBuffer(response))
@@ -118,6 +197,13 @@ func MappingElection(provider Storer, item []byte, req *http.Request, validator
 					}
 
 					logger.Debugf("The stored key %s matched the current iteration key ETag %+v as stale", keyName, validator)
+
+					SetAgeHeader(resultStale, keyItem.GetStoredAt().AsTime())
+
+					RecordDecision(req, "Hit", "stale")
+					RecordDecision(req, "Key", keyItem.GetRealKey())
+					RecordDecision(req, "Stored-At", keyItem.GetStoredAt().AsTime().Format(time.RFC3339))
+					RecordDecision(req, "Remaining-TTL", Until(keyItem.GetStaleTime().AsTime()).Truncate(time.Second).String())
 				}
 			}
 		} else {
@@ -129,6 +215,16 @@ func MappingElection(provider Storer, item []byte, req *http.Request, validator
 }
 
 func MappingUpdater(key string, item []byte, logger Logger, now, freshTime, staleTime time.Time, variedHeaders http.Header, etag, realKey string) (val []byte, e error) {
+	// No Last-Modified validator available here: variedHeaders only carries
+	// the Vary'd request headers. Callers holding the stored response bytes
+	// should use MappingUpdaterWithLastModified with ResponseLastModified.
+	return MappingUpdaterWithLastModified(key, item, logger, now, freshTime, staleTime, variedHeaders, etag, "", realKey)
+}
+
+// MappingUpdaterWithLastModified stores the given Last-Modified validator
+// alongside the etag in the mapping metadata, enabling If-Modified-Since
+// driven elections.
+func MappingUpdaterWithLastModified(key string, item []byte, logger Logger, now, freshTime, staleTime time.Time, variedHeaders http.Header, etag, lastModified, realKey string) (val []byte, e error) {
 	mapping := &StorageMapper{}
 	if len(item) != 0 {
 		e = proto.Unmarshal(item, mapping)
@@ -159,6 +255,7 @@ func MappingUpdater(key string, item []byte, logger Logger, now, freshTime, stal
 		VariedHeaders: pbvariedeheader,
 		Etag:          etag,
 		RealKey:       realKey,
+		LastModified:  lastModified,
 	}
 
 	val, e = proto.Marshal(mapping)
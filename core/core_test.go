@@ -1 +1,234 @@
 package core_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/WJQSERVER/souin-storages/core"
+)
+
+// nopLogger is a no-op core.Logger for tests.
+type nopLogger struct{}
+
+func (nopLogger) Debug(_ ...interface{})             {}
+func (nopLogger) Info(_ ...interface{})              {}
+func (nopLogger) Warn(_ ...interface{})              {}
+func (nopLogger) Error(_ ...interface{})             {}
+func (nopLogger) DPanic(_ ...interface{})            {}
+func (nopLogger) Panic(_ ...interface{})             {}
+func (nopLogger) Fatal(_ ...interface{})             {}
+func (nopLogger) Debugf(_ string, _ ...interface{})  {}
+func (nopLogger) Infof(_ string, _ ...interface{})   {}
+func (nopLogger) Warnf(_ string, _ ...interface{})   {}
+func (nopLogger) Errorf(_ string, _ ...interface{})  {}
+func (nopLogger) DPanicf(_ string, _ ...interface{}) {}
+func (nopLogger) Panicf(_ string, _ ...interface{})  {}
+func (nopLogger) Fatalf(_ string, _ ...interface{})  {}
+
+func TestMemoryStorer_GetSetDelete(t *testing.T) {
+	provider := core.NewMemoryStorer(1<<20, time.Minute, nopLogger{})
+
+	if err := provider.Set("key", []byte("value"), time.Minute); err != nil {
+		t.Errorf("Set shouldn't fail: %v", err)
+	}
+
+	if res := provider.Get("key"); string(res) != "value" {
+		t.Errorf("%s not corresponding to value", string(res))
+	}
+
+	provider.Delete("key")
+
+	if res := provider.Get("key"); len(res) != 0 {
+		t.Error("Key key should not exist after Delete")
+	}
+}
+
+func TestMemoryStorer_EvictsLeastRecentlyUsed(t *testing.T) {
+	// Capacity fits two of the three 4-byte values.
+	provider := core.NewMemoryStorer(8, time.Minute, nopLogger{})
+
+	_ = provider.Set("first", []byte("aaaa"), time.Minute)
+	_ = provider.Set("second", []byte("bbbb"), time.Minute)
+
+	// Touch first so second becomes the least recently used entry.
+	_ = provider.Get("first")
+
+	_ = provider.Set("third", []byte("cccc"), time.Minute)
+
+	if res := provider.Get("second"); len(res) != 0 {
+		t.Error("Key second should have been evicted as least recently used")
+	}
+
+	if res := provider.Get("first"); len(res) == 0 {
+		t.Error("Key first should have survived the eviction")
+	}
+
+	if res := provider.Get("third"); len(res) == 0 {
+		t.Error("Key third should have survived the eviction")
+	}
+}
+
+func TestMemoryStorer_ExpiredKeyIsNotServed(t *testing.T) {
+	provider := core.NewMemoryStorer(1<<20, time.Minute, nopLogger{})
+
+	_ = provider.Set("ExpiredKey", []byte("value"), time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+
+	if res := provider.Get("ExpiredKey"); len(res) != 0 {
+		t.Error("Key ExpiredKey should have expired")
+	}
+}
+
+func TestMemoryStorer_DeleteMany(t *testing.T) {
+	provider := core.NewMemoryStorer(1<<20, time.Minute, nopLogger{})
+
+	_ = provider.Set("GET-http-domain.com-/", []byte("value"), time.Minute)
+	_ = provider.Set("GET-http-domain.com-/about", []byte("value"), time.Minute)
+	_ = provider.Set("GET-http-other.com-/", []byte("value"), time.Minute)
+
+	provider.DeleteMany(".*domain.com.*")
+
+	if res := provider.Get("GET-http-domain.com-/"); len(res) != 0 {
+		t.Error("Keys matching the pattern should have been deleted")
+	}
+
+	if res := provider.Get("GET-http-other.com-/"); len(res) == 0 {
+		t.Error("Keys not matching the pattern should have survived")
+	}
+}
+
+func TestTieredStorer_SetWritesEveryTier(t *testing.T) {
+	front := core.NewMemoryStorer(1<<20, time.Minute, nopLogger{})
+	back := core.NewMemoryStorer(1<<20, time.Minute, nopLogger{})
+	provider := core.NewTieredStorer(false, time.Minute, front, back)
+
+	if err := provider.Set("key", []byte("value"), time.Minute); err != nil {
+		t.Errorf("Set shouldn't fail: %v", err)
+	}
+
+	if res := front.Get("key"); string(res) != "value" {
+		t.Error("The front tier should hold the key")
+	}
+
+	if res := back.Get("key"); string(res) != "value" {
+		t.Error("The back tier should hold the key")
+	}
+}
+
+func TestTieredStorer_ReadRepairBackfillsUpperTiers(t *testing.T) {
+	front := core.NewMemoryStorer(1<<20, time.Minute, nopLogger{})
+	back := core.NewMemoryStorer(1<<20, time.Minute, nopLogger{})
+	provider := core.NewTieredStorer(true, time.Minute, front, back)
+
+	// Only the lower tier holds the key, as after a front eviction.
+	_ = back.Set("key", []byte("value"), time.Minute)
+
+	if res := provider.Get("key"); string(res) != "value" {
+		t.Errorf("%s not corresponding to value", string(res))
+	}
+
+	if provider.Repairs() != 1 {
+		t.Errorf("One repair should have been triggered, got %d", provider.Repairs())
+	}
+
+	// The backfill runs asynchronously, poll until it lands.
+	deadline := time.Now().Add(time.Second)
+	for len(front.Get("key")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("The front tier should have been backfilled by read-repair")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// buildMapping encodes a mapping referencing the given varied keys, all stale
+// until staleTime.
+func buildMapping(t *testing.T, staleTime time.Time, variedKeys ...string) []byte {
+	t.Helper()
+
+	var value []byte
+
+	now := core.Now()
+
+	for _, variedKey := range variedKeys {
+		var err error
+
+		value, err = core.MappingUpdaterWithLastModified(variedKey, value, nopLogger{}, now, staleTime, staleTime, http.Header{}, "", "", variedKey)
+		if err != nil {
+			t.Fatalf("Impossible to build the mapping: %v", err)
+		}
+	}
+
+	return value
+}
+
+func TestPruneMapping_RemovesDeletedVariants(t *testing.T) {
+	value := buildMapping(t, core.Now().Add(time.Hour), "variant1", "variant2")
+
+	pruned, remaining, keepFor, changed := core.PruneMapping(value, func(key string) bool {
+		return key == "variant1"
+	})
+
+	if !changed {
+		t.Error("The mapping should have been changed")
+	}
+
+	if remaining != 1 {
+		t.Errorf("One variant should remain, got %d", remaining)
+	}
+
+	if keepFor <= 0 {
+		t.Errorf("The mapping should still be worth keeping, got %s", keepFor)
+	}
+
+	mapping, err := core.DecodeMapping(pruned)
+	if err != nil {
+		t.Errorf("The pruned mapping should decode: %v", err)
+	}
+
+	if _, found := mapping.GetMapping()["variant1"]; found {
+		t.Error("The deleted variant should have been removed from the mapping")
+	}
+
+	if _, found := mapping.GetMapping()["variant2"]; !found {
+		t.Error("The surviving variant should still be in the mapping")
+	}
+}
+
+func TestPruneMapping_ReportsEmptyMapping(t *testing.T) {
+	value := buildMapping(t, core.Now().Add(time.Hour), "variant1")
+
+	_, remaining, _, changed := core.PruneMapping(value, func(string) bool {
+		return true
+	})
+
+	if !changed {
+		t.Error("The mapping should have been changed")
+	}
+
+	if remaining != 0 {
+		t.Errorf("No variant should remain, got %d", remaining)
+	}
+}
+
+func TestPruneMapping_UntouchedMappingIsNotRewritten(t *testing.T) {
+	value := buildMapping(t, core.Now().Add(time.Hour), "variant1")
+
+	pruned, remaining, _, changed := core.PruneMapping(value, func(string) bool {
+		return false
+	})
+
+	if changed {
+		t.Error("The mapping shouldn't have been changed")
+	}
+
+	if remaining != 1 {
+		t.Errorf("One variant should remain, got %d", remaining)
+	}
+
+	if string(pruned) != string(value) {
+		t.Error("An untouched mapping should be returned as-is")
+	}
+}
@@ -79,7 +79,7 @@ func MappingElection(provider Storer, item []byte, req *http.Request, validator
 
 		if validator.Matched {
 			// If the key is fresh enough.
-			if time.Since(keyItem.GetFreshTime().AsTime()) < 0 {
+			if Since(keyItem.GetFreshTime().AsTime()) < 0 {
 				response := provider.Get(keyName)
 				if response != nil {
 					bufW := new(bytes.Buffer)
@@ -99,7 +99,7 @@ func MappingElection(provider Storer, item []byte, req *http.Request, validator
 			}
 
 			// If the key is still stale.
-			if time.Since(keyItem.GetStaleTime().AsTime()) < 0 {
+			if Since(keyItem.GetStaleTime().AsTime()) < 0 {
 				response := provider.Get(keyName)
 				if response != nil {
 					bufW := new(bytes.Buffer)
@@ -124,6 +124,16 @@ func MappingElection(provider Storer, item []byte, req *http.Request, validator
 }
 
 func MappingUpdater(key string, item []byte, logger Logger, now, freshTime, staleTime time.Time, variedHeaders http.Header, etag, realKey string) (val []byte, e error) {
+	// No Last-Modified validator available here: variedHeaders only carries
+	// the Vary'd request headers. Callers holding the stored response bytes
+	// should use MappingUpdaterWithLastModified with ResponseLastModified.
+	return MappingUpdaterWithLastModified(key, item, logger, now, freshTime, staleTime, variedHeaders, etag, "", realKey)
+}
+
+// MappingUpdaterWithLastModified stores the given Last-Modified validator
+// alongside the etag in the mapping metadata, enabling If-Modified-Since
+// driven elections.
+func MappingUpdaterWithLastModified(key string, item []byte, logger Logger, now, freshTime, staleTime time.Time, variedHeaders http.Header, etag, lastModified, realKey string) (val []byte, e error) {
 	mapping := &StorageMapper{}
 	if len(item) != 0 {
 		e = proto.Unmarshal(item, mapping)
@@ -153,6 +163,7 @@ func MappingUpdater(key string, item []byte, logger Logger, now, freshTime, stal
 		StaleTime:     timestamppb.New(staleTime),
 		VariedHeaders: pbvariedeheader,
 		Etag:          etag,
+		LastModified:  lastModified,
 		RealKey:       realKey,
 	}
 
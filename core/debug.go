@@ -0,0 +1,57 @@
+package core
+
+import (
+	"net/http"
+	"sync"
+)
+
+// DebugDecisionCtx is the context key the middleware uses to opt a request
+// into storage decision tracing.
+const DebugDecisionCtx = "storages_debug_decision"
+
+// StorageDecision accumulates the storage decisions made while serving a
+// request. The middleware stores one in the request context and surfaces the
+// recorded values as X-Souin-Storage-* response headers for troubleshooting.
+type StorageDecision struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// NewStorageDecision returns an empty decision collector.
+func NewStorageDecision() *StorageDecision {
+	return &StorageDecision{values: map[string]string{}}
+}
+
+// Record stores one decision value under the given header suffix.
+func (decision *StorageDecision) Record(name, value string) {
+	decision.mu.Lock()
+	defer decision.mu.Unlock()
+
+	decision.values[name] = value
+}
+
+// Headers returns the recorded values as X-Souin-Storage-* headers.
+func (decision *StorageDecision) Headers() http.Header {
+	decision.mu.Lock()
+	defer decision.mu.Unlock()
+
+	headers := http.Header{}
+	for name, value := range decision.values {
+		headers.Set("X-Souin-Storage-"+name, value)
+	}
+
+	return headers
+}
+
+// RecordDecision records a value when the request carries a decision
+// collector, and is a no-op otherwise, so providers can call it
+// unconditionally on the hot path.
+func RecordDecision(req *http.Request, name, value string) {
+	if req == nil {
+		return
+	}
+
+	if decision, ok := req.Context().Value(DebugDecisionCtx).(*StorageDecision); ok {
+		decision.Record(name, value)
+	}
+}
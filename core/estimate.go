@@ -0,0 +1,86 @@
+package core
+
+import (
+	"math"
+	"math/rand"
+)
+
+// SampleEstimate is a sampling-based estimate of a keyspace, for backends
+// where exact counts require a full scan (object stores, wide-column
+// databases). Margins are the half-width of the 95% confidence interval.
+type SampleEstimate struct {
+	Count       int64 `json:"count"`
+	Bytes       int64 `json:"bytes"`
+	CountMargin int64 `json:"count_margin"`
+	BytesMargin int64 `json:"bytes_margin"`
+	Partitions  int   `json:"partitions"`
+	Sampled     int   `json:"sampled"`
+}
+
+// EstimateKeyspace extrapolates item count and total bytes from a random
+// subset of partitions. The keyspace is assumed to be split into total
+// partitions (hash prefixes, token ranges, shard directories); probe returns
+// the exact count and byte total for one partition. Sampling sample of them
+// and scaling by total/sample gives an unbiased estimate whose confidence
+// interval narrows as the sample grows, without ever scanning everything.
+func EstimateKeyspace(total, sample int, probe func(partition int) (count, bytes int64)) SampleEstimate {
+	if total <= 0 || probe == nil {
+		return SampleEstimate{}
+	}
+
+	if sample <= 0 || sample > total {
+		sample = total
+	}
+
+	counts := make([]float64, 0, sample)
+	bytes := make([]float64, 0, sample)
+
+	for _, partition := range rand.Perm(total)[:sample] {
+		count, size := probe(partition)
+		counts = append(counts, float64(count))
+		bytes = append(bytes, float64(size))
+	}
+
+	countMean, countMargin := meanAndMargin(counts)
+	bytesMean, bytesMargin := meanAndMargin(bytes)
+
+	scale := float64(total)
+
+	return SampleEstimate{
+		Count:       int64(countMean * scale),
+		Bytes:       int64(bytesMean * scale),
+		CountMargin: int64(countMargin * scale),
+		BytesMargin: int64(bytesMargin * scale),
+		Partitions:  total,
+		Sampled:     sample,
+	}
+}
+
+// meanAndMargin returns the sample mean and the half-width of its 95%
+// confidence interval (1.96 standard errors). A sample of one has an
+// unknowable variance, reported as a zero margin rather than a guess.
+func meanAndMargin(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+
+	mean := sum / float64(len(values))
+
+	if len(values) < 2 {
+		return mean, 0
+	}
+
+	var squared float64
+	for _, value := range values {
+		squared += (value - mean) * (value - mean)
+	}
+
+	deviation := math.Sqrt(squared / float64(len(values)-1))
+
+	return mean, 1.96 * deviation / math.Sqrt(float64(len(values)))
+}
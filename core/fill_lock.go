@@ -0,0 +1,105 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// FillLockPrefix prefixes the coordination keys a distributed fill lock
+// writes into the shared backend.
+const FillLockPrefix = "LOCK_"
+
+// FillLock coordinates origin fills in-process: a single request becomes the
+// filler for a missing key while the other requests wait for it (or time out
+// and get served stale by the caller).
+type FillLock struct {
+	mu       sync.Mutex
+	inflight map[string]chan struct{}
+}
+
+// NewFillLock returns an empty fill coordinator.
+func NewFillLock() *FillLock {
+	return &FillLock{inflight: map[string]chan struct{}{}}
+}
+
+// Acquire returns true when the caller becomes the filler for the key. When
+// it returns false, the current filler finished or the timeout elapsed; the
+// caller should re-check storage before reaching to the origin.
+func (lock *FillLock) Acquire(key string, timeout time.Duration) bool {
+	lock.mu.Lock()
+
+	done, inflight := lock.inflight[key]
+	if !inflight {
+		lock.inflight[key] = make(chan struct{})
+		lock.mu.Unlock()
+
+		return true
+	}
+
+	lock.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+
+	return false
+}
+
+// Release marks the fill finished and wakes every waiter.
+func (lock *FillLock) Release(key string) {
+	lock.mu.Lock()
+	defer lock.mu.Unlock()
+
+	if done, ok := lock.inflight[key]; ok {
+		close(done)
+		delete(lock.inflight, key)
+	}
+}
+
+// DistributedFillLock extends the in-process coordination with a short-lived
+// lock key in a shared backend, so roughly one node per fleet fills a key.
+// Without a compare-and-set primitive in the Storer interface this is
+// best-effort: two nodes can still race on the lock key, which degrades to
+// two origin fetches, never to a wrong response.
+type DistributedFillLock struct {
+	local    *FillLock
+	provider Storer
+	ttl      time.Duration
+}
+
+// NewDistributedFillLock coordinates fills through the given shared backend.
+// The ttl bounds how long a crashed filler can block the other nodes.
+func NewDistributedFillLock(provider Storer, ttl time.Duration) *DistributedFillLock {
+	if ttl <= 0 {
+		ttl = 10 * time.Second
+	}
+
+	return &DistributedFillLock{local: NewFillLock(), provider: provider, ttl: ttl}
+}
+
+// Acquire returns true when the caller becomes the filler for the key on this
+// node and no other node currently holds the lock key.
+func (lock *DistributedFillLock) Acquire(key string, timeout time.Duration) bool {
+	if !lock.local.Acquire(key, timeout) {
+		return false
+	}
+
+	if len(lock.provider.Get(FillLockPrefix+key)) != 0 {
+		// Another node is filling; release the local claim so waiters on
+		// this node wake up and re-check storage.
+		lock.local.Release(key)
+
+		return false
+	}
+
+	_ = lock.provider.Set(FillLockPrefix+key, []byte("1"), lock.ttl)
+
+	return true
+}
+
+// Release drops the shared lock key and wakes the local waiters.
+func (lock *DistributedFillLock) Release(key string) {
+	lock.provider.Delete(FillLockPrefix + key)
+	lock.local.Release(key)
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// PruneMapping removes the variant references matched by deleted from an
+// encoded mapping. It returns the re-encoded mapping, the number of surviving
+// variants, how long the mapping is still worth keeping (based on the latest
+// stale deadline among the survivors) and whether anything was removed.
+// Callers should drop the mapping entirely when no variant survives.
+func PruneMapping(value []byte, deleted func(string) bool) (pruned []byte, remaining int, keepFor time.Duration, changed bool) {
+	mapping, err := DecodeMapping(value)
+	if err != nil {
+		return value, 0, 0, false
+	}
+
+	var latestStale time.Time
+
+	for variedKey, keyIndex := range mapping.GetMapping() {
+		if deleted(variedKey) {
+			delete(mapping.GetMapping(), variedKey)
+
+			changed = true
+
+			continue
+		}
+
+		if staleTime := keyIndex.GetStaleTime().AsTime(); staleTime.After(latestStale) {
+			latestStale = staleTime
+		}
+	}
+
+	remaining = len(mapping.GetMapping())
+	keepFor = Until(latestStale)
+
+	if !changed {
+		return value, remaining, keepFor, false
+	}
+
+	pruned, err = proto.Marshal(mapping)
+	if err != nil {
+		return value, remaining, keepFor, false
+	}
+
+	return pruned, remaining, keepFor, true
+}
+
+// MappingLister is an optional interface a Storer can implement to expose its
+// stored mapping entries keyed by the exact key they live under. CleanMappings
+// prefers it over MapKeys so the rewrite goes through the key it actually
+// scanned, which matters for providers decorating keys (e.g. a Redis hash tag
+// prefix) where a reconstructed MappingKeyPrefix+baseKey would miss the stored
+// entry.
+type MappingLister interface {
+	ListMappings() map[string][]byte
+}
+
+// CleanMappings walks the provider's mappings and removes the references
+// matched by deleted, dropping the mappings left without any variant.
+// Providers call it at the end of DeleteMany so pattern purges cannot leave
+// mappings pointing at removed variants.
+func CleanMappings(provider Storer, deleted func(string) bool, logger Logger) {
+	var mappings map[string][]byte
+
+	if lister, ok := provider.(MappingLister); ok {
+		mappings = lister.ListMappings()
+	} else {
+		mappings = map[string][]byte{}
+		for baseKey, value := range provider.MapKeys(MappingKeyPrefix) {
+			mappings[MappingKeyPrefix+baseKey] = []byte(value)
+		}
+	}
+
+	for mappingKey, value := range mappings {
+		pruned, remaining, keepFor, changed := PruneMapping(value, deleted)
+		if !changed {
+			continue
+		}
+
+		if remaining == 0 || keepFor <= 0 {
+			provider.Delete(mappingKey)
+			logger.Debugf("Removed the mapping %s left empty by DeleteMany", mappingKey)
+
+			continue
+		}
+
+		if err := provider.Set(mappingKey, pruned, keepFor); err != nil {
+			logger.Errorf("Impossible to rewrite the mapping %s after DeleteMany: %v", mappingKey, err)
+		}
+	}
+}
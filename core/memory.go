@@ -0,0 +1,261 @@
+package core
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	lz4 "github.com/pierrec/lz4/v4"
+)
+
+// memoryEntry is a single cached value with its expiry deadline.
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryStorer is a small in-memory LRU cache implementing Storer, meant to
+// sit as the fast front tier of a TieredStorer: hot keys get promoted into RAM
+// by read-repair and evicting one only demotes it back to the tier below,
+// which still holds the full copy. It is deliberately size-bounded and makes
+// no attempt at durability.
+type MemoryStorer struct {
+	mu sync.Mutex
+
+	capacity int64 // maximum total value bytes kept in RAM
+	size     int64
+	entries  map[string]*list.Element
+	lru      *list.List // front = most recently used
+	stale    time.Duration
+	logger   Logger
+}
+
+// NewMemoryStorer creates an in-memory LRU bounded to capacity value bytes.
+func NewMemoryStorer(capacity int64, stale time.Duration, logger Logger) *MemoryStorer {
+	return &MemoryStorer{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		lru:      list.New(),
+		stale:    stale,
+		logger:   logger,
+	}
+}
+
+// evictLocked drops least recently used entries until the cache fits again.
+// Callers must hold the mutex.
+func (provider *MemoryStorer) evictLocked() {
+	for provider.capacity > 0 && provider.size > provider.capacity {
+		oldest := provider.lru.Back()
+		if oldest == nil {
+			return
+		}
+
+		provider.removeLocked(oldest.Value.(*memoryEntry).key)
+	}
+}
+
+// removeLocked removes one entry. Callers must hold the mutex.
+func (provider *MemoryStorer) removeLocked(key string) {
+	element, found := provider.entries[key]
+	if !found {
+		return
+	}
+
+	provider.size -= int64(len(element.Value.(*memoryEntry).value))
+	provider.lru.Remove(element)
+	delete(provider.entries, key)
+}
+
+// getLocked returns a live entry, dropping it when expired. Callers must hold
+// the mutex.
+func (provider *MemoryStorer) getLocked(key string) *memoryEntry {
+	element, found := provider.entries[key]
+	if !found {
+		return nil
+	}
+
+	entry := element.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && Now().After(entry.expiresAt) {
+		provider.removeLocked(key)
+
+		return nil
+	}
+
+	provider.lru.MoveToFront(element)
+
+	return entry
+}
+
+// MapKeys method returns the map of existing keys.
+func (provider *MemoryStorer) MapKeys(prefix string) map[string]string {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	keys := map[string]string{}
+
+	for key, element := range provider.entries {
+		if strings.HasPrefix(key, prefix) {
+			k, _ := strings.CutPrefix(key, prefix)
+			keys[k] = string(element.Value.(*memoryEntry).value)
+		}
+	}
+
+	return keys
+}
+
+// ListKeys method returns the list of existing keys.
+func (provider *MemoryStorer) ListKeys() []string {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	keys := make([]string, 0, len(provider.entries))
+	for key := range provider.entries {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// Get method returns the populated response if exists, empty response then.
+// The returned slice is a copy so callers cannot mutate the cached value.
+func (provider *MemoryStorer) Get(key string) []byte {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if entry := provider.getLocked(key); entry != nil {
+		value := make([]byte, len(entry.value))
+		copy(value, entry.value)
+
+		return value
+	}
+
+	return nil
+}
+
+// Set method will store the response in the memory tier.
+func (provider *MemoryStorer) Set(key string, value []byte, duration time.Duration) error {
+	var expiresAt time.Time
+	if duration > 0 {
+		expiresAt = Now().Add(duration)
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	provider.removeLocked(key)
+
+	// Store a private copy so later mutations of the caller's slice cannot
+	// corrupt the cached value.
+	stored := make([]byte, len(value))
+	copy(stored, value)
+
+	element := provider.lru.PushFront(&memoryEntry{key: key, value: stored, expiresAt: expiresAt})
+	provider.entries[key] = element
+	provider.size += int64(len(stored))
+
+	provider.evictLocked()
+
+	return nil
+}
+
+// Delete method will delete the response in the memory tier if exists
+// corresponding to key param.
+func (provider *MemoryStorer) Delete(key string) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	provider.removeLocked(key)
+}
+
+// DeleteMany method will delete the responses in the memory tier if exists
+// corresponding to the regex key param.
+func (provider *MemoryStorer) DeleteMany(key string) {
+	rgKey, e := regexp.Compile(key)
+	if e != nil {
+		return
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	for storedKey := range provider.entries {
+		if rgKey.MatchString(storedKey) {
+			provider.removeLocked(storedKey)
+		}
+	}
+}
+
+// Init method will.
+func (provider *MemoryStorer) Init() error {
+	return nil
+}
+
+// Name returns the storer name.
+func (provider *MemoryStorer) Name() string {
+	return "MEMORY"
+}
+
+// Uuid returns an unique identifier.
+func (provider *MemoryStorer) Uuid() string {
+	return fmt.Sprintf("memory-%d-%s", provider.capacity, provider.stale)
+}
+
+// Reset method will reset or close provider.
+func (provider *MemoryStorer) Reset() error {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	provider.entries = map[string]*list.Element{}
+	provider.lru = list.New()
+	provider.size = 0
+
+	return nil
+}
+
+// GetMultiLevel tries to load the key and check if one of linked keys is a
+// fresh/stale candidate.
+func (provider *MemoryStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	value := provider.Get(MappingKeyPrefix + key)
+	if len(value) == 0 {
+		return fresh, stale
+	}
+
+	fresh, stale, _ = MappingElection(provider, value, req, validator, provider.logger)
+
+	return fresh, stale
+}
+
+// SetMultiLevel tries to store the key with the given value and update the
+// mapping key to store metadata.
+func (provider *MemoryStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	now := Now()
+
+	compressed := new(bytes.Buffer)
+	if _, err := lz4.NewWriter(compressed).ReadFrom(bytes.NewReader(value)); err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into the memory tier, %v", variedKey, err)
+
+		return err
+	}
+
+	if err := provider.Set(variedKey, compressed.Bytes(), duration+provider.stale); err != nil {
+		return err
+	}
+
+	mappingKey := MappingKeyPrefix + baseKey
+	result := provider.Get(mappingKey)
+
+	val, err := MappingUpdaterWithLastModified(variedKey, result, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, ResponseLastModified(value), realKey)
+	if err != nil {
+		return err
+	}
+
+	return provider.Set(mappingKey, val, duration+provider.stale)
+}
+
+var _ Storer = (*MemoryStorer)(nil)
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// PatternRoute binds a key regexp to a backend.
+type PatternRoute struct {
+	Pattern *regexp.Regexp
+	Backend Storer
+}
+
+// PatternRouterStorer dispatches operations to different backends based on
+// key patterns (e.g. API responses to redis, static assets to simplefs). The
+// first matching route wins; keys matching no route go to the fallback.
+// Every backend keeps its own mappings, so entries and their metadata always
+// live together.
+type PatternRouterStorer struct {
+	routes   []PatternRoute
+	fallback Storer
+	backends []Storer
+}
+
+// NewPatternRouterStorer builds a router from the ordered routes and the
+// fallback backend.
+func NewPatternRouterStorer(fallback Storer, routes ...PatternRoute) *PatternRouterStorer {
+	backends := []Storer{fallback}
+	for _, route := range routes {
+		backends = append(backends, route.Backend)
+	}
+
+	return &PatternRouterStorer{routes: routes, fallback: fallback, backends: backends}
+}
+
+// route picks the backend owning the given key.
+func (provider *PatternRouterStorer) route(key string) Storer {
+	// Mapping keys must land on the same backend as the keys they index.
+	key = strings.TrimPrefix(key, MappingKeyPrefix)
+
+	for _, route := range provider.routes {
+		if route.Pattern.MatchString(key) {
+			return route.Backend
+		}
+	}
+
+	return provider.fallback
+}
+
+// MapKeys returns the map of existing keys from every backend.
+func (provider *PatternRouterStorer) MapKeys(prefix string) map[string]string {
+	keys := map[string]string{}
+
+	for _, backend := range provider.backends {
+		for key, value := range backend.MapKeys(prefix) {
+			keys[key] = value
+		}
+	}
+
+	return keys
+}
+
+// ListKeys returns the list of existing keys from every backend.
+func (provider *PatternRouterStorer) ListKeys() []string {
+	keys := []string{}
+	for _, backend := range provider.backends {
+		keys = append(keys, backend.ListKeys()...)
+	}
+
+	return keys
+}
+
+// Get returns the value from the backend owning the key.
+func (provider *PatternRouterStorer) Get(key string) []byte {
+	return provider.route(key).Get(key)
+}
+
+// Set stores the value in the backend owning the key.
+func (provider *PatternRouterStorer) Set(key string, value []byte, duration time.Duration) error {
+	return provider.route(key).Set(key, value, duration)
+}
+
+// Delete removes the key from the backend owning it.
+func (provider *PatternRouterStorer) Delete(key string) {
+	provider.route(key).Delete(key)
+}
+
+// DeleteMany removes the matching keys from every backend, as a purge
+// pattern may span several routes.
+func (provider *PatternRouterStorer) DeleteMany(key string) {
+	for _, backend := range provider.backends {
+		backend.DeleteMany(key)
+	}
+}
+
+// Init initializes every backend.
+func (provider *PatternRouterStorer) Init() error {
+	for _, backend := range provider.backends {
+		if err := backend.Init(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Name returns the storer name.
+func (provider *PatternRouterStorer) Name() string {
+	return "PATTERN_ROUTER"
+}
+
+// Uuid returns an unique identifier combining every backend.
+func (provider *PatternRouterStorer) Uuid() string {
+	uuids := make([]string, len(provider.backends))
+	for i, backend := range provider.backends {
+		uuids[i] = backend.Uuid()
+	}
+
+	return fmt.Sprintf("pattern-router(%s)", strings.Join(uuids, ","))
+}
+
+// Reset resets every backend.
+func (provider *PatternRouterStorer) Reset() error {
+	for _, backend := range provider.backends {
+		if err := backend.Reset(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetMultiLevel runs the fresh/stale election on the backend owning the key.
+func (provider *PatternRouterStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	return provider.route(key).GetMultiLevel(key, req, validator)
+}
+
+// SetMultiLevel stores the response in the backend owning the base key, so
+// the mapping and its variants stay on the same backend.
+func (provider *PatternRouterStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	return provider.route(baseKey).SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey)
+}
+
+var _ Storer = (*PatternRouterStorer)(nil)
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PrivacyStorer hashes every key before it reaches the wrapped backend, so
+// mapping keys and real keys at rest no longer embed the full URL surface of
+// the origin. With an encryption key it additionally encrypts the payloads of
+// the plain Set/Get path with AES-GCM.
+//
+// Bodies stored through SetMultiLevel and the varied headers kept in mapping
+// metadata stay in cleartext: the wrapped backend reads both during the
+// fresh/stale election, so encrypting them requires backend support rather
+// than a wrapper. Hashed keys are one-way; pattern-based purges must match
+// against the original keys upstream of this wrapper.
+type PrivacyStorer struct {
+	inner Storer
+	gcm   cipher.AEAD
+}
+
+// NewPrivacyStorer wraps the backend with key hashing. A non-empty
+// encryptionKey (16, 24 or 32 bytes) enables AES-GCM payload encryption on
+// the plain key/value path.
+func NewPrivacyStorer(inner Storer, encryptionKey []byte) (*PrivacyStorer, error) {
+	provider := &PrivacyStorer{inner: inner}
+
+	if len(encryptionKey) != 0 {
+		block, err := aes.NewCipher(encryptionKey)
+		if err != nil {
+			return nil, err
+		}
+
+		provider.gcm, err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return provider, nil
+}
+
+// hashKey replaces the key with its hex-encoded SHA-256, keeping the mapping
+// prefix so the wrapped backend still recognizes mapping entries.
+func (provider *PrivacyStorer) hashKey(key string) string {
+	if trimmed, found := strings.CutPrefix(key, MappingKeyPrefix); found {
+		return MappingKeyPrefix + provider.hashKey(trimmed)
+	}
+
+	digest := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(digest[:])
+}
+
+// seal encrypts the value when an encryption key is configured.
+func (provider *PrivacyStorer) seal(value []byte) ([]byte, error) {
+	if provider.gcm == nil {
+		return value, nil
+	}
+
+	nonce := make([]byte, provider.gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return provider.gcm.Seal(nonce, nonce, value, nil), nil
+}
+
+// open decrypts the value when an encryption key is configured.
+func (provider *PrivacyStorer) open(value []byte) ([]byte, error) {
+	if provider.gcm == nil || len(value) == 0 {
+		return value, nil
+	}
+
+	if len(value) < provider.gcm.NonceSize() {
+		return nil, errors.New("stored value shorter than the nonce")
+	}
+
+	return provider.gcm.Open(nil, value[:provider.gcm.NonceSize()], value[provider.gcm.NonceSize():], nil)
+}
+
+// MapKeys returns the map of existing hashed keys.
+func (provider *PrivacyStorer) MapKeys(prefix string) map[string]string {
+	if prefix != "" && prefix != MappingKeyPrefix {
+		prefix = provider.hashKey(prefix)
+	}
+
+	return provider.inner.MapKeys(prefix)
+}
+
+// ListKeys returns the list of existing hashed keys.
+func (provider *PrivacyStorer) ListKeys() []string {
+	return provider.inner.ListKeys()
+}
+
+// Get returns the decrypted value stored under the hashed key.
+func (provider *PrivacyStorer) Get(key string) []byte {
+	value, err := provider.open(provider.inner.Get(provider.hashKey(key)))
+	if err != nil {
+		return nil
+	}
+
+	return value
+}
+
+// Set stores the encrypted value under the hashed key.
+func (provider *PrivacyStorer) Set(key string, value []byte, duration time.Duration) error {
+	sealed, err := provider.seal(value)
+	if err != nil {
+		return err
+	}
+
+	return provider.inner.Set(provider.hashKey(key), sealed, duration)
+}
+
+// Delete removes the hashed key.
+func (provider *PrivacyStorer) Delete(key string) {
+	provider.inner.Delete(provider.hashKey(key))
+}
+
+// DeleteMany forwards the pattern unchanged; it can only match the hashed
+// form of the keys.
+func (provider *PrivacyStorer) DeleteMany(key string) {
+	provider.inner.DeleteMany(key)
+}
+
+// Init initializes the wrapped backend.
+func (provider *PrivacyStorer) Init() error {
+	return provider.inner.Init()
+}
+
+// Name returns the storer name.
+func (provider *PrivacyStorer) Name() string {
+	return "PRIVACY"
+}
+
+// Uuid returns an unique identifier.
+func (provider *PrivacyStorer) Uuid() string {
+	return fmt.Sprintf("privacy(%s)", provider.inner.Uuid())
+}
+
+// Reset resets the wrapped backend.
+func (provider *PrivacyStorer) Reset() error {
+	return provider.inner.Reset()
+}
+
+// GetMultiLevel runs the fresh/stale election under the hashed key.
+func (provider *PrivacyStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	return provider.inner.GetMultiLevel(provider.hashKey(key), req, validator)
+}
+
+// SetMultiLevel stores the response under hashed base, varied and real keys.
+func (provider *PrivacyStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	return provider.inner.SetMultiLevel(provider.hashKey(baseKey), provider.hashKey(variedKey), value, variedHeaders, etag, duration, provider.hashKey(realKey))
+}
+
+var _ Storer = (*PrivacyStorer)(nil)
@@ -0,0 +1,194 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	replicationSet = iota
+	replicationSetMultiLevel
+	replicationDelete
+	replicationDeleteMany
+)
+
+// replicationOp is a single write or purge waiting to be mirrored.
+type replicationOp struct {
+	kind          int
+	baseKey       string
+	variedKey     string
+	value         []byte
+	variedHeaders http.Header
+	etag          string
+	duration      time.Duration
+	realKey       string
+	enqueued      time.Time
+}
+
+// ReplicatedStorer mirrors writes and purges to a secondary backend
+// asynchronously, e.g. a Redis endpoint in another region, so geo-distributed
+// fleets converge on the same content and purges. Reads are always served by
+// the primary.
+type ReplicatedStorer struct {
+	primary Storer
+	replica Storer
+
+	queue      chan replicationOp
+	replicated atomic.Int64
+	dropped    atomic.Int64
+	lagNanos   atomic.Int64
+}
+
+// NewReplicatedStorer wraps the primary with async replication to the
+// replica. queueSize bounds the number of in-flight operations; once the
+// queue is full, further operations are dropped and counted instead of
+// blocking the request path.
+func NewReplicatedStorer(primary, replica Storer, queueSize int) *ReplicatedStorer {
+	if queueSize <= 0 {
+		queueSize = 1024
+	}
+
+	return &ReplicatedStorer{
+		primary: primary,
+		replica: replica,
+		queue:   make(chan replicationOp, queueSize),
+	}
+}
+
+// Lag returns the queue latency of the last replicated operation, the number
+// of operations replicated so far and the number dropped on queue overflow.
+func (provider *ReplicatedStorer) Lag() (lag time.Duration, replicated, dropped int64) {
+	return time.Duration(provider.lagNanos.Load()), provider.replicated.Load(), provider.dropped.Load()
+}
+
+// enqueue hands the operation to the replication worker without blocking.
+func (provider *ReplicatedStorer) enqueue(op replicationOp) {
+	op.enqueued = time.Now()
+
+	select {
+	case provider.queue <- op:
+	default:
+		provider.dropped.Add(1)
+	}
+}
+
+// replicate applies queued operations to the replica and records the lag.
+func (provider *ReplicatedStorer) replicate() {
+	for op := range provider.queue {
+		switch op.kind {
+		case replicationSet:
+			_ = provider.replica.Set(op.baseKey, op.value, op.duration)
+		case replicationSetMultiLevel:
+			_ = provider.replica.SetMultiLevel(op.baseKey, op.variedKey, op.value, op.variedHeaders, op.etag, op.duration, op.realKey)
+		case replicationDelete:
+			provider.replica.Delete(op.baseKey)
+		case replicationDeleteMany:
+			provider.replica.DeleteMany(op.baseKey)
+		}
+
+		provider.replicated.Add(1)
+		provider.lagNanos.Store(int64(time.Since(op.enqueued)))
+	}
+}
+
+// MapKeys returns the map of existing keys from the primary.
+func (provider *ReplicatedStorer) MapKeys(prefix string) map[string]string {
+	return provider.primary.MapKeys(prefix)
+}
+
+// ListKeys returns the list of existing keys from the primary.
+func (provider *ReplicatedStorer) ListKeys() []string {
+	return provider.primary.ListKeys()
+}
+
+// Get returns the value from the primary.
+func (provider *ReplicatedStorer) Get(key string) []byte {
+	return provider.primary.Get(key)
+}
+
+// Set stores the value in the primary and mirrors it asynchronously.
+func (provider *ReplicatedStorer) Set(key string, value []byte, duration time.Duration) error {
+	if err := provider.primary.Set(key, value, duration); err != nil {
+		return err
+	}
+
+	provider.enqueue(replicationOp{kind: replicationSet, baseKey: key, value: value, duration: duration})
+
+	return nil
+}
+
+// Delete removes the key from the primary and mirrors the purge.
+func (provider *ReplicatedStorer) Delete(key string) {
+	provider.primary.Delete(key)
+	provider.enqueue(replicationOp{kind: replicationDelete, baseKey: key})
+}
+
+// DeleteMany removes the matching keys from the primary and mirrors the purge.
+func (provider *ReplicatedStorer) DeleteMany(key string) {
+	provider.primary.DeleteMany(key)
+	provider.enqueue(replicationOp{kind: replicationDeleteMany, baseKey: key})
+}
+
+// Init initializes both backends and starts the replication worker.
+func (provider *ReplicatedStorer) Init() error {
+	if err := provider.primary.Init(); err != nil {
+		return err
+	}
+
+	if err := provider.replica.Init(); err != nil {
+		return err
+	}
+
+	go provider.replicate()
+
+	return nil
+}
+
+// Name returns the storer name.
+func (provider *ReplicatedStorer) Name() string {
+	return "REPLICATED"
+}
+
+// Uuid returns an unique identifier combining both backends.
+func (provider *ReplicatedStorer) Uuid() string {
+	return fmt.Sprintf("replicated(%s,%s)", provider.primary.Uuid(), provider.replica.Uuid())
+}
+
+// Reset resets both backends.
+func (provider *ReplicatedStorer) Reset() error {
+	if err := provider.primary.Reset(); err != nil {
+		return err
+	}
+
+	return provider.replica.Reset()
+}
+
+// GetMultiLevel runs the fresh/stale election on the primary.
+func (provider *ReplicatedStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	return provider.primary.GetMultiLevel(key, req, validator)
+}
+
+// SetMultiLevel stores the response in the primary and mirrors it
+// asynchronously.
+func (provider *ReplicatedStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	if err := provider.primary.SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey); err != nil {
+		return err
+	}
+
+	provider.enqueue(replicationOp{
+		kind:          replicationSetMultiLevel,
+		baseKey:       baseKey,
+		variedKey:     variedKey,
+		value:         value,
+		variedHeaders: variedHeaders,
+		etag:          etag,
+		duration:      duration,
+		realKey:       realKey,
+	})
+
+	return nil
+}
+
+var _ Storer = (*ReplicatedStorer)(nil)
@@ -1,6 +1,11 @@
 package core
 
-import "time"
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"time"
+)
 
 type Revalidator struct {
 	Matched                     bool
@@ -17,6 +22,29 @@ type Revalidator struct {
 	IfMatch                     []string
 	RequestETags                []string
 	ResponseETag                string
+	// EarlyRefresh reports that the served entry is still fresh but close
+	// enough to expiry that the caller should revalidate it now, while the
+	// other in-flight requests keep being served from storage.
+	EarlyRefresh bool
+}
+
+// ValidateLastModifiedFromHeader validates the stored Last-Modified value
+// against the If-Modified-Since request validator, so conditional requests can
+// be answered from storage without comparing full bodies. Per RFC 9110
+// §13.1.3, If-Modified-Since is ignored when the request also carries
+// If-None-Match, so the ETag verdict is never overwritten here.
+func ValidateLastModifiedFromHeader(lastModified string, validator *Revalidator) {
+	if validator.IfNoneMatchPresent || !validator.IfModifiedSincePresent || lastModified == "" {
+		return
+	}
+
+	lastModifiedTime, err := http.ParseTime(lastModified)
+	if err != nil {
+		return
+	}
+
+	validator.NeedRevalidation = true
+	validator.NotModified = !lastModifiedTime.After(validator.IfModifiedSince)
 }
 
 func ValidateETagFromHeader(etag string, validator *Revalidator) {
@@ -75,3 +103,17 @@ func ValidateETagFromHeader(etag string, validator *Revalidator) {
 		}
 	}
 }
+
+// ResponseLastModified parses the stored response dump and returns its
+// Last-Modified header, so providers can persist the validator in their
+// mapping metadata without keeping the parsed response around.
+func ResponseLastModified(value []byte) string {
+	response, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(value)), nil)
+	if err != nil {
+		return ""
+	}
+
+	defer response.Body.Close()
+
+	return response.Header.Get("Last-Modified")
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ShadowStorer wraps two Storer implementations during a backend migration.
+// Reads are served from the primary while every write is applied to both, so
+// the shadow backend fills up under real traffic. Once the shadow is deemed
+// converged, Cutover flips reads to it without a restart.
+type ShadowStorer struct {
+	primary Storer
+	shadow  Storer
+
+	cutover     atomic.Bool
+	reads       atomic.Int64
+	divergences atomic.Int64
+}
+
+// NewShadowStorer wraps the given primary and shadow storers.
+func NewShadowStorer(primary, shadow Storer) *ShadowStorer {
+	return &ShadowStorer{primary: primary, shadow: shadow}
+}
+
+// active returns the storer currently serving reads.
+func (provider *ShadowStorer) active() Storer {
+	if provider.cutover.Load() {
+		return provider.shadow
+	}
+
+	return provider.primary
+}
+
+// standby returns the storer not serving reads.
+func (provider *ShadowStorer) standby() Storer {
+	if provider.cutover.Load() {
+		return provider.primary
+	}
+
+	return provider.shadow
+}
+
+// Cutover switches reads to the shadow backend.
+func (provider *ShadowStorer) Cutover() {
+	provider.cutover.Store(true)
+}
+
+// Divergences returns how many reads observed a different value on the
+// standby backend, and how many reads were compared in total.
+func (provider *ShadowStorer) Divergences() (divergences, reads int64) {
+	return provider.divergences.Load(), provider.reads.Load()
+}
+
+// MapKeys returns the map of existing keys from the active backend.
+func (provider *ShadowStorer) MapKeys(prefix string) map[string]string {
+	return provider.active().MapKeys(prefix)
+}
+
+// ListKeys returns the list of existing keys from the active backend.
+func (provider *ShadowStorer) ListKeys() []string {
+	return provider.active().ListKeys()
+}
+
+// Get returns the value from the active backend and counts a divergence when
+// the standby backend holds a different value for the same key.
+func (provider *ShadowStorer) Get(key string) []byte {
+	value := provider.active().Get(key)
+
+	provider.reads.Add(1)
+
+	if !bytes.Equal(value, provider.standby().Get(key)) {
+		provider.divergences.Add(1)
+	}
+
+	return value
+}
+
+// Set stores the value in both backends.
+func (provider *ShadowStorer) Set(key string, value []byte, duration time.Duration) error {
+	if err := provider.primary.Set(key, value, duration); err != nil {
+		return err
+	}
+
+	return provider.shadow.Set(key, value, duration)
+}
+
+// Delete removes the key from both backends.
+func (provider *ShadowStorer) Delete(key string) {
+	provider.primary.Delete(key)
+	provider.shadow.Delete(key)
+}
+
+// DeleteMany removes the matching keys from both backends.
+func (provider *ShadowStorer) DeleteMany(key string) {
+	provider.primary.DeleteMany(key)
+	provider.shadow.DeleteMany(key)
+}
+
+// Init initializes both backends.
+func (provider *ShadowStorer) Init() error {
+	if err := provider.primary.Init(); err != nil {
+		return err
+	}
+
+	return provider.shadow.Init()
+}
+
+// Name returns the storer name.
+func (provider *ShadowStorer) Name() string {
+	return "SHADOW"
+}
+
+// Uuid returns an unique identifier combining both backends.
+func (provider *ShadowStorer) Uuid() string {
+	return fmt.Sprintf("shadow(%s,%s)", provider.primary.Uuid(), provider.shadow.Uuid())
+}
+
+// Reset resets both backends.
+func (provider *ShadowStorer) Reset() error {
+	if err := provider.primary.Reset(); err != nil {
+		return err
+	}
+
+	return provider.shadow.Reset()
+}
+
+// GetMultiLevel runs the fresh/stale election on the active backend.
+func (provider *ShadowStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	return provider.active().GetMultiLevel(key, req, validator)
+}
+
+// SetMultiLevel stores the response in both backends.
+func (provider *ShadowStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	if err := provider.primary.SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey); err != nil {
+		return err
+	}
+
+	return provider.shadow.SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey)
+}
+
+var _ Storer = (*ShadowStorer)(nil)
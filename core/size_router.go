@@ -0,0 +1,124 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SizeRouterStorer routes small bodies to a fast backend (typically
+// in-memory) and everything above the threshold to a roomier one (typically
+// disk or object storage), so tiny hot objects never pay disk latency and
+// huge objects never bloat RAM. Each backend keeps its own mappings; reads
+// consult the small backend first.
+type SizeRouterStorer struct {
+	small     Storer
+	large     Storer
+	threshold int64
+}
+
+// NewSizeRouterStorer routes bodies of threshold bytes or more to the large
+// backend. A non-positive threshold defaults to 64 KiB.
+func NewSizeRouterStorer(small, large Storer, threshold int64) *SizeRouterStorer {
+	if threshold <= 0 {
+		threshold = 64 << 10
+	}
+
+	return &SizeRouterStorer{small: small, large: large, threshold: threshold}
+}
+
+// route picks the backend a body of the given size belongs to.
+func (provider *SizeRouterStorer) route(size int) Storer {
+	if int64(size) < provider.threshold {
+		return provider.small
+	}
+
+	return provider.large
+}
+
+// MapKeys returns the map of existing keys from both backends.
+func (provider *SizeRouterStorer) MapKeys(prefix string) map[string]string {
+	keys := provider.large.MapKeys(prefix)
+	for key, value := range provider.small.MapKeys(prefix) {
+		keys[key] = value
+	}
+
+	return keys
+}
+
+// ListKeys returns the list of existing keys from both backends.
+func (provider *SizeRouterStorer) ListKeys() []string {
+	return append(provider.small.ListKeys(), provider.large.ListKeys()...)
+}
+
+// Get returns the value from the backend holding the key.
+func (provider *SizeRouterStorer) Get(key string) []byte {
+	if value := provider.small.Get(key); len(value) != 0 {
+		return value
+	}
+
+	return provider.large.Get(key)
+}
+
+// Set stores the value in the backend matching its size.
+func (provider *SizeRouterStorer) Set(key string, value []byte, duration time.Duration) error {
+	return provider.route(len(value)).Set(key, value, duration)
+}
+
+// Delete removes the key from both backends.
+func (provider *SizeRouterStorer) Delete(key string) {
+	provider.small.Delete(key)
+	provider.large.Delete(key)
+}
+
+// DeleteMany removes the matching keys from both backends.
+func (provider *SizeRouterStorer) DeleteMany(key string) {
+	provider.small.DeleteMany(key)
+	provider.large.DeleteMany(key)
+}
+
+// Init initializes both backends.
+func (provider *SizeRouterStorer) Init() error {
+	if err := provider.small.Init(); err != nil {
+		return err
+	}
+
+	return provider.large.Init()
+}
+
+// Name returns the storer name.
+func (provider *SizeRouterStorer) Name() string {
+	return "SIZE_ROUTER"
+}
+
+// Uuid returns an unique identifier combining both backends.
+func (provider *SizeRouterStorer) Uuid() string {
+	return fmt.Sprintf("size-router(%d,%s,%s)", provider.threshold, provider.small.Uuid(), provider.large.Uuid())
+}
+
+// Reset resets both backends.
+func (provider *SizeRouterStorer) Reset() error {
+	if err := provider.small.Reset(); err != nil {
+		return err
+	}
+
+	return provider.large.Reset()
+}
+
+// GetMultiLevel runs the fresh/stale election on the small backend first and
+// falls back to the large one.
+func (provider *SizeRouterStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale = provider.small.GetMultiLevel(key, req, validator)
+	if fresh != nil || stale != nil {
+		return fresh, stale
+	}
+
+	return provider.large.GetMultiLevel(key, req, validator)
+}
+
+// SetMultiLevel stores the response in the backend matching its size.
+func (provider *SizeRouterStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	return provider.route(len(value)).SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey)
+}
+
+var _ Storer = (*SizeRouterStorer)(nil)
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// TieredStorer chains multiple Storer implementations ordered from fastest to
+// most complete (e.g. in-memory, filesystem, remote). Reads walk the tiers in
+// order; writes and purges are applied to every tier.
+type TieredStorer struct {
+	tiers []Storer
+
+	// readRepair backfills upper tiers asynchronously when a key is only
+	// found in a lower one, so the tiers converge under read traffic.
+	readRepair bool
+	repairTTL  time.Duration
+	repairs    atomic.Int64
+}
+
+// NewTieredStorer chains the given tiers. When readRepair is true, hits served
+// by a lower tier are copied back into the tiers above it with repairTTL.
+func NewTieredStorer(readRepair bool, repairTTL time.Duration, tiers ...Storer) *TieredStorer {
+	return &TieredStorer{tiers: tiers, readRepair: readRepair, repairTTL: repairTTL}
+}
+
+// Repairs returns how many backfills were triggered by read-repair.
+func (provider *TieredStorer) Repairs() int64 {
+	return provider.repairs.Load()
+}
+
+// repair copies the key into every tier above the one that served it.
+func (provider *TieredStorer) repair(key string, value []byte, hitTier int) {
+	if !provider.readRepair || hitTier == 0 {
+		return
+	}
+
+	provider.repairs.Add(1)
+
+	go func() {
+		for _, tier := range provider.tiers[:hitTier] {
+			_ = tier.Set(key, value, provider.repairTTL)
+		}
+	}()
+}
+
+// repairMultiLevel copies the mapping and every mapped variant into the tiers
+// above the one that served the election.
+func (provider *TieredStorer) repairMultiLevel(key string, hitTier int) {
+	if !provider.readRepair || hitTier == 0 {
+		return
+	}
+
+	provider.repairs.Add(1)
+
+	go func() {
+		source := provider.tiers[hitTier]
+
+		mappingValue := source.Get(MappingKeyPrefix + key)
+		if len(mappingValue) == 0 {
+			return
+		}
+
+		mapping, err := DecodeMapping(mappingValue)
+		if err != nil {
+			return
+		}
+
+		for _, tier := range provider.tiers[:hitTier] {
+			_ = tier.Set(MappingKeyPrefix+key, mappingValue, provider.repairTTL)
+
+			for variedKey := range mapping.GetMapping() {
+				if value := source.Get(variedKey); len(value) != 0 {
+					_ = tier.Set(variedKey, value, provider.repairTTL)
+				}
+			}
+		}
+	}()
+}
+
+// MapKeys returns the map of existing keys from the last, most complete tier.
+func (provider *TieredStorer) MapKeys(prefix string) map[string]string {
+	return provider.tiers[len(provider.tiers)-1].MapKeys(prefix)
+}
+
+// ListKeys returns the list of existing keys from the last, most complete tier.
+func (provider *TieredStorer) ListKeys() []string {
+	return provider.tiers[len(provider.tiers)-1].ListKeys()
+}
+
+// Get walks the tiers in order and returns the first hit, backfilling the
+// tiers above it when read-repair is enabled.
+func (provider *TieredStorer) Get(key string) []byte {
+	for tier, storer := range provider.tiers {
+		if value := storer.Get(key); len(value) != 0 {
+			provider.repair(key, value, tier)
+
+			return value
+		}
+	}
+
+	return nil
+}
+
+// Set stores the value in every tier.
+func (provider *TieredStorer) Set(key string, value []byte, duration time.Duration) error {
+	for _, tier := range provider.tiers {
+		if err := tier.Set(key, value, duration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete removes the key from every tier.
+func (provider *TieredStorer) Delete(key string) {
+	for _, tier := range provider.tiers {
+		tier.Delete(key)
+	}
+}
+
+// DeleteMany removes the matching keys from every tier.
+func (provider *TieredStorer) DeleteMany(key string) {
+	for _, tier := range provider.tiers {
+		tier.DeleteMany(key)
+	}
+}
+
+// Init initializes every tier.
+func (provider *TieredStorer) Init() error {
+	for _, tier := range provider.tiers {
+		if err := tier.Init(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Name returns the storer name.
+func (provider *TieredStorer) Name() string {
+	return "TIERED"
+}
+
+// Uuid returns an unique identifier combining every tier.
+func (provider *TieredStorer) Uuid() string {
+	uuids := make([]string, len(provider.tiers))
+	for i, tier := range provider.tiers {
+		uuids[i] = tier.Uuid()
+	}
+
+	return fmt.Sprintf("tiered(%s)", strings.Join(uuids, ","))
+}
+
+// Reset resets every tier.
+func (provider *TieredStorer) Reset() error {
+	for _, tier := range provider.tiers {
+		if err := tier.Reset(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetMultiLevel runs the fresh/stale election on each tier in order and
+// repairs the tiers above the first one that answers.
+func (provider *TieredStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	for tier, storer := range provider.tiers {
+		fresh, stale = storer.GetMultiLevel(key, req, validator)
+		if fresh != nil || stale != nil {
+			provider.repairMultiLevel(key, tier)
+
+			return fresh, stale
+		}
+	}
+
+	return fresh, stale
+}
+
+// SetMultiLevel stores the response in every tier.
+func (provider *TieredStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	for _, tier := range provider.tiers {
+		if err := tier.SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var _ Storer = (*TieredStorer)(nil)
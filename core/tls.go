@@ -0,0 +1,107 @@
+package core
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLSBlock is the TLS/mTLS configuration shared by every remote provider, so
+// operators configure certificates the same way regardless of the backend.
+type TLSBlock struct {
+	CAFile             string `json:"ca_file" yaml:"ca_file"`
+	CertFile           string `json:"cert_file" yaml:"cert_file"`
+	KeyFile            string `json:"key_file" yaml:"key_file"`
+	ServerName         string `json:"server_name" yaml:"server_name"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify" yaml:"insecure_skip_verify"`
+}
+
+// ParseTLSBlock extracts the "tls" block from a provider configuration map
+// and builds the resulting *tls.Config. It returns nil without error when no
+// block is present.
+func ParseTLSBlock(configuration interface{}) (*tls.Config, error) {
+	configMap, ok := configuration.(map[string]interface{})
+	if !ok || configMap == nil {
+		return nil, nil
+	}
+
+	blockMap, ok := configMap["tls"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	block := TLSBlock{}
+
+	if v, ok := blockMap["ca_file"].(string); ok {
+		block.CAFile = v
+	}
+
+	if v, ok := blockMap["cert_file"].(string); ok {
+		block.CertFile = v
+	}
+
+	if v, ok := blockMap["key_file"].(string); ok {
+		block.KeyFile = v
+	}
+
+	if v, ok := blockMap["server_name"].(string); ok {
+		block.ServerName = v
+	}
+
+	if v, ok := blockMap["insecure_skip_verify"].(bool); ok {
+		block.InsecureSkipVerify = v
+	}
+
+	return block.Build()
+}
+
+// Build converts the block into a *tls.Config. The client certificate is read
+// from disk on every handshake, so rotated certificate files are picked up
+// without restarting the server.
+func (t TLSBlock) Build() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", t.CAFile)
+		}
+
+		config.RootCAs = pool
+	}
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		if t.CertFile == "" || t.KeyFile == "" {
+			return nil, errors.New("cert_file and key_file must both be set")
+		}
+
+		// Fail at startup on an unreadable pair instead of on the first
+		// handshake.
+		if _, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile); err != nil {
+			return nil, err
+		}
+
+		certFile, keyFile := t.CertFile, t.KeyFile
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+			if err != nil {
+				return nil, err
+			}
+
+			return &certificate, nil
+		}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// TTLRouterStorer routes short-lived entries to an ephemeral backend
+// (typically in-memory) and long-lived ones to persistent storage, since
+// persisting sub-minute entries to disk is pure write amplification. Reads
+// consult the ephemeral backend first.
+type TTLRouterStorer struct {
+	ephemeral  Storer
+	persistent Storer
+	threshold  time.Duration
+}
+
+// NewTTLRouterStorer routes entries living strictly less than threshold to
+// the ephemeral backend. A non-positive threshold defaults to one minute.
+func NewTTLRouterStorer(ephemeral, persistent Storer, threshold time.Duration) *TTLRouterStorer {
+	if threshold <= 0 {
+		threshold = time.Minute
+	}
+
+	return &TTLRouterStorer{ephemeral: ephemeral, persistent: persistent, threshold: threshold}
+}
+
+// route picks the backend an entry with the given TTL belongs to.
+func (provider *TTLRouterStorer) route(duration time.Duration) Storer {
+	if duration > 0 && duration < provider.threshold {
+		return provider.ephemeral
+	}
+
+	return provider.persistent
+}
+
+// MapKeys returns the map of existing keys from both backends.
+func (provider *TTLRouterStorer) MapKeys(prefix string) map[string]string {
+	keys := provider.persistent.MapKeys(prefix)
+	for key, value := range provider.ephemeral.MapKeys(prefix) {
+		keys[key] = value
+	}
+
+	return keys
+}
+
+// ListKeys returns the list of existing keys from both backends.
+func (provider *TTLRouterStorer) ListKeys() []string {
+	return append(provider.ephemeral.ListKeys(), provider.persistent.ListKeys()...)
+}
+
+// Get returns the value from the backend holding the key.
+func (provider *TTLRouterStorer) Get(key string) []byte {
+	if value := provider.ephemeral.Get(key); len(value) != 0 {
+		return value
+	}
+
+	return provider.persistent.Get(key)
+}
+
+// Set stores the value in the backend matching its TTL.
+func (provider *TTLRouterStorer) Set(key string, value []byte, duration time.Duration) error {
+	return provider.route(duration).Set(key, value, duration)
+}
+
+// Delete removes the key from both backends.
+func (provider *TTLRouterStorer) Delete(key string) {
+	provider.ephemeral.Delete(key)
+	provider.persistent.Delete(key)
+}
+
+// DeleteMany removes the matching keys from both backends.
+func (provider *TTLRouterStorer) DeleteMany(key string) {
+	provider.ephemeral.DeleteMany(key)
+	provider.persistent.DeleteMany(key)
+}
+
+// Init initializes both backends.
+func (provider *TTLRouterStorer) Init() error {
+	if err := provider.ephemeral.Init(); err != nil {
+		return err
+	}
+
+	return provider.persistent.Init()
+}
+
+// Name returns the storer name.
+func (provider *TTLRouterStorer) Name() string {
+	return "TTL_ROUTER"
+}
+
+// Uuid returns an unique identifier combining both backends.
+func (provider *TTLRouterStorer) Uuid() string {
+	return fmt.Sprintf("ttl-router(%s,%s,%s)", provider.threshold, provider.ephemeral.Uuid(), provider.persistent.Uuid())
+}
+
+// Reset resets both backends.
+func (provider *TTLRouterStorer) Reset() error {
+	if err := provider.ephemeral.Reset(); err != nil {
+		return err
+	}
+
+	return provider.persistent.Reset()
+}
+
+// GetMultiLevel runs the fresh/stale election on the ephemeral backend first
+// and falls back to the persistent one.
+func (provider *TTLRouterStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale = provider.ephemeral.GetMultiLevel(key, req, validator)
+	if fresh != nil || stale != nil {
+		return fresh, stale
+	}
+
+	return provider.persistent.GetMultiLevel(key, req, validator)
+}
+
+// SetMultiLevel stores the response in the backend matching its TTL.
+func (provider *TTLRouterStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	return provider.route(duration).SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey)
+}
+
+var _ Storer = (*TTLRouterStorer)(nil)
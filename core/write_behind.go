@@ -0,0 +1,263 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// writeBehindOp is one queued persist, coalesced per key: re-writing a key
+// whose persist is still queued replaces the payload in place instead of
+// growing the queue.
+type writeBehindOp struct {
+	key      string
+	apply    func(Storer) error
+	canceled bool
+}
+
+// WriteBehindStorer wraps a Storer and acknowledges writes once they are
+// queued, draining them in the background. It is meant as the disk tier of a
+// memory-first TieredStorer: Set returns as soon as the memory tier holds the
+// value, and the bounded queue persists it afterwards. The semantics are
+// deliberately lossy on crash — entries queued but not yet persisted
+// disappear — which is why the mode is opt-in; mapping keys stay
+// write-through regardless, so a crash can never leave a mapping pointing at
+// entries that were persisted without one.
+type WriteBehindStorer struct {
+	back   Storer
+	logger Logger
+
+	mu      sync.Mutex
+	pending map[string]*writeBehindOp
+	queue   chan string
+
+	// blockWhenFull makes the caller wait for a queue slot instead of
+	// falling back to a synchronous write-through; either way a full
+	// queue never silently drops a write.
+	blockWhenFull bool
+
+	started bool
+	stop    chan struct{}
+}
+
+// NewWriteBehindStorer wraps back with a write-behind queue of queueDepth
+// entries. When the queue is full, blockWhenFull selects between waiting for
+// a slot and falling back to a synchronous write.
+func NewWriteBehindStorer(back Storer, queueDepth int, blockWhenFull bool, logger Logger) *WriteBehindStorer {
+	if queueDepth <= 0 {
+		queueDepth = 1024
+	}
+
+	return &WriteBehindStorer{
+		back:          back,
+		logger:        logger,
+		pending:       map[string]*writeBehindOp{},
+		queue:         make(chan string, queueDepth),
+		blockWhenFull: blockWhenFull,
+		stop:          make(chan struct{}),
+	}
+}
+
+// Pending returns how many persists are still queued.
+func (provider *WriteBehindStorer) Pending() int {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	return len(provider.pending)
+}
+
+// drain applies queued persists until the storer is reset.
+func (provider *WriteBehindStorer) drain() {
+	for {
+		select {
+		case key := <-provider.queue:
+			provider.mu.Lock()
+			op := provider.pending[key]
+			delete(provider.pending, key)
+			provider.mu.Unlock()
+
+			if op == nil || op.canceled {
+				continue
+			}
+
+			if err := op.apply(provider.back); err != nil {
+				provider.logger.Errorf("Impossible to persist the key %s behind the memory tier, %v", key, err)
+			}
+		case <-provider.stop:
+			return
+		}
+	}
+}
+
+// enqueue coalesces the persist under key, blocking or degrading to a
+// synchronous write when the queue is full.
+func (provider *WriteBehindStorer) enqueue(key string, apply func(Storer) error) error {
+	provider.mu.Lock()
+
+	if op, found := provider.pending[key]; found {
+		// A persist for this key is still queued, replace it in place.
+		op.apply = apply
+		op.canceled = false
+		provider.mu.Unlock()
+
+		return nil
+	}
+
+	op := &writeBehindOp{key: key, apply: apply}
+	provider.pending[key] = op
+	provider.mu.Unlock()
+
+	if provider.blockWhenFull {
+		provider.queue <- key
+
+		return nil
+	}
+
+	select {
+	case provider.queue <- key:
+		return nil
+	default:
+		// Queue full: degrade to write-through rather than dropping the
+		// write silently.
+		provider.mu.Lock()
+		delete(provider.pending, key)
+		provider.mu.Unlock()
+
+		return apply(provider.back)
+	}
+}
+
+// cancel drops a queued persist for the key, so a delete can never be undone
+// by an older write resurrecting on drain.
+func (provider *WriteBehindStorer) cancel(key string) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if op, found := provider.pending[key]; found {
+		op.canceled = true
+		delete(provider.pending, key)
+	}
+}
+
+// MapKeys returns the map of existing keys from the wrapped storer.
+func (provider *WriteBehindStorer) MapKeys(prefix string) map[string]string {
+	return provider.back.MapKeys(prefix)
+}
+
+// ListKeys returns the list of existing keys from the wrapped storer.
+func (provider *WriteBehindStorer) ListKeys() []string {
+	return provider.back.ListKeys()
+}
+
+// Get reads from the wrapped storer; the memory tier in front of this one
+// serves the entries whose persist is still queued.
+func (provider *WriteBehindStorer) Get(key string) []byte {
+	return provider.back.Get(key)
+}
+
+// Set queues the persist and acknowledges immediately. Mapping keys stay
+// write-through: losing a queued mapping while its entries were persisted
+// would orphan files the election logic can never reach.
+func (provider *WriteBehindStorer) Set(key string, value []byte, duration time.Duration) error {
+	if strings.Contains(key, MappingKeyPrefix) {
+		return provider.back.Set(key, value, duration)
+	}
+
+	stored := make([]byte, len(value))
+	copy(stored, value)
+
+	return provider.enqueue(key, func(back Storer) error {
+		return back.Set(key, stored, duration)
+	})
+}
+
+// Delete cancels any queued persist for the key and removes it from the
+// wrapped storer.
+func (provider *WriteBehindStorer) Delete(key string) {
+	provider.cancel(key)
+	provider.back.Delete(key)
+}
+
+// DeleteMany cancels the matching queued persists and purges the wrapped
+// storer.
+func (provider *WriteBehindStorer) DeleteMany(key string) {
+	if rgKey, e := regexp.Compile(key); e == nil {
+		provider.mu.Lock()
+		for pendingKey, op := range provider.pending {
+			if rgKey.MatchString(pendingKey) {
+				op.canceled = true
+				delete(provider.pending, pendingKey)
+			}
+		}
+		provider.mu.Unlock()
+	}
+
+	provider.back.DeleteMany(key)
+}
+
+// Init initializes the wrapped storer and starts the drain loop.
+func (provider *WriteBehindStorer) Init() error {
+	if err := provider.back.Init(); err != nil {
+		return err
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if !provider.started {
+		provider.started = true
+
+		go provider.drain()
+	}
+
+	return nil
+}
+
+// Name returns the storer name.
+func (provider *WriteBehindStorer) Name() string {
+	return "WRITE_BEHIND"
+}
+
+// Uuid returns an unique identifier wrapping the backing one.
+func (provider *WriteBehindStorer) Uuid() string {
+	return fmt.Sprintf("write-behind(%s)", provider.back.Uuid())
+}
+
+// Reset drops the queued persists and resets the wrapped storer.
+func (provider *WriteBehindStorer) Reset() error {
+	provider.mu.Lock()
+	for key, op := range provider.pending {
+		op.canceled = true
+		delete(provider.pending, key)
+	}
+	provider.mu.Unlock()
+
+	return provider.back.Reset()
+}
+
+// GetMultiLevel delegates the fresh/stale election to the wrapped storer.
+func (provider *WriteBehindStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	return provider.back.GetMultiLevel(key, req, validator)
+}
+
+// SetMultiLevel queues the whole entry-plus-mapping persist as one unit, so a
+// crash loses both together and never leaves a mapping pointing at a missing
+// entry.
+func (provider *WriteBehindStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	stored := make([]byte, len(value))
+	copy(stored, value)
+
+	headers := http.Header{}
+	for name, values := range variedHeaders {
+		headers[name] = append([]string{}, values...)
+	}
+
+	return provider.enqueue(variedKey, func(back Storer) error {
+		return back.SetMultiLevel(baseKey, variedKey, stored, headers, etag, duration, realKey)
+	})
+}
+
+var _ Storer = (*WriteBehindStorer)(nil)
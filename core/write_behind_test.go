@@ -0,0 +1,118 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/WJQSERVER/souin-storages/core"
+)
+
+// waitDrained polls until the write-behind queue is empty.
+func waitDrained(t *testing.T, provider *core.WriteBehindStorer) {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		if provider.Pending() == 0 {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatal("The write-behind queue should have drained")
+}
+
+func TestWriteBehindStorer_SetIsPersistedInBackground(t *testing.T) {
+	back := core.NewMemoryStorer(1<<20, time.Minute, nopLogger{})
+	provider := core.NewWriteBehindStorer(back, 16, false, nopLogger{})
+
+	if err := provider.Init(); err != nil {
+		t.Errorf("Init shouldn't fail: %v", err)
+	}
+
+	if err := provider.Set("key", []byte("value"), time.Minute); err != nil {
+		t.Errorf("Set shouldn't fail: %v", err)
+	}
+
+	waitDrained(t, provider)
+
+	if res := back.Get("key"); string(res) != "value" {
+		t.Errorf("%s not corresponding to value in the backing storer", string(res))
+	}
+}
+
+func TestWriteBehindStorer_MappingKeysStayWriteThrough(t *testing.T) {
+	back := core.NewMemoryStorer(1<<20, time.Minute, nopLogger{})
+	provider := core.NewWriteBehindStorer(back, 16, false, nopLogger{})
+	_ = provider.Init()
+
+	if err := provider.Set(core.MappingKeyPrefix+"base", []byte("mapping"), time.Minute); err != nil {
+		t.Errorf("Set shouldn't fail: %v", err)
+	}
+
+	// No drain wait: the mapping write must already be visible.
+	if res := back.Get(core.MappingKeyPrefix + "base"); string(res) != "mapping" {
+		t.Error("Mapping keys should be persisted synchronously")
+	}
+
+	if provider.Pending() != 0 {
+		t.Error("Mapping keys should not ride the queue")
+	}
+}
+
+func TestWriteBehindStorer_DeleteCancelsQueuedPersist(t *testing.T) {
+	back := core.NewMemoryStorer(1<<20, time.Minute, nopLogger{})
+	provider := core.NewWriteBehindStorer(back, 16, false, nopLogger{})
+
+	// Not initialized yet, so the queue is not draining: the delete races
+	// nothing and must cancel the still-queued persist.
+	if err := provider.Set("key", []byte("value"), time.Minute); err != nil {
+		t.Errorf("Set shouldn't fail: %v", err)
+	}
+
+	provider.Delete("key")
+
+	_ = provider.Init()
+	waitDrained(t, provider)
+
+	if res := back.Get("key"); len(res) != 0 {
+		t.Error("A deleted key should not resurrect when the queue drains")
+	}
+}
+
+func TestWriteBehindStorer_FullQueueFallsBackToWriteThrough(t *testing.T) {
+	back := core.NewMemoryStorer(1<<20, time.Minute, nopLogger{})
+	// Depth 1 and no drain loop: the second distinct key overflows.
+	provider := core.NewWriteBehindStorer(back, 1, false, nopLogger{})
+
+	if err := provider.Set("first", []byte("a"), time.Minute); err != nil {
+		t.Errorf("Set shouldn't fail: %v", err)
+	}
+
+	if err := provider.Set("second", []byte("b"), time.Minute); err != nil {
+		t.Errorf("The overflowing Set should degrade to write-through: %v", err)
+	}
+
+	if res := back.Get("second"); string(res) != "b" {
+		t.Error("The overflowing write should land synchronously in the backing storer")
+	}
+}
+
+func TestWriteBehindStorer_CoalescesQueuedWrites(t *testing.T) {
+	back := core.NewMemoryStorer(1<<20, time.Minute, nopLogger{})
+	provider := core.NewWriteBehindStorer(back, 16, false, nopLogger{})
+
+	_ = provider.Set("key", []byte("old"), time.Minute)
+	_ = provider.Set("key", []byte("new"), time.Minute)
+
+	if provider.Pending() != 1 {
+		t.Errorf("Two writes of one key should coalesce into one persist, %d pending", provider.Pending())
+	}
+
+	_ = provider.Init()
+	waitDrained(t, provider)
+
+	if res := back.Get("key"); string(res) != "new" {
+		t.Errorf("%s not corresponding to the last written value", string(res))
+	}
+}
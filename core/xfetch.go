@@ -0,0 +1,33 @@
+package core
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// EarlyRefreshBeta controls how aggressively fresh entries are reported
+// refresh-worthy as they approach expiry. 0 disables early refresh, 1 is the
+// value recommended by the XFetch paper, higher values refresh earlier.
+var EarlyRefreshBeta = 1.0
+
+// shouldRefreshEarly implements XFetch-style probabilistic early expiration:
+// the probability of electing an entry for early recomputation rises as its
+// expiry approaches, so a single request revalidates ahead of time instead of
+// every client missing at once when the entry dies.
+func shouldRefreshEarly(storedAt, freshTime time.Time) bool {
+	if EarlyRefreshBeta <= 0 {
+		return false
+	}
+
+	ttl := freshTime.Sub(storedAt)
+	if ttl <= 0 {
+		return false
+	}
+
+	// Without a measured recompute cost, assume it is a small fraction of
+	// the TTL.
+	delta := float64(ttl) / 100
+
+	return float64(Until(freshTime)) < delta*EarlyRefreshBeta*-math.Log(rand.Float64())
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/darkweak/storages/core"
@@ -21,11 +22,14 @@ import (
 // Etcd provider type.
 type Etcd struct {
 	*clientv3.Client
-	stale         time.Duration
-	ctx           context.Context
-	logger        core.Logger
-	reconnecting  bool
-	configuration clientv3.Config
+	stale            time.Duration
+	ctx              context.Context
+	logger           core.Logger
+	reconnecting     bool
+	configuration    clientv3.Config
+	leaseBucketWidth time.Duration
+	leasesMu         sync.Mutex
+	leases           map[int64]clientv3.LeaseID
 }
 
 // Factory function create new Etcd instance.
@@ -40,6 +44,23 @@ func Factory(etcdCfg core.CacheProvider, logger core.Logger, stale time.Duration
 		etcdConfiguration.Logger = zapLogger.Desugar()
 	}
 
+	// One shared lease per TTL bucket instead of one lease per key; keys may
+	// outlive their TTL by up to the bucket width. Set to 0 to get back one
+	// lease per key.
+	leaseBucketWidth := time.Minute
+
+	if etcdConfig, ok := etcdCfg.Configuration.(map[string]interface{}); ok && etcdConfig != nil {
+		if value, ok := etcdConfig["leaseBucketWidth"]; ok {
+			if v, ok := value.(string); ok {
+				if d, err := time.ParseDuration(v); err == nil {
+					leaseBucketWidth = d
+				} else {
+					logger.Errorf("Impossible to parse leaseBucketWidth %q: %v", v, err)
+				}
+			}
+		}
+	}
+
 	if etcdCfg.URL != "" {
 		etcdConfiguration.Endpoints = strings.Split(etcdCfg.URL, ",")
 	} else {
@@ -54,6 +75,22 @@ func Factory(etcdCfg core.CacheProvider, logger core.Logger, stale time.Duration
 		}
 	}
 
+	if tlsConfig, err := core.ParseTLSBlock(etcdCfg.Configuration); err != nil {
+		return nil, err
+	} else if tlsConfig != nil {
+		etcdConfiguration.TLS = tlsConfig
+	}
+
+	if tuning, ok := core.ParseConnectionTuning(etcdCfg.Configuration); ok {
+		if tuning.DialTimeout > 0 {
+			etcdConfiguration.DialTimeout = tuning.DialTimeout
+		}
+
+		if tuning.HealthCheckInterval > 0 {
+			etcdConfiguration.DialKeepAliveTime = tuning.HealthCheckInterval
+		}
+	}
+
 	cli, err := clientv3.New(etcdConfiguration)
 	if err != nil {
 		logger.Error("Impossible to initialize the Etcd DB.", err)
@@ -68,14 +105,56 @@ func Factory(etcdCfg core.CacheProvider, logger core.Logger, stale time.Duration
 	}
 
 	return &Etcd{
-		Client:        cli,
-		ctx:           context.Background(),
-		stale:         stale,
-		logger:        logger,
-		configuration: etcdConfiguration,
+		Client:           cli,
+		ctx:              context.Background(),
+		stale:            stale,
+		logger:           logger,
+		configuration:    etcdConfiguration,
+		leaseBucketWidth: leaseBucketWidth,
+		leases:           map[int64]clientv3.LeaseID{},
 	}, nil
 }
 
+// leaseForDuration returns a lease covering at least the given duration,
+// sharing one lease per expiry bucket to keep the lease count bounded on
+// high-cardinality caches.
+func (provider *Etcd) leaseForDuration(duration time.Duration) (clientv3.LeaseID, error) {
+	if provider.leaseBucketWidth <= 0 {
+		rs, err := provider.Client.Grant(context.TODO(), int64(duration.Seconds()))
+		if err != nil {
+			return 0, err
+		}
+
+		return rs.ID, nil
+	}
+
+	now := time.Now()
+	bucket := now.Add(duration).Truncate(provider.leaseBucketWidth).Add(provider.leaseBucketWidth)
+
+	provider.leasesMu.Lock()
+	defer provider.leasesMu.Unlock()
+
+	// Forget the buckets whose leases already expired.
+	for expiry := range provider.leases {
+		if expiry <= now.Unix() {
+			delete(provider.leases, expiry)
+		}
+	}
+
+	if id, ok := provider.leases[bucket.Unix()]; ok {
+		return id, nil
+	}
+
+	rs, err := provider.Client.Grant(context.TODO(), int64(time.Until(bucket).Seconds())+1)
+	if err != nil {
+		return 0, err
+	}
+
+	provider.leases[bucket.Unix()] = rs.ID
+
+	return rs.ID, nil
+}
+
 // Name returns the storer name.
 func (provider *Etcd) Name() string {
 	return "ETCD"
@@ -225,9 +304,9 @@ func (provider *Etcd) SetMultiLevel(baseKey, variedKey string, value []byte, var
 		return err
 	}
 
-	rs, err := provider.Client.Grant(context.TODO(), int64(duration.Seconds()))
+	leaseID, err := provider.leaseForDuration(duration)
 	if err == nil {
-		_, err = provider.Client.Put(provider.ctx, variedKey, compressed.String(), clientv3.WithLease(rs.ID))
+		_, err = provider.Client.Put(provider.ctx, variedKey, compressed.String(), clientv3.WithLease(leaseID))
 	}
 
 	if err != nil {
@@ -242,7 +321,7 @@ func (provider *Etcd) SetMultiLevel(baseKey, variedKey string, value []byte, var
 
 	mappingKey := core.MappingKeyPrefix + baseKey
 	result := provider.Get(mappingKey)
-	val, e := core.MappingUpdater(variedKey, result, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	val, e := core.MappingUpdaterWithLastModified(variedKey, result, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, core.ResponseLastModified(value), realKey)
 
 	if e != nil {
 		return e
@@ -263,9 +342,9 @@ func (provider *Etcd) Set(key string, value []byte, duration time.Duration) erro
 		return fmt.Errorf("the connection is not ready: %v", provider.Client.ActiveConnection().GetState())
 	}
 
-	rs, err := provider.Client.Grant(context.TODO(), int64(duration.Seconds()))
+	leaseID, err := provider.leaseForDuration(duration)
 	if err == nil {
-		_, err = provider.Client.Put(provider.ctx, key, string(value), clientv3.WithLease(rs.ID))
+		_, err = provider.Client.Put(provider.ctx, key, string(value), clientv3.WithLease(leaseID))
 	}
 
 	if err != nil {
@@ -312,6 +391,8 @@ func (provider *Etcd) DeleteMany(key string) {
 			}
 		}
 	}
+
+	core.CleanMappings(provider, rgKey.MatchString, provider.logger)
 }
 
 // Init method will.
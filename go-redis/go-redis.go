@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"regexp"
 	"strings"
@@ -73,10 +74,54 @@ func Factory(redisConfiguration core.CacheProvider, logger core.Logger, stale ti
 		}
 	}
 
+	if tlsConfig, err := core.ParseTLSBlock(redisConfiguration.Configuration); err != nil {
+		return nil, err
+	} else if tlsConfig != nil {
+		options.TLSConfig = tlsConfig
+	}
+
+	if tuning, ok := core.ParseConnectionTuning(redisConfiguration.Configuration); ok {
+		if tuning.PoolSize > 0 {
+			options.PoolSize = tuning.PoolSize
+		}
+
+		if tuning.MinIdleConns > 0 {
+			options.MinIdleConns = tuning.MinIdleConns
+		}
+
+		if tuning.DialTimeout > 0 {
+			options.DialTimeout = tuning.DialTimeout
+		}
+
+		if tuning.ReadTimeout > 0 {
+			options.ReadTimeout = tuning.ReadTimeout
+		}
+
+		if tuning.WriteTimeout > 0 {
+			options.WriteTimeout = tuning.WriteTimeout
+		}
+	}
+
 	if len(options.Addrs) == 0 {
 		return nil, errors.New("no redis addresses given.")
 	}
 
+	// Accept unix:///path.sock addresses for local sidecar deployments.
+	unixAddresses := false
+
+	for i, address := range options.Addrs {
+		if path, found := strings.CutPrefix(address, "unix://"); found {
+			options.Addrs[i] = path
+			unixAddresses = true
+		}
+	}
+
+	if unixAddresses {
+		options.Dialer = func(ctx context.Context, _, address string) (net.Conn, error) {
+			return (&net.Dialer{Timeout: options.DialTimeout}).DialContext(ctx, "unix", address)
+		}
+	}
+
 	if options.ClientName == "" {
 		options.ClientName = "souin-redis"
 	}
@@ -144,7 +189,7 @@ func (provider *Redis) MapKeys(prefix string) map[string]string {
 	mapKeys := map[string]string{}
 	keys := []string{}
 
-	iter := provider.inClient.Scan(provider.ctx, 0, prefix+"*", 0).Iterator()
+	iter := provider.inClient.Scan(provider.ctx, 0, provider.hashtags+prefix+"*", 0).Iterator()
 	for iter.Next(provider.ctx) {
 		keys = append(keys, iter.Val())
 	}
@@ -159,13 +204,43 @@ func (provider *Redis) MapKeys(prefix string) map[string]string {
 	}
 
 	for idx, item := range keys {
-		k, _ := strings.CutPrefix(item, prefix)
+		k, _ := strings.CutPrefix(item, provider.hashtags+prefix)
 		mapKeys[k] = vals[idx].(string)
 	}
 
 	return mapKeys
 }
 
+// ListMappings returns the stored mapping entries keyed by the exact Redis
+// key, hash tag included, so core.CleanMappings rewrites the mappings through
+// the keys it scanned instead of reconstructing them without the hash tag.
+func (provider *Redis) ListMappings() map[string][]byte {
+	mappings := map[string][]byte{}
+	keys := []string{}
+
+	iter := provider.inClient.Scan(provider.ctx, 0, provider.hashtags+core.MappingKeyPrefix+"*", 0).Iterator()
+	for iter.Next(provider.ctx) {
+		keys = append(keys, iter.Val())
+	}
+
+	if err := iter.Err(); err != nil || len(keys) == 0 {
+		return mappings
+	}
+
+	vals, err := provider.inClient.MGet(provider.ctx, keys...).Result()
+	if err != nil {
+		return mappings
+	}
+
+	for idx, key := range keys {
+		if value, ok := vals[idx].(string); ok {
+			mappings[key] = []byte(value)
+		}
+	}
+
+	return mappings
+}
+
 // GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
 func (provider *Redis) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
 	b, e := provider.inClient.Get(provider.ctx, provider.hashtags+core.MappingKeyPrefix+key).Bytes()
@@ -202,7 +277,7 @@ func (provider *Redis) SetMultiLevel(baseKey, variedKey string, value []byte, va
 		return err
 	}
 
-	val, err := core.MappingUpdater(provider.hashtags+variedKey, result, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	val, err := core.MappingUpdaterWithLastModified(provider.hashtags+variedKey, result, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, core.ResponseLastModified(value), realKey)
 	if err != nil {
 		return err
 	}
@@ -236,6 +311,43 @@ func (provider *Redis) Get(key string) (item []byte) {
 	return
 }
 
+// GetMany reads several keys in a single pipelined round trip, used by the
+// mapping election to fetch every variant candidate at once. A pipeline of
+// GETs (rather than one MGET) stays valid in cluster mode when the keys do
+// not share a hash slot.
+func (provider *Redis) GetMany(keys []string) map[string][]byte {
+	results := map[string][]byte{}
+
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to get the redis keys while reconnecting.")
+
+		return results
+	}
+
+	pipe := provider.inClient.Pipeline()
+	commands := make([]*redis.StringCmd, len(keys))
+
+	for i, key := range keys {
+		commands[i] = pipe.Get(provider.ctx, key)
+	}
+
+	if _, err := pipe.Exec(provider.ctx); err != nil && !errors.Is(err, redis.Nil) {
+		if !provider.reconnecting {
+			go provider.Reconnect()
+		}
+
+		return results
+	}
+
+	for i, command := range commands {
+		if value, err := command.Bytes(); err == nil {
+			results[keys[i]] = value
+		}
+	}
+
+	return results
+}
+
 // Prefix method returns the keys that match the prefix key.
 func (provider *Redis) Prefix(key string) []string {
 	// keys, _ := provider.inClient.Do(provider.ctx, provider.inClient.B().Keys().Pattern(key+"*").Build()).AsStrSlice()
@@ -308,6 +420,8 @@ func (provider *Redis) DeleteMany(key string) {
 	}
 
 	provider.inClient.Del(provider.ctx, keys...)
+
+	core.CleanMappings(provider, rgKey.MatchString, provider.logger)
 }
 
 // Init method will.
@@ -0,0 +1,437 @@
+package memcached
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// errCacheMiss is returned when the key does not exist on the server.
+	errCacheMiss = errors.New("memcached: cache miss")
+	// errCasConflict is returned when a compare-and-swap write lost the
+	// race against another writer.
+	errCasConflict = errors.New("memcached: cas conflict")
+	// errNotStored is returned when an add-mode write found the key
+	// already present.
+	errNotStored = errors.New("memcached: not stored")
+	// errProtocol is returned on an unexpected server answer.
+	errProtocol = errors.New("memcached: protocol error")
+)
+
+const (
+	// maxKeyLength is the protocol limit on key bytes.
+	maxKeyLength = 250
+	// defaultPoolSize bounds the pooled connections per server; one
+	// connection per request collapses under the concurrency Caddy
+	// generates.
+	defaultPoolSize = 8
+	// defaultIdleTimeout closes pooled connections idle for longer.
+	defaultIdleTimeout = time.Minute
+	// dialTimeout bounds the connection establishment.
+	dialTimeout = 5 * time.Second
+	// operationTimeout bounds a single round-trip on the wire.
+	operationTimeout = 10 * time.Second
+)
+
+// metaItem is a value read through mg, carrying the CAS token and the
+// remaining TTL when requested.
+type metaItem struct {
+	value []byte
+	cas   uint64
+	ttl   int64
+}
+
+// pooledConn is one connection with its buffered reader/writer and the time
+// it was returned to the pool.
+type pooledConn struct {
+	conn     net.Conn
+	rw       *bufio.ReadWriter
+	returned time.Time
+}
+
+// connPool is a bounded free-list of connections to one server.
+type connPool struct {
+	address     string
+	free        chan *pooledConn
+	idleTimeout time.Duration
+}
+
+// newConnPool creates the pool for one server address.
+func newConnPool(address string, size int, idleTimeout time.Duration) *connPool {
+	return &connPool{address: address, free: make(chan *pooledConn, size), idleTimeout: idleTimeout}
+}
+
+// get returns a pooled connection, dialing a new one when the free-list is
+// empty or the pooled connection sat idle for too long.
+func (pool *connPool) get() (*pooledConn, error) {
+	for {
+		select {
+		case pooled := <-pool.free:
+			if time.Since(pooled.returned) > pool.idleTimeout {
+				_ = pooled.conn.Close()
+
+				continue
+			}
+
+			return pooled, nil
+		default:
+			conn, err := net.DialTimeout("tcp", pool.address, dialTimeout)
+			if err != nil {
+				return nil, err
+			}
+
+			return &pooledConn{conn: conn, rw: bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))}, nil
+		}
+	}
+}
+
+// put returns the connection to the free-list, closing it when the pool is
+// already full.
+func (pool *connPool) put(pooled *pooledConn) {
+	pooled.returned = time.Now()
+
+	select {
+	case pool.free <- pooled:
+	default:
+		_ = pooled.conn.Close()
+	}
+}
+
+// discard closes a connection whose stream state is no longer trustworthy.
+func (pool *connPool) discard(pooled *pooledConn) {
+	_ = pooled.conn.Close()
+}
+
+// metaClient shards keys over the configured servers and speaks the meta
+// protocol (mg/ms/md) with them.
+type metaClient struct {
+	pools []*connPool
+}
+
+// newMetaClient creates the pools for the given server addresses.
+func newMetaClient(addresses []string, poolSize int, idleTimeout time.Duration) *metaClient {
+	pools := make([]*connPool, 0, len(addresses))
+	for _, address := range addresses {
+		pools = append(pools, newConnPool(address, poolSize, idleTimeout))
+	}
+
+	return &metaClient{pools: pools}
+}
+
+// poolFor picks the server holding the key, FNV-sharded over the servers.
+func (client *metaClient) poolFor(key string) *connPool {
+	if len(client.pools) == 1 {
+		return client.pools[0]
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key))
+
+	return client.pools[hash.Sum32()%uint32(len(client.pools))]
+}
+
+// encodeKey returns the key bytes to send and whether the b (base64) flag is
+// required. Keys with unsafe bytes ride base64-encoded; keys still too long
+// after encoding are rejected by wireKey before reaching here.
+func encodeKey(key string) (string, bool) {
+	safe := len(key) <= maxKeyLength
+	for i := 0; safe && i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			safe = false
+		}
+	}
+
+	if safe {
+		return key, false
+	}
+
+	return base64.StdEncoding.EncodeToString([]byte(key)), true
+}
+
+// roundTrip sends one request line (plus an optional data block) and returns
+// the response line.
+func (client *metaClient) roundTrip(pool *connPool, line string, data []byte) (string, []byte, error) {
+	pooled, err := pool.get()
+	if err != nil {
+		return "", nil, err
+	}
+
+	_ = pooled.conn.SetDeadline(time.Now().Add(operationTimeout))
+
+	if _, err := pooled.rw.WriteString(line + "\r\n"); err != nil {
+		pool.discard(pooled)
+
+		return "", nil, err
+	}
+
+	if data != nil {
+		if _, err := pooled.rw.Write(append(data, '\r', '\n')); err != nil {
+			pool.discard(pooled)
+
+			return "", nil, err
+		}
+	}
+
+	if err := pooled.rw.Flush(); err != nil {
+		pool.discard(pooled)
+
+		return "", nil, err
+	}
+
+	response, err := pooled.rw.ReadString('\n')
+	if err != nil {
+		pool.discard(pooled)
+
+		return "", nil, err
+	}
+
+	response = strings.TrimRight(response, "\r\n")
+
+	var payload []byte
+
+	if strings.HasPrefix(response, "VA ") {
+		fields := strings.Fields(response)
+		if len(fields) < 2 {
+			pool.discard(pooled)
+
+			return "", nil, errProtocol
+		}
+
+		size, err := strconv.Atoi(fields[1])
+		if err != nil {
+			pool.discard(pooled)
+
+			return "", nil, errProtocol
+		}
+
+		payload = make([]byte, size+2)
+		if _, err := io.ReadFull(pooled.rw.Reader, payload); err != nil {
+			pool.discard(pooled)
+
+			return "", nil, err
+		}
+
+		payload = payload[:size]
+	}
+
+	pool.put(pooled)
+
+	return response, payload, nil
+}
+
+// metaFlags extracts the returned flags (everything after the status and the
+// size) from a response line.
+func metaFlags(response string) []string {
+	fields := strings.Fields(response)
+	if len(fields) < 2 {
+		return nil
+	}
+
+	if fields[0] == "VA" {
+		return fields[2:]
+	}
+
+	return fields[1:]
+}
+
+// get reads the key, with its CAS token and remaining TTL.
+func (client *metaClient) get(key string) (*metaItem, error) {
+	wire, encoded := encodeKey(key)
+	if len(wire) > maxKeyLength {
+		return nil, errCacheMiss
+	}
+
+	line := "mg " + wire + " v c t"
+	if encoded {
+		line += " b"
+	}
+
+	pool := client.poolFor(key)
+
+	response, payload, err := client.roundTrip(pool, line, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case strings.HasPrefix(response, "VA "):
+		item := &metaItem{value: payload}
+
+		for _, flag := range metaFlags(response) {
+			if len(flag) < 2 {
+				continue
+			}
+
+			switch flag[0] {
+			case 'c':
+				item.cas, _ = strconv.ParseUint(flag[1:], 10, 64)
+			case 't':
+				item.ttl, _ = strconv.ParseInt(flag[1:], 10, 64)
+			}
+		}
+
+		return item, nil
+	case strings.HasPrefix(response, "EN"):
+		return nil, errCacheMiss
+	default:
+		return nil, fmt.Errorf("%w: %s", errProtocol, response)
+	}
+}
+
+// set writes the key. A zero casToken writes unconditionally; addOnly makes
+// the write fail with errNotStored when the key already exists; a non-zero
+// casToken makes it fail with errCasConflict when the token moved.
+func (client *metaClient) set(key string, value []byte, ttl int64, casToken uint64, addOnly bool) error {
+	wire, encoded := encodeKey(key)
+	if len(wire) > maxKeyLength {
+		return fmt.Errorf("memcached: the key %s does not fit the %d bytes limit once encoded", url.QueryEscape(key), maxKeyLength)
+	}
+
+	line := fmt.Sprintf("ms %s %d T%d", wire, len(value), ttl)
+	if encoded {
+		line += " b"
+	}
+
+	if casToken != 0 {
+		line += " C" + strconv.FormatUint(casToken, 10)
+	}
+
+	if addOnly {
+		line += " ME"
+	}
+
+	pool := client.poolFor(key)
+
+	response, _, err := client.roundTrip(pool, line, value)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case strings.HasPrefix(response, "HD"):
+		return nil
+	case strings.HasPrefix(response, "EX"):
+		return errCasConflict
+	case strings.HasPrefix(response, "NS"):
+		return errNotStored
+	case strings.HasPrefix(response, "NF"):
+		return errCasConflict
+	default:
+		return fmt.Errorf("%w: %s", errProtocol, response)
+	}
+}
+
+// delete removes the key; a miss is not an error.
+func (client *metaClient) delete(key string) error {
+	wire, encoded := encodeKey(key)
+	if len(wire) > maxKeyLength {
+		return nil
+	}
+
+	line := "md " + wire
+	if encoded {
+		line += " b"
+	}
+
+	pool := client.poolFor(key)
+
+	response, _, err := client.roundTrip(pool, line, nil)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(response, "HD") || strings.HasPrefix(response, "NF") {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", errProtocol, response)
+}
+
+// keys walks every server with lru_crawler metadump and streams the raw key
+// of each live entry to the callback.
+func (client *metaClient) keys(callback func(key string)) error {
+	for _, pool := range client.pools {
+		if err := client.keysFrom(pool, callback); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// keysFrom dumps one server. The metadump lines URL-encode the raw key, so
+// arbitrary key bytes survive the trip.
+func (client *metaClient) keysFrom(pool *connPool, callback func(key string)) error {
+	pooled, err := pool.get()
+	if err != nil {
+		return err
+	}
+
+	_ = pooled.conn.SetDeadline(time.Now().Add(operationTimeout))
+
+	if _, err := pooled.rw.WriteString("lru_crawler metadump all\r\n"); err != nil {
+		pool.discard(pooled)
+
+		return err
+	}
+
+	if err := pooled.rw.Flush(); err != nil {
+		pool.discard(pooled)
+
+		return err
+	}
+
+	for {
+		line, err := pooled.rw.ReadString('\n')
+		if err != nil {
+			pool.discard(pooled)
+
+			return err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "END" {
+			break
+		}
+
+		if strings.HasPrefix(line, "ERROR") || strings.HasPrefix(line, "CLIENT_ERROR") || strings.HasPrefix(line, "BUSY") {
+			pool.discard(pooled)
+
+			return fmt.Errorf("%w: %s", errProtocol, line)
+		}
+
+		for _, field := range strings.Fields(line) {
+			if value, found := strings.CutPrefix(field, "key="); found {
+				if key, err := url.QueryUnescape(value); err == nil {
+					callback(key)
+				}
+
+				break
+			}
+		}
+	}
+
+	pool.put(pooled)
+
+	return nil
+}
+
+// flushAll empties every server.
+func (client *metaClient) flushAll() error {
+	for _, pool := range client.pools {
+		if _, _, err := client.roundTrip(pool, "flush_all", nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+module github.com/darkweak/storages/memcached
+
+go 1.22.1
+
+replace github.com/darkweak/storages/core => ../core
+
+require (
+	github.com/darkweak/storages/core v0.0.13
+	github.com/pierrec/lz4/v4 v4.1.22
+	go.uber.org/zap v1.27.0
+)
+
+require (
+	github.com/WJQSERVER/souin-storages/core v0.1.0 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	google.golang.org/protobuf v1.36.5 // indirect
+)
@@ -0,0 +1,298 @@
+package memcached
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	lz4 "github.com/pierrec/lz4/v4"
+)
+
+// casRetries bounds the optimistic retries of the mapping update when
+// another writer moved the CAS token between the read and the write.
+const casRetries = 16
+
+// Memcached provider type.
+type Memcached struct {
+	client *metaClient
+	stale  time.Duration
+	logger core.Logger
+	uuid   string
+}
+
+// Factory function create new Memcached instance.
+func Factory(memcachedConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	servers := []string{}
+	if memcachedConfiguration.URL != "" {
+		servers = strings.Split(memcachedConfiguration.URL, ",")
+	}
+
+	poolSize := defaultPoolSize
+	idleTimeout := defaultIdleTimeout
+
+	if memcachedConfig, ok := memcachedConfiguration.Configuration.(map[string]interface{}); ok && memcachedConfig != nil {
+		if value, ok := memcachedConfig["servers"]; ok {
+			switch serverValue := value.(type) {
+			case string:
+				servers = strings.Split(serverValue, ",")
+			case []interface{}:
+				servers = []string{}
+
+				for _, item := range serverValue {
+					if address, ok := item.(string); ok {
+						servers = append(servers, address)
+					}
+				}
+			}
+		}
+
+		if value, ok := memcachedConfig["pool_size"]; ok {
+			switch v := value.(type) {
+			case int:
+				poolSize = v
+			case float64:
+				poolSize = int(v)
+			}
+		}
+
+		if value, ok := memcachedConfig["idle_timeout"]; ok {
+			if durationValue, ok := value.(string); ok {
+				duration, err := time.ParseDuration(durationValue)
+				if err != nil {
+					logger.Errorf("Impossible to parse the idle_timeout value %v: %v", value, err)
+				} else {
+					idleTimeout = duration
+				}
+			}
+		}
+	}
+
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("impossible to create the Memcached client: no server given")
+	}
+
+	for i := range servers {
+		servers[i] = strings.TrimSpace(servers[i])
+	}
+
+	return &Memcached{
+		client: newMetaClient(servers, poolSize, idleTimeout),
+		stale:  stale,
+		logger: logger,
+		uuid:   fmt.Sprintf("%s-%s", strings.Join(servers, ","), stale),
+	}, nil
+}
+
+// Name returns the storer name.
+func (provider *Memcached) Name() string {
+	return "MEMCACHED"
+}
+
+// Uuid returns an unique identifier.
+func (provider *Memcached) Uuid() string {
+	return provider.uuid
+}
+
+// ttlSeconds converts a duration to the whole seconds the T flag carries,
+// rounding sub-second TTLs up so they do not become immortal zero.
+func ttlSeconds(duration time.Duration) int64 {
+	if duration <= 0 {
+		return 0
+	}
+
+	seconds := int64(duration / time.Second)
+	if duration%time.Second != 0 {
+		seconds++
+	}
+
+	return seconds
+}
+
+// ListKeys method returns the list of existing keys.
+func (provider *Memcached) ListKeys() []string {
+	keys := []string{}
+
+	err := provider.client.keys(func(key string) {
+		if !strings.HasPrefix(key, core.MappingKeyPrefix) {
+			return
+		}
+
+		item, err := provider.client.get(key)
+		if err != nil {
+			return
+		}
+
+		mapping, err := core.DecodeMapping(item.value)
+		if err == nil {
+			for _, v := range mapping.GetMapping() {
+				keys = append(keys, v.GetRealKey())
+			}
+		}
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to list the Memcached keys, %v", err)
+	}
+
+	return keys
+}
+
+// MapKeys method returns the map of existing keys.
+func (provider *Memcached) MapKeys(prefix string) map[string]string {
+	keys := map[string]string{}
+
+	err := provider.client.keys(func(key string) {
+		if !strings.HasPrefix(key, prefix) {
+			return
+		}
+
+		item, err := provider.client.get(key)
+		if err != nil {
+			return
+		}
+
+		k, _ := strings.CutPrefix(key, prefix)
+		keys[k] = string(item.value)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to map the Memcached keys, %v", err)
+	}
+
+	return keys
+}
+
+// Get method returns the populated response if exists, empty response then.
+func (provider *Memcached) Get(key string) []byte {
+	item, err := provider.client.get(key)
+	if err != nil {
+		return nil
+	}
+
+	return item.value
+}
+
+// Set method will store the response in Memcached provider.
+func (provider *Memcached) Set(key string, value []byte, duration time.Duration) error {
+	err := provider.client.set(key, value, ttlSeconds(duration), 0, false)
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s into Memcached, %v", key, err)
+	}
+
+	return err
+}
+
+// Delete method will delete the response in Memcached provider if exists
+// corresponding to key param.
+func (provider *Memcached) Delete(key string) {
+	_ = provider.client.delete(key)
+}
+
+// DeleteMany method will delete the responses in Memcached provider if exists
+// corresponding to the regex key param.
+func (provider *Memcached) DeleteMany(key string) {
+	rgKey, e := regexp.Compile(key)
+	if e != nil {
+		return
+	}
+
+	matching := []string{}
+
+	_ = provider.client.keys(func(storedKey string) {
+		if rgKey.MatchString(storedKey) {
+			matching = append(matching, storedKey)
+		}
+	})
+
+	for _, storedKey := range matching {
+		provider.Delete(storedKey)
+	}
+
+	core.CleanMappings(provider, rgKey.MatchString, provider.logger)
+}
+
+// Init method will.
+func (provider *Memcached) Init() error {
+	return nil
+}
+
+// Reset method will reset or close provider.
+func (provider *Memcached) Reset() error {
+	return provider.client.flushAll()
+}
+
+// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
+func (provider *Memcached) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	value := provider.Get(core.MappingKeyPrefix + key)
+	if len(value) == 0 {
+		return fresh, stale
+	}
+
+	fresh, stale, _ = core.MappingElection(provider, value, req, validator, provider.logger)
+
+	return fresh, stale
+}
+
+// SetMultiLevel tries to store the key with the given value and update the
+// mapping key to store metadata. The mapping update rides the CAS token from
+// the read, so two concurrent writers never lose each other's variant — the
+// read-modify-write race the non-transactional providers tolerate.
+func (provider *Memcached) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	now := time.Now()
+
+	compressed := new(bytes.Buffer)
+	if _, err := lz4.NewWriter(compressed).ReadFrom(bytes.NewReader(value)); err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into Memcached, %v", variedKey, err)
+
+		return err
+	}
+
+	if err := provider.Set(variedKey, compressed.Bytes(), duration+provider.stale); err != nil {
+		return err
+	}
+
+	mappingKey := core.MappingKeyPrefix + baseKey
+
+	for attempt := 0; attempt < casRetries; attempt++ {
+		var (
+			item []byte
+			cas  uint64
+		)
+
+		existing, err := provider.client.get(mappingKey)
+
+		switch {
+		case err == nil:
+			item = existing.value
+			cas = existing.cas
+		case errors.Is(err, errCacheMiss):
+		default:
+			return err
+		}
+
+		val, err := core.MappingUpdaterWithLastModified(variedKey, item, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, core.ResponseLastModified(value), realKey)
+		if err != nil {
+			return err
+		}
+
+		err = provider.client.set(mappingKey, val, 0, cas, cas == 0)
+		if err == nil {
+			return nil
+		}
+
+		if errors.Is(err, errCasConflict) || errors.Is(err, errNotStored) {
+			continue
+		}
+
+		provider.logger.Errorf("Impossible to set value into Memcached, %v", err)
+
+		return err
+	}
+
+	return fmt.Errorf("impossible to update the mapping %s after %d CAS attempts", mappingKey, casRetries)
+}
+
+var _ core.Storer = (*Memcached)(nil)
@@ -0,0 +1,142 @@
+package memcached_test
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/darkweak/storages/memcached"
+	"go.uber.org/zap"
+)
+
+const (
+	byteKey        = "MyByteKey"
+	nonExistentKey = "NonExistentKey"
+	baseValue      = "My first data"
+	serverAddress  = "localhost:11211"
+)
+
+// getMemcachedInstance connects to the memcached instance from
+// compose.test.yml, skipping the test when no server is reachable.
+func getMemcachedInstance(t *testing.T) core.Storer {
+	t.Helper()
+
+	conn, err := net.DialTimeout("tcp", serverAddress, time.Second)
+	if err != nil {
+		t.Skipf("No memcached server available: %v", err)
+	}
+
+	_ = conn.Close()
+
+	instance, err := memcached.Factory(core.CacheProvider{URL: serverAddress}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("The factory should not fail: %v", err)
+	}
+
+	return instance
+}
+
+func TestMemcachedConnectionFactory(t *testing.T) {
+	instance := getMemcachedInstance(t)
+
+	if nil == instance {
+		t.Error("Memcached should be instanciated")
+	}
+}
+
+func TestMemcachedConnectionFactory_NoServer(t *testing.T) {
+	if _, err := memcached.Factory(core.CacheProvider{}, zap.NewNop().Sugar(), 0); err == nil {
+		t.Error("The factory should fail without a server")
+	}
+}
+
+func TestIShouldBeAbleToReadAndWriteDataInMemcached(t *testing.T) {
+	client := getMemcachedInstance(t)
+
+	_ = client.Set("Test", []byte(baseValue), time.Duration(20)*time.Second)
+
+	res := client.Get("Test")
+	if len(res) == 0 {
+		t.Errorf("Key %s should exist", baseValue)
+	}
+
+	if baseValue != string(res) {
+		t.Errorf("%s not corresponding to %s", string(res), baseValue)
+	}
+}
+
+func TestMemcached_GetRequestInCache(t *testing.T) {
+	client := getMemcachedInstance(t)
+	res := client.Get(nonExistentKey)
+
+	if 0 < len(res) {
+		t.Errorf("Key %s should not exist", nonExistentKey)
+	}
+}
+
+func TestMemcached_LongKeyRoundTrip(t *testing.T) {
+	client := getMemcachedInstance(t)
+
+	// Spaces and a length above 250 bytes both force the base64 b-flag
+	// encoding on the wire.
+	key := "GET-https-example.com-/a key with spaces/"
+	for len(key) < 300 {
+		key += "x"
+	}
+
+	_ = client.Set(key, []byte(baseValue), time.Duration(20)*time.Second)
+
+	if res := client.Get(key); string(res) != baseValue {
+		t.Errorf("Key %s should round-trip through the base64 encoding", key)
+	}
+
+	client.Delete(key)
+}
+
+func TestMemcached_DeleteRequestInCache(t *testing.T) {
+	client := getMemcachedInstance(t)
+	_ = client.Set(byteKey, []byte("A"), time.Duration(20)*time.Second)
+
+	client.Delete(byteKey)
+
+	if res := client.Get(byteKey); 0 < len(res) {
+		t.Errorf("Key %s should not exist", byteKey)
+	}
+}
+
+func TestMemcached_ExpiredKeyIsNotServed(t *testing.T) {
+	client := getMemcachedInstance(t)
+	_ = client.Set("ExpiredKey", []byte(baseValue), time.Millisecond)
+	time.Sleep(1100 * time.Millisecond)
+
+	if res := client.Get("ExpiredKey"); 0 < len(res) {
+		t.Error("Key ExpiredKey should have expired")
+	}
+}
+
+const maxCounter = 10
+
+func TestMemcached_MapKeys(t *testing.T) {
+	client := getMemcachedInstance(t)
+	prefix := "MAP_KEYS_PREFIX_"
+
+	for i := 0; i < maxCounter; i++ {
+		_ = client.Set(fmt.Sprintf("%s%d", prefix, i), []byte(fmt.Sprintf("Hello from %d", i)), time.Minute)
+	}
+
+	keys := client.MapKeys(prefix)
+
+	if len(keys) != maxCounter {
+		t.Errorf("The map should contain %d elements, %d given", maxCounter, len(keys))
+	}
+
+	for k, v := range keys {
+		if v != fmt.Sprintf("Hello from %s", k) {
+			t.Errorf("Expected Hello from %s, %s given", k, v)
+		}
+	}
+
+	client.DeleteMany(prefix + ".*")
+}
@@ -107,10 +107,59 @@ func sanitizeProperties(configMap map[string]interface{}) map[string]interface{}
 	return configMap
 }
 
+// parseBucketConfig reads the JetStream bucket tuning keys (replication
+// factor, storage type, history depth, bucket TTL) so operators can choose
+// durability vs speed for the backing key-value store.
+func parseBucketConfig(configMap map[string]interface{}, logger core.Logger) nats.KeyValueConfig {
+	bucketConfig := nats.KeyValueConfig{}
+
+	if v := configMap["replicas"]; v != nil {
+		switch replicas := v.(type) {
+		case int:
+			bucketConfig.Replicas = replicas
+		case float64:
+			bucketConfig.Replicas = int(replicas)
+		}
+	}
+
+	if v := configMap["storage"]; v != nil {
+		switch v {
+		case "memory":
+			bucketConfig.Storage = nats.MemoryStorage
+		case "file", "":
+			bucketConfig.Storage = nats.FileStorage
+		default:
+			logger.Errorf("Unknown storage type %v for the Nats bucket, expected file or memory", v)
+		}
+	}
+
+	if v := configMap["history"]; v != nil {
+		switch history := v.(type) {
+		case int:
+			bucketConfig.History = uint8(history)
+		case float64:
+			bucketConfig.History = uint8(history)
+		}
+	}
+
+	if v := configMap["ttl"]; v != nil {
+		if s, ok := v.(string); ok {
+			if d, err := time.ParseDuration(s); err == nil {
+				bucketConfig.TTL = d
+			} else {
+				logger.Errorf("Impossible to parse the Nats bucket ttl %q: %v", s, err)
+			}
+		}
+	}
+
+	return bucketConfig
+}
+
 // Factory function create new Nats instance.
 func Factory(natsConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
 	natsOptions := nats.GetDefaultOptions()
 	bucketName := "souin-bucket"
+	bucketConfig := nats.KeyValueConfig{}
 
 	if natsConfiguration.Configuration != nil {
 		var parsedNats nats.Options
@@ -119,6 +168,8 @@ func Factory(natsConfiguration core.CacheProvider, logger core.Logger, stale tim
 			bucketName, _ = bucket.(string)
 		}
 
+		bucketConfig = parseBucketConfig(natsConfiguration.Configuration.(map[string]interface{}), logger)
+
 		natsConfiguration.Configuration = sanitizeProperties(natsConfiguration.Configuration.(map[string]interface{}))
 		if b, e := json.Marshal(natsConfiguration.Configuration); e == nil {
 			if e = json.Unmarshal(b, &parsedNats); e != nil {
@@ -133,6 +184,26 @@ func Factory(natsConfiguration core.CacheProvider, logger core.Logger, stale tim
 		natsOptions.Servers = strings.Split(natsConfiguration.URL, ",")
 	}
 
+	if tlsConfig, err := core.ParseTLSBlock(natsConfiguration.Configuration); err != nil {
+		return nil, err
+	} else if tlsConfig != nil {
+		natsOptions.TLSConfig = tlsConfig
+	}
+
+	if tuning, ok := core.ParseConnectionTuning(natsConfiguration.Configuration); ok {
+		if tuning.DialTimeout > 0 {
+			natsOptions.Timeout = tuning.DialTimeout
+		}
+
+		if tuning.WriteTimeout > 0 {
+			natsOptions.FlusherTimeout = tuning.WriteTimeout
+		}
+
+		if tuning.HealthCheckInterval > 0 {
+			natsOptions.PingInterval = tuning.HealthCheckInterval
+		}
+	}
+
 	if len(natsOptions.Servers) == 0 {
 		natsOptions.Servers = []string{nats.DefaultURL}
 	}
@@ -151,9 +222,9 @@ func Factory(natsConfiguration core.CacheProvider, logger core.Logger, stale tim
 		return nil, err
 	}
 
-	_, err = stream.CreateKeyValue(&nats.KeyValueConfig{
-		Bucket: bucketName,
-	})
+	bucketConfig.Bucket = bucketName
+
+	_, err = stream.CreateKeyValue(&bucketConfig)
 	if err != nil {
 		logger.Error("Impossible to create the Nats bucket %s.", err, bucketName)
 
@@ -300,7 +371,7 @@ func (provider *Nats) SetMultiLevel(baseKey, variedKey string, value []byte, var
 	mappingKey := core.MappingKeyPrefix + baseKey
 	r := provider.Get(mappingKey)
 
-	val, err := core.MappingUpdater(variedKey, r, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	val, err := core.MappingUpdaterWithLastModified(variedKey, r, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, core.ResponseLastModified(value), realKey)
 	if err != nil {
 		provider.logger.Errorf("Impossible to update the mapping key %s in Nats: %v", mappingKey, err)
 
@@ -359,6 +430,8 @@ func (provider *Nats) DeleteMany(key string) {
 			_ = keyvalue.Purge(key)
 		}
 	}
+
+	core.CleanMappings(provider, rgKey.MatchString, provider.logger)
 }
 
 // Init method will.
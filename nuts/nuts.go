@@ -283,7 +283,7 @@ func (provider *Nuts) SetMultiLevel(baseKey, variedKey string, value []byte, var
 			val = item
 		}
 
-		val, err = core.MappingUpdater(variedKey, val, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+		val, err = core.MappingUpdaterWithLastModified(variedKey, val, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, core.ResponseLastModified(value), realKey)
 		if err != nil {
 			return err
 		}
@@ -344,6 +344,8 @@ func (provider *Nuts) DeleteMany(key string) {
 
 		return nil
 	})
+
+	core.CleanMappings(provider, rgKey.MatchString, provider.logger)
 }
 
 // Init method will.
@@ -0,0 +1,146 @@
+package olric
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/buraksezer/olric"
+	"github.com/buraksezer/olric/config"
+	"github.com/darkweak/storages/core"
+)
+
+// startEmbedded starts an in-process Olric member and returns the address the
+// cluster client should dial. Discovery, partitioning and replication are
+// driven by the configuration map:
+//
+//	mode: embedded
+//	environment: local|lan|wan (default lan)
+//	bindAddr / bindPort: olric protocol listener (default 0.0.0.0:3320)
+//	memberlistPort: memberlist gossip port (default 3322)
+//	peers: static seed peers, host[:port]
+//	discovery: static|dns — dns resolves every peer name at startup, which
+//	  covers Kubernetes headless services out of the box
+//	partitionCount / replicaCount: cluster sizing knobs
+func startEmbedded(olricCfg map[string]interface{}, logger core.Logger) (string, error) {
+	environment := "lan"
+	if v, ok := olricCfg["environment"].(string); ok && v != "" {
+		environment = v
+	}
+
+	c := config.New(environment)
+
+	if v, ok := olricCfg["bindAddr"].(string); ok && v != "" {
+		c.BindAddr = v
+	}
+
+	if port, ok := configInt(olricCfg["bindPort"]); ok {
+		c.BindPort = port
+	}
+
+	if port, ok := configInt(olricCfg["memberlistPort"]); ok {
+		c.MemberlistConfig.BindPort = port
+	}
+
+	if count, ok := configInt(olricCfg["partitionCount"]); ok {
+		c.PartitionCount = uint64(count)
+	}
+
+	if count, ok := configInt(olricCfg["replicaCount"]); ok {
+		c.ReplicaCount = count
+	}
+
+	peers := configStrings(olricCfg["peers"])
+	if discovery, _ := olricCfg["discovery"].(string); discovery == "dns" {
+		peers = resolvePeers(peers, logger)
+	}
+
+	c.Peers = peers
+
+	started := make(chan struct{})
+	c.Started = func() {
+		close(started)
+	}
+
+	db, err := olric.New(c)
+	if err != nil {
+		return "", err
+	}
+
+	go func() {
+		if err := db.Start(); err != nil {
+			logger.Errorf("The embedded Olric member stopped: %v", err)
+		}
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(30 * time.Second):
+		return "", errors.New("the embedded olric member did not start in time")
+	}
+
+	return fmt.Sprintf("%s:%d", c.BindAddr, c.BindPort), nil
+}
+
+// configInt reads an integer configuration value regardless of the decoder
+// that produced it.
+func configInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case float64:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
+
+// configStrings reads a string list configuration value.
+func configStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		peers := make([]string, 0, len(v))
+
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				peers = append(peers, s)
+			}
+		}
+
+		return peers
+	default:
+		return nil
+	}
+}
+
+// resolvePeers expands every peer name into the addresses it resolves to,
+// keeping the port, so a single DNS name (e.g. a Kubernetes headless service)
+// seeds the whole memberlist.
+func resolvePeers(peers []string, logger core.Logger) []string {
+	resolved := []string{}
+
+	for _, peer := range peers {
+		host, port, err := net.SplitHostPort(peer)
+		if err != nil {
+			host, port = peer, "3322"
+		}
+
+		addresses, err := net.LookupHost(host)
+		if err != nil {
+			logger.Errorf("Impossible to resolve the olric peer %s: %v", host, err)
+
+			resolved = append(resolved, net.JoinHostPort(host, port))
+
+			continue
+		}
+
+		for _, address := range addresses {
+			resolved = append(resolved, net.JoinHostPort(address, port))
+		}
+	}
+
+	return resolved
+}
@@ -29,7 +29,24 @@ type Olric struct {
 
 // Factory function create new Olric instance.
 func Factory(olricConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
-	client, err := olric.NewClusterClient(strings.Split(olricConfiguration.URL, ","))
+	addresses := strings.Split(olricConfiguration.URL, ",")
+
+	// In embedded mode the provider starts its own cluster member and
+	// connects to it, instead of requiring an external olricd.
+	if olricCfg, ok := olricConfiguration.Configuration.(map[string]interface{}); ok && olricCfg != nil {
+		if mode, _ := olricCfg["mode"].(string); mode == "embedded" {
+			address, err := startEmbedded(olricCfg, logger)
+			if err != nil {
+				logger.Errorf("Impossible to start the embedded Olric member, %v", err)
+
+				return nil, err
+			}
+
+			addresses = []string{address}
+		}
+	}
+
+	client, err := olric.NewClusterClient(addresses)
 	if err != nil {
 		logger.Errorf("Impossible to connect to Olric, %v", err)
 	}
@@ -40,7 +57,7 @@ func Factory(olricConfiguration core.CacheProvider, logger core.Logger, stale ti
 		stale:         stale,
 		logger:        logger,
 		configuration: config.Client{},
-		addresses:     strings.Split(olricConfiguration.URL, ","),
+		addresses:     addresses,
 	}, nil
 }
 
@@ -181,7 +198,7 @@ func (provider *Olric) SetMultiLevel(baseKey, variedKey string, value []byte, va
 		return err
 	}
 
-	val, err = core.MappingUpdater(variedKey, val, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	val, err = core.MappingUpdaterWithLastModified(variedKey, val, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, core.ResponseLastModified(value), realKey)
 	if err != nil {
 		return err
 	}
@@ -286,6 +303,13 @@ func (provider *Olric) DeleteMany(key string) {
 	records.Close()
 
 	_, _ = dmap.Delete(context.Background(), keys...)
+
+	deleted := make(map[string]bool, len(keys))
+	for _, deletedKey := range keys {
+		deleted[deletedKey] = true
+	}
+
+	core.CleanMappings(provider, func(variedKey string) bool { return deleted[variedKey] }, provider.logger)
 }
 
 // Init method will initialize Olric provider if needed.
@@ -148,7 +148,7 @@ func (provider *Otter) SetMultiLevel(baseKey, variedKey string, value []byte, va
 	mappingKey := core.MappingKeyPrefix + baseKey
 	item, _ := provider.cache.Get(mappingKey)
 
-	val, e := core.MappingUpdater(variedKey, item, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	val, e := core.MappingUpdaterWithLastModified(variedKey, item, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, core.ResponseLastModified(value), realKey)
 	if e != nil {
 		return e
 	}
@@ -195,6 +195,8 @@ func (provider *Otter) DeleteMany(key string) {
 	provider.cache.DeleteByFunc(func(k string, value []byte) bool {
 		return rgKey.MatchString(k)
 	})
+
+	core.CleanMappings(provider, rgKey.MatchString, provider.logger)
 }
 
 // Init method will.
@@ -3,9 +3,11 @@ package redis
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -57,6 +59,30 @@ func Factory(redisConfiguration core.CacheProvider, logger core.Logger, stale ti
 		}
 	}
 
+	if tlsConfig, err := core.ParseTLSBlock(redisConfiguration.Configuration); err != nil {
+		return nil, err
+	} else if tlsConfig != nil {
+		options.TLSConfig = tlsConfig
+	}
+
+	if tuning, ok := core.ParseConnectionTuning(redisConfiguration.Configuration); ok {
+		if tuning.PoolSize > 0 {
+			options.BlockingPoolSize = tuning.PoolSize
+		}
+
+		if tuning.DialTimeout > 0 {
+			options.Dialer.Timeout = tuning.DialTimeout
+		}
+
+		if tuning.WriteTimeout > 0 {
+			options.ConnWriteTimeout = tuning.WriteTimeout
+		}
+
+		if tuning.HealthCheckInterval > 0 {
+			options.Dialer.KeepAlive = tuning.HealthCheckInterval
+		}
+	}
+
 	if options.Dialer.Timeout == 0 {
 		options.Dialer.Timeout = time.Second
 	}
@@ -65,6 +91,26 @@ func Factory(redisConfiguration core.CacheProvider, logger core.Logger, stale ti
 		return nil, errors.New("no redis addresses given.")
 	}
 
+	// Accept unix:///path.sock addresses for local sidecar deployments.
+	unixAddresses := false
+
+	for i, address := range options.InitAddress {
+		if path, found := strings.CutPrefix(address, "unix://"); found {
+			options.InitAddress[i] = path
+			unixAddresses = true
+		}
+	}
+
+	if unixAddresses {
+		options.DialFn = func(address string, dialer *net.Dialer, tlsConfig *tls.Config) (net.Conn, error) {
+			if tlsConfig != nil {
+				return tls.DialWithDialer(dialer, "unix", address, tlsConfig)
+			}
+
+			return dialer.Dial("unix", address)
+		}
+	}
+
 	cli, err := redis.NewClient(options)
 	if err != nil {
 		return nil, err
@@ -98,12 +144,36 @@ func (provider *Redis) Uuid() string {
 	)
 }
 
+// getMany returns the values for the given keys in a single round trip,
+// skipping missing keys.
+func (provider *Redis) getMany(keys []string) map[string][]byte {
+	values := make(map[string][]byte, len(keys))
+	if len(keys) == 0 {
+		return values
+	}
+
+	cmds := make([]redis.Completed, 0, len(keys))
+	for _, key := range keys {
+		cmds = append(cmds, provider.inClient.B().Get().Key(key).Build())
+	}
+
+	for i, result := range provider.inClient.DoMulti(provider.ctx, cmds...) {
+		if b, err := result.AsBytes(); err == nil {
+			values[keys[i]] = b
+		}
+	}
+
+	return values
+}
+
 // ListKeys method returns the list of existing keys.
 func (provider *Redis) ListKeys() []string {
 	var scan redis.ScanEntry
 
 	var err error
 
+	mappingKeys := []string{}
+
 	elements := []string{}
 
 	provider.logger.Debugf("Call the ListKeys function in redis")
@@ -113,21 +183,21 @@ func (provider *Redis) ListKeys() []string {
 			provider.logger.Errorf("Cannot scan: %v", err)
 		}
 
-		for _, element := range scan.Elements {
-			value := provider.Get(element)
+		mappingKeys = append(mappingKeys, scan.Elements...)
+	}
 
-			mapping, err := core.DecodeMapping(value)
-			if err != nil {
+	for _, value := range provider.getMany(mappingKeys) {
+		mapping, err := core.DecodeMapping(value)
+		if err != nil {
+			continue
+		}
+
+		for _, v := range mapping.GetMapping() {
+			if v.GetFreshTime().AsTime().Before(time.Now()) && v.GetStaleTime().AsTime().Before(time.Now()) {
 				continue
 			}
 
-			for _, v := range mapping.GetMapping() {
-				if v.GetFreshTime().AsTime().Before(time.Now()) && v.GetStaleTime().AsTime().Before(time.Now()) {
-					continue
-				}
-
-				elements = append(elements, v.GetRealKey())
-			}
+			elements = append(elements, v.GetRealKey())
 		}
 	}
 
@@ -146,21 +216,58 @@ func (provider *Redis) MapKeys(prefix string) map[string]string {
 	provider.logger.Debugf("Call the MapKeys in redis with the prefix %s", prefix)
 
 	for more := true; more; more = scan.Cursor != 0 {
-		if scan, err = provider.inClient.Do(context.Background(), provider.inClient.B().Scan().Cursor(scan.Cursor).Match(prefix+"*").Build()).AsScanEntry(); err != nil {
+		if scan, err = provider.inClient.Do(context.Background(), provider.inClient.B().Scan().Cursor(scan.Cursor).Match(provider.hashtags+prefix+"*").Build()).AsScanEntry(); err != nil {
 			provider.logger.Errorf("Cannot scan: %v", err)
 		}
 
 		elements = append(elements, scan.Elements...)
 	}
 
-	for _, key := range elements {
-		k, _ := strings.CutPrefix(key, prefix)
-		kvStore[k] = string(provider.Get(key))
+	for key, value := range provider.getMany(elements) {
+		k, _ := strings.CutPrefix(key, provider.hashtags+prefix)
+		kvStore[k] = string(value)
 	}
 
 	return kvStore
 }
 
+// ListMappings returns the stored mapping entries keyed by the exact Redis
+// key, hash tag included, so core.CleanMappings rewrites the mappings through
+// the keys it scanned instead of reconstructing them without the hash tag.
+func (provider *Redis) ListMappings() map[string][]byte {
+	var scan redis.ScanEntry
+
+	var err error
+
+	mappingKeys := []string{}
+
+	for more := true; more; more = scan.Cursor != 0 {
+		if scan, err = provider.inClient.Do(context.Background(), provider.inClient.B().Scan().Cursor(scan.Cursor).Match(provider.hashtags+core.MappingKeyPrefix+"*").Build()).AsScanEntry(); err != nil {
+			provider.logger.Errorf("Cannot scan: %v", err)
+		}
+
+		mappingKeys = append(mappingKeys, scan.Elements...)
+	}
+
+	return provider.getMany(mappingKeys)
+}
+
+// prefetchedRedis serves Get from an already batched round trip and falls
+// back to the client for anything else.
+type prefetchedRedis struct {
+	*Redis
+	values map[string][]byte
+}
+
+// Get method returns the populated response if exists, empty response then.
+func (provider *prefetchedRedis) Get(key string) []byte {
+	if value, ok := provider.values[key]; ok {
+		return value
+	}
+
+	return provider.Redis.Get(key)
+}
+
 // GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
 func (provider *Redis) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
 	b, e := provider.inClient.Do(provider.ctx, provider.inClient.B().Get().Key(provider.hashtags+core.MappingKeyPrefix+key).Build()).AsBytes()
@@ -168,7 +275,19 @@ func (provider *Redis) GetMultiLevel(key string, req *http.Request, validator *c
 		return
 	}
 
-	fresh, stale, _ = core.MappingElection(provider, b, req, validator, provider.logger)
+	// Fetch every candidate body in a single pipelined round trip instead of
+	// paying one GET per variant during the election.
+	candidates := []string{}
+
+	if mapping, err := core.DecodeMapping(b); err == nil {
+		for keyName, keyItem := range mapping.GetMapping() {
+			if keyItem.GetStaleTime().AsTime().After(time.Now()) {
+				candidates = append(candidates, keyName)
+			}
+		}
+	}
+
+	fresh, stale, _ = core.MappingElection(&prefetchedRedis{Redis: provider, values: provider.getMany(candidates)}, b, req, validator, provider.logger)
 
 	return
 }
@@ -184,20 +303,27 @@ func (provider *Redis) SetMultiLevel(baseKey, variedKey string, value []byte, va
 		return err
 	}
 
-	if err := provider.inClient.Do(provider.ctx, provider.inClient.B().Set().Key(provider.hashtags+variedKey).Value(compressed.String()).Ex(duration+provider.stale).Build()).Error(); err != nil {
+	mappingKey := provider.hashtags + core.MappingKeyPrefix + baseKey
+
+	// Pipeline the body write and the mapping read into one round trip.
+	results := provider.inClient.DoMulti(
+		provider.ctx,
+		provider.inClient.B().Set().Key(provider.hashtags+variedKey).Value(compressed.String()).Ex(duration+provider.stale).Build(),
+		provider.inClient.B().Get().Key(mappingKey).Build(),
+	)
+
+	if err := results[0].Error(); err != nil {
 		provider.logger.Errorf("Impossible to set value into Redis, %v", err)
 
 		return err
 	}
 
-	mappingKey := provider.hashtags + core.MappingKeyPrefix + baseKey
-
-	v, err := provider.inClient.Do(provider.ctx, provider.inClient.B().Get().Key(mappingKey).Build()).AsBytes()
+	v, err := results[1].AsBytes()
 	if err != nil && !errors.Is(err, redis.Nil) {
 		return err
 	}
 
-	val, err := core.MappingUpdater(provider.hashtags+variedKey, v, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	val, err := core.MappingUpdaterWithLastModified(provider.hashtags+variedKey, v, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, core.ResponseLastModified(value), realKey)
 	if err != nil {
 		return err
 	}
@@ -260,6 +386,13 @@ func (provider *Redis) DeleteMany(key string) {
 	}
 
 	_ = provider.inClient.Do(provider.ctx, provider.inClient.B().Del().Key(elements...).Build())
+
+	deleted := make(map[string]bool, len(elements))
+	for _, deletedKey := range elements {
+		deleted[deletedKey] = true
+	}
+
+	core.CleanMappings(provider, func(variedKey string) bool { return deleted[variedKey] }, provider.logger)
 }
 
 // Init method will.
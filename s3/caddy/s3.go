@@ -0,0 +1,55 @@
+package caddy
+
+import (
+	"net/http"
+
+	caddy "github.com/caddyserver/caddy/v2"
+	"github.com/caddyserver/caddy/v2/modules/caddyhttp"
+	"github.com/darkweak/storages/core"
+	"github.com/darkweak/storages/s3"
+)
+
+const moduleName = "s3"
+
+// S3 storage.
+type S3 struct {
+	// Keep the handler configuration.
+	core.Configuration
+}
+
+//nolint:gochecknoinits
+func init() {
+	caddy.RegisterModule(S3{})
+}
+
+// CaddyModule returns the Caddy module information.
+func (S3) CaddyModule() caddy.ModuleInfo {
+	return caddy.ModuleInfo{
+		ID:  "storages.cache.s3",
+		New: func() caddy.Module { return new(S3) },
+	}
+}
+
+// Provision to do the provisioning part.
+func (b *S3) Provision(ctx caddy.Context) error {
+	logger := ctx.Logger(b)
+	storer, err := s3.Factory(b.Configuration.Provider, logger.Sugar(), b.Configuration.Stale)
+
+	if err != nil {
+		return err
+	}
+
+	core.RegisterStorage(storer)
+
+	return nil
+}
+
+func (b *S3) ServeHTTP(rw http.ResponseWriter, rq *http.Request, next caddyhttp.Handler) error {
+	return next.ServeHTTP(rw, rq)
+}
+
+// Interface guards.
+var (
+	_ caddy.Provisioner           = (*S3)(nil)
+	_ caddyhttp.MiddlewareHandler = (*S3)(nil)
+)
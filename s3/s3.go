@@ -0,0 +1,465 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/dustin/go-humanize"
+	minio "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	lz4 "github.com/pierrec/lz4/v4"
+)
+
+const (
+	// expiresMetadataKey carries the expires-at unix nanoseconds in the
+	// object user metadata; zero or absent means no expiry.
+	expiresMetadataKey = "Souin-Expires-At"
+	// defaultMultipartThreshold is the body size above which uploads go
+	// through the multipart API and downloads through ranged GETs.
+	defaultMultipartThreshold = 64 << 20
+	// defaultPartSize is the multipart part and download range size.
+	defaultPartSize = 16 << 20
+	// defaultUploadConcurrency bounds the concurrent part uploads.
+	defaultUploadConcurrency = 4
+	// rangeRetries is how many times a single download range is retried
+	// before the whole read fails; a transient error only re-fetches one
+	// range, never the whole object.
+	rangeRetries = 3
+)
+
+// S3 provider type.
+type S3 struct {
+	client             *minio.Client
+	bucket             string
+	stale              time.Duration
+	ctx                context.Context
+	logger             core.Logger
+	uuid               string
+	multipartThreshold int64
+	partSize           int64
+	uploadConcurrency  uint
+}
+
+// parseSizeOption reads a byte size from the configuration map, accepting the
+// int/float64/string shapes the other providers accept (see simplefs.Factory).
+func parseSizeOption(config map[string]interface{}, name string, fallback int64, logger core.Logger) int64 {
+	value, ok := config[name]
+	if !ok {
+		return fallback
+	}
+
+	switch sizeValue := value.(type) {
+	case int:
+		return int64(sizeValue)
+	case int64:
+		return sizeValue
+	case float64:
+		return int64(sizeValue)
+	case string:
+		size, err := humanize.ParseBytes(sizeValue)
+		if err != nil {
+			logger.Errorf("Impossible to parse the %s value %v: %v", name, value, err)
+
+			return fallback
+		}
+
+		return int64(size) //nolint:gosec
+	default:
+		return fallback
+	}
+}
+
+// parseStringOption reads a string from the configuration map.
+func parseStringOption(config map[string]interface{}, name, fallback string) string {
+	if value, ok := config[name]; ok {
+		if v, ok := value.(string); ok {
+			return v
+		}
+	}
+
+	return fallback
+}
+
+// Factory function create new S3 instance.
+func Factory(s3Configuration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	endpoint := s3Configuration.URL
+	bucket := "souin"
+	region := ""
+	accessKey := ""
+	secretKey := ""
+	secure := false
+	multipartThreshold := int64(defaultMultipartThreshold)
+	partSize := int64(defaultPartSize)
+	uploadConcurrency := defaultUploadConcurrency
+
+	if s3Config, ok := s3Configuration.Configuration.(map[string]interface{}); ok && s3Config != nil {
+		endpoint = parseStringOption(s3Config, "endpoint", endpoint)
+		bucket = parseStringOption(s3Config, "bucket", bucket)
+		region = parseStringOption(s3Config, "region", region)
+		accessKey = parseStringOption(s3Config, "access_key", accessKey)
+		secretKey = parseStringOption(s3Config, "secret_key", secretKey)
+
+		if value, ok := s3Config["secure"]; ok {
+			if v, ok := value.(bool); ok {
+				secure = v
+			}
+		}
+
+		multipartThreshold = parseSizeOption(s3Config, "multipart_threshold", multipartThreshold, logger)
+		partSize = parseSizeOption(s3Config, "part_size", partSize, logger)
+
+		if value, ok := s3Config["upload_concurrency"]; ok {
+			switch v := value.(type) {
+			case int:
+				uploadConcurrency = v
+			case float64:
+				uploadConcurrency = int(v)
+			}
+		}
+	}
+
+	if endpoint == "" {
+		return nil, fmt.Errorf("impossible to create the S3 client: no endpoint given")
+	}
+
+	// Static credentials when configured, the usual environment and IAM
+	// chain otherwise.
+	var creds *credentials.Credentials
+	if accessKey != "" {
+		creds = credentials.NewStaticV4(accessKey, secretKey, "")
+	} else {
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.EnvMinio{},
+			&credentials.IAM{},
+		})
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{Creds: creds, Secure: secure, Region: region})
+	if err != nil {
+		logger.Error("Impossible to create the S3 client.", err)
+
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	exists, err := client.BucketExists(ctx, bucket)
+	if err != nil {
+		logger.Error("Impossible to reach the S3 bucket.", err)
+
+		return nil, err
+	}
+
+	if !exists {
+		if err := client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{Region: region}); err != nil {
+			logger.Error("Impossible to create the S3 bucket.", err)
+
+			return nil, err
+		}
+	}
+
+	return &S3{
+		client:             client,
+		bucket:             bucket,
+		stale:              stale,
+		ctx:                ctx,
+		logger:             logger,
+		uuid:               fmt.Sprintf("%s-%s-%s", endpoint, bucket, stale),
+		multipartThreshold: multipartThreshold,
+		partSize:           partSize,
+		uploadConcurrency:  uint(uploadConcurrency), //nolint:gosec
+	}, nil
+}
+
+// Name returns the storer name.
+func (provider *S3) Name() string {
+	return "S3"
+}
+
+// Uuid returns an unique identifier.
+func (provider *S3) Uuid() string {
+	return provider.uuid
+}
+
+// expiresAt extracts the expiry deadline from the object metadata, zero when
+// the object never expires.
+func expiresAt(info minio.ObjectInfo) time.Time {
+	value := info.UserMetadata[expiresMetadataKey]
+	if value == "" {
+		value = info.Metadata.Get("X-Amz-Meta-" + expiresMetadataKey)
+	}
+
+	if value == "" {
+		return time.Time{}
+	}
+
+	nanos, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || nanos == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, nanos)
+}
+
+// alive reports whether the object is still inside its TTL, removing it
+// lazily when expired.
+func (provider *S3) alive(key string, info minio.ObjectInfo) bool {
+	deadline := expiresAt(info)
+	if deadline.IsZero() || time.Now().Before(deadline) {
+		return true
+	}
+
+	_ = provider.client.RemoveObject(provider.ctx, provider.bucket, key, minio.RemoveObjectOptions{})
+
+	return false
+}
+
+// readRanged downloads the object in partSize ranges so a transient error
+// only re-fetches one range instead of the whole multi-GB body.
+func (provider *S3) readRanged(key string, size int64) []byte {
+	content := make([]byte, size)
+
+	for offset := int64(0); offset < size; offset += provider.partSize {
+		end := offset + provider.partSize - 1
+		if end >= size {
+			end = size - 1
+		}
+
+		var lastErr error
+
+		for attempt := 0; attempt < rangeRetries; attempt++ {
+			options := minio.GetObjectOptions{}
+			if err := options.SetRange(offset, end); err != nil {
+				return nil
+			}
+
+			object, err := provider.client.GetObject(provider.ctx, provider.bucket, key, options)
+			if err != nil {
+				lastErr = err
+
+				continue
+			}
+
+			_, err = io.ReadFull(object, content[offset:end+1])
+			_ = object.Close()
+
+			if err == nil {
+				lastErr = nil
+
+				break
+			}
+
+			lastErr = err
+		}
+
+		if lastErr != nil {
+			provider.logger.Errorf("Impossible to download the range %d-%d of the key %s from S3, %v", offset, end, key, lastErr)
+
+			return nil
+		}
+	}
+
+	return content
+}
+
+// Get method returns the populated response if exists, empty response then.
+func (provider *S3) Get(key string) []byte {
+	info, err := provider.client.StatObject(provider.ctx, provider.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil
+	}
+
+	if !provider.alive(key, info) {
+		return nil
+	}
+
+	if info.Size >= provider.multipartThreshold {
+		return provider.readRanged(key, info.Size)
+	}
+
+	object, err := provider.client.GetObject(provider.ctx, provider.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil
+	}
+
+	defer object.Close()
+
+	content, err := io.ReadAll(object)
+	if err != nil {
+		return nil
+	}
+
+	return content
+}
+
+// Set method will store the response in S3 provider.
+func (provider *S3) Set(key string, value []byte, duration time.Duration) error {
+	metadata := map[string]string{}
+	if duration > 0 {
+		metadata[expiresMetadataKey] = strconv.FormatInt(time.Now().Add(duration).UnixNano(), 10)
+	}
+
+	options := minio.PutObjectOptions{
+		UserMetadata: metadata,
+		ContentType:  "application/octet-stream",
+	}
+
+	multipart := int64(len(value)) >= provider.multipartThreshold
+	if multipart {
+		// Multipart upload with bounded concurrency; the client retries
+		// each part independently so one failed part never re-uploads the
+		// whole object.
+		options.PartSize = uint64(provider.partSize) //nolint:gosec
+		options.NumThreads = provider.uploadConcurrency
+	} else {
+		options.DisableMultipart = true
+	}
+
+	_, err := provider.client.PutObject(provider.ctx, provider.bucket, key, bytes.NewReader(value), int64(len(value)), options)
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s into S3, %v", key, err)
+
+		if multipart {
+			// Abort the pending upload so failed writes never accumulate
+			// billable parts.
+			_ = provider.client.RemoveIncompleteUpload(provider.ctx, provider.bucket, key)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Delete method will delete the response in S3 provider if exists
+// corresponding to key param.
+func (provider *S3) Delete(key string) {
+	_ = provider.client.RemoveObject(provider.ctx, provider.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// DeleteMany method will delete the responses in S3 provider if exists
+// corresponding to the regex key param.
+func (provider *S3) DeleteMany(key string) {
+	rgKey, e := regexp.Compile(key)
+	if e != nil {
+		return
+	}
+
+	for object := range provider.client.ListObjects(provider.ctx, provider.bucket, minio.ListObjectsOptions{Recursive: true}) {
+		if object.Err != nil {
+			return
+		}
+
+		if rgKey.MatchString(object.Key) {
+			provider.Delete(object.Key)
+		}
+	}
+
+	core.CleanMappings(provider, rgKey.MatchString, provider.logger)
+}
+
+// Init method will.
+func (provider *S3) Init() error {
+	return nil
+}
+
+// ListKeys method returns the list of existing keys.
+func (provider *S3) ListKeys() []string {
+	keys := []string{}
+
+	for object := range provider.client.ListObjects(provider.ctx, provider.bucket, minio.ListObjectsOptions{Prefix: core.MappingKeyPrefix, Recursive: true}) {
+		if object.Err != nil {
+			return keys
+		}
+
+		mapping, err := core.DecodeMapping(provider.Get(object.Key))
+		if err == nil {
+			for _, v := range mapping.GetMapping() {
+				keys = append(keys, v.GetRealKey())
+			}
+		}
+	}
+
+	return keys
+}
+
+// MapKeys method returns the map of existing keys.
+func (provider *S3) MapKeys(prefix string) map[string]string {
+	keys := map[string]string{}
+
+	for object := range provider.client.ListObjects(provider.ctx, provider.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return keys
+		}
+
+		if value := provider.Get(object.Key); len(value) != 0 {
+			k, _ := strings.CutPrefix(object.Key, prefix)
+			keys[k] = string(value)
+		}
+	}
+
+	return keys
+}
+
+// Reset method will reset or close provider.
+func (provider *S3) Reset() error {
+	for object := range provider.client.ListObjects(provider.ctx, provider.bucket, minio.ListObjectsOptions{Recursive: true}) {
+		if object.Err != nil {
+			return object.Err
+		}
+
+		provider.Delete(object.Key)
+	}
+
+	return nil
+}
+
+// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
+func (provider *S3) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	value := provider.Get(core.MappingKeyPrefix + key)
+	if len(value) == 0 {
+		return fresh, stale
+	}
+
+	fresh, stale, _ = core.MappingElection(provider, value, req, validator, provider.logger)
+
+	return fresh, stale
+}
+
+// SetMultiLevel tries to store the key with the given value and update the
+// mapping key to store metadata.
+func (provider *S3) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	now := time.Now()
+
+	compressed := new(bytes.Buffer)
+	if _, err := lz4.NewWriter(compressed).ReadFrom(bytes.NewReader(value)); err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into S3, %v", variedKey, err)
+
+		return err
+	}
+
+	if err := provider.Set(variedKey, compressed.Bytes(), duration+provider.stale); err != nil {
+		return err
+	}
+
+	mappingKey := core.MappingKeyPrefix + baseKey
+	item := provider.Get(mappingKey)
+
+	val, err := core.MappingUpdaterWithLastModified(variedKey, item, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, core.ResponseLastModified(value), realKey)
+	if err != nil {
+		return err
+	}
+
+	return provider.Set(mappingKey, val, 0)
+}
+
+var _ core.Storer = (*S3)(nil)
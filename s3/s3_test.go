@@ -0,0 +1,176 @@
+package s3_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/darkweak/storages/s3"
+	"go.uber.org/zap"
+)
+
+const (
+	byteKey        = "MyByteKey"
+	nonExistentKey = "NonExistentKey"
+	baseValue      = "My first data"
+)
+
+// getS3Instance connects to the MinIO instance from compose.test.yml,
+// skipping the test when no object store is reachable.
+func getS3Instance(t *testing.T) core.Storer {
+	t.Helper()
+
+	instance, err := s3.Factory(core.CacheProvider{
+		URL: "localhost:9000",
+		Configuration: map[string]interface{}{
+			"bucket":     "souin-test",
+			"access_key": "minioadmin",
+			"secret_key": "minioadmin",
+		},
+	}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Skipf("No S3 server available: %v", err)
+	}
+
+	return instance
+}
+
+func TestS3ConnectionFactory(t *testing.T) {
+	instance := getS3Instance(t)
+
+	if nil == instance {
+		t.Error("S3 should be instanciated")
+	}
+}
+
+func TestIShouldBeAbleToReadAndWriteDataInS3(t *testing.T) {
+	client := getS3Instance(t)
+
+	_ = client.Set("Test", []byte(baseValue), time.Duration(20)*time.Second)
+
+	res := client.Get("Test")
+	if len(res) == 0 {
+		t.Errorf("Key %s should exist", baseValue)
+	}
+
+	if baseValue != string(res) {
+		t.Errorf("%s not corresponding to %s", string(res), baseValue)
+	}
+}
+
+func TestS3_GetRequestInCache(t *testing.T) {
+	client := getS3Instance(t)
+	res := client.Get(nonExistentKey)
+
+	if 0 < len(res) {
+		t.Errorf("Key %s should not exist", nonExistentKey)
+	}
+}
+
+func TestS3_SetRequestInCache_TTL(t *testing.T) {
+	key := "MyEmptyKey"
+	client := getS3Instance(t)
+	val := []byte("Hello world")
+	_ = client.Set(key, val, time.Duration(20)*time.Second)
+
+	newValue := client.Get(key)
+
+	if len(newValue) != len(val) {
+		t.Errorf("Key %s should be equals to %s, %s provided", key, val, newValue)
+	}
+}
+
+func TestS3_SetRequestInCache_Expired(t *testing.T) {
+	key := "MyExpiredKey"
+	client := getS3Instance(t)
+	_ = client.Set(key, []byte("Hello world"), time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+
+	if 0 < len(client.Get(key)) {
+		t.Errorf("Key %s should be expired", key)
+	}
+}
+
+func TestS3_DeleteRequestInCache(t *testing.T) {
+	client := getS3Instance(t)
+	_ = client.Set(byteKey, []byte("A"), time.Duration(20)*time.Second)
+	client.Delete(byteKey)
+
+	if 0 < len(client.Get(byteKey)) {
+		t.Errorf("Key %s should not exist", byteKey)
+	}
+}
+
+func TestS3_MultipartRoundTrip(t *testing.T) {
+	client := getS3Instance(t)
+
+	// Large enough to cross the 5 MiB test threshold and exercise the
+	// multipart upload and the ranged download paths.
+	instance, err := s3.Factory(core.CacheProvider{
+		URL: "localhost:9000",
+		Configuration: map[string]interface{}{
+			"bucket":              "souin-test",
+			"access_key":          "minioadmin",
+			"secret_key":          "minioadmin",
+			"multipart_threshold": 6 << 20,
+			"part_size":           5 << 20,
+		},
+	}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Skipf("No S3 server available: %v", err)
+	}
+
+	client = instance
+
+	key := "MyMultipartKey"
+	payload := make([]byte, 12<<20)
+
+	for i := range payload {
+		payload[i] = byte(i % 251)
+	}
+
+	if err := client.Set(key, payload, time.Minute); err != nil {
+		t.Errorf("Impossible to upload the multipart key: %v", err)
+	}
+
+	read := client.Get(key)
+	if len(read) != len(payload) {
+		t.Errorf("Key %s should hold %d bytes, %d read", key, len(payload), len(read))
+	}
+
+	for i := range read {
+		if read[i] != payload[i] {
+			t.Errorf("Key %s content differs at offset %d", key, i)
+
+			break
+		}
+	}
+
+	client.Delete(key)
+}
+
+const maxCounter = 10
+
+func TestS3_MapKeys(t *testing.T) {
+	client := getS3Instance(t)
+	prefix := "MAP_KEYS_PREFIX_"
+
+	for i := 0; i < maxCounter; i++ {
+		_ = client.Set(fmt.Sprintf("%s%d", prefix, i), []byte(fmt.Sprintf("Hello from %d", i)), time.Minute)
+	}
+
+	keys := client.MapKeys(prefix)
+
+	if len(keys) != maxCounter {
+		t.Errorf("The map should contain %d elements, %d given", maxCounter, len(keys))
+	}
+
+	for k, v := range keys {
+		if v != fmt.Sprintf("Hello from %s", k) {
+			t.Errorf("Expected Hello from %s, %s given", k, v)
+		}
+	}
+
+	client.DeleteMany(prefix + ".*")
+}
@@ -0,0 +1,101 @@
+package simplefs
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"time"
+)
+
+// accessCheckpointFile is the file name of the access time checkpoint in the
+// storage directory.
+const accessCheckpointFile = ".access-checkpoint.json"
+
+// snapshot exports the currently recorded access times.
+func (tracker *accessTracker) snapshot() map[string]time.Time {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	result := make(map[string]time.Time, len(tracker.times))
+	for key, at := range tracker.times {
+		result[key] = at
+	}
+
+	return result
+}
+
+// restore merges the access times of the checkpoint, the existing (newer)
+// records win.
+func (tracker *accessTracker) restore(times map[string]time.Time) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	if tracker.times == nil {
+		tracker.times = map[string]time.Time{}
+	}
+
+	for key, at := range times {
+		if existing, found := tracker.times[key]; !found || at.After(existing) {
+			tracker.times[key] = at
+		}
+	}
+}
+
+// writeAccessCheckpoint atomically persists the access times (temporary file
+// then rename).
+func (provider *Simplefs) writeAccessCheckpoint() {
+	payload, err := json.Marshal(provider.access.snapshot())
+	if err != nil {
+		return
+	}
+
+	path := filepath.Join(provider.path, accessCheckpointFile)
+	tmpPath := path + ".tmp"
+
+	if err := provider.fs.WriteFile(tmpPath, payload, 0o644); err != nil {
+		provider.logger.Errorf("Impossible to write the access time checkpoint %s: %v", tmpPath, err)
+
+		return
+	}
+
+	if err := provider.fs.Rename(tmpPath, path); err != nil {
+		provider.logger.Errorf("Impossible to replace the access time checkpoint %s: %v", path, err)
+	}
+}
+
+// loadAccessCheckpoint restores the last persisted access times at startup,
+// so the eviction decisions after a restart never degrade to a random order.
+func (provider *Simplefs) loadAccessCheckpoint() {
+	path := filepath.Join(provider.path, accessCheckpointFile)
+
+	payload, err := provider.fs.ReadFile(path)
+	if err != nil {
+		return // no checkpoint file, skip
+	}
+
+	times := map[string]time.Time{}
+	if err := json.Unmarshal(payload, &times); err != nil {
+		provider.logger.Warnf("Impossible to parse the access time checkpoint %s: %v", path, err)
+
+		return
+	}
+
+	provider.access.restore(times)
+	provider.logger.Debugf("Restored %d access time records from the checkpoint", len(times))
+}
+
+// startAccessCheckpoint starts the periodic access time checkpoint loop, a
+// zero interval disables it.
+func (provider *Simplefs) startAccessCheckpoint(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			provider.writeAccessCheckpoint()
+		}
+	}()
+}
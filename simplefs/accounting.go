@@ -0,0 +1,125 @@
+package simplefs
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/jellydator/ttlcache/v3"
+)
+
+// Accounting invariant: actualSize is always the sum of every charge in
+// charges. Entries are charged synchronously on the insert path (block-aligned
+// actual file size after the write, in-memory values like mappings by their
+// value size), and the explicit delete paths discharge synchronously and
+// remove the backing data. The ttlcache event callbacks run asynchronously in
+// their own goroutines without ordering guarantees, so only the cache-internal
+// expiry and capacity evictions discharge through the callback. This keeps
+// actualSize from ever going negative and lets the synchronous eviction loop
+// observe the space it just freed.
+
+// chargeKey records the charged size of a key and adjusts actualSize by the
+// delta against the previous charge.
+func (provider *Simplefs) chargeKey(key string, usage int64) {
+	provider.mu.Lock()
+	previous := provider.charges[key]
+	provider.charges[key] = usage
+	provider.actualSize += usage - previous
+	newSize := provider.actualSize
+	provider.mu.Unlock()
+
+	provider.metrics.SetActualSize(newSize)
+}
+
+// dischargeKey clears the charge of a key and returns the freed charged size.
+// Calling it twice is harmless.
+func (provider *Simplefs) dischargeKey(key string) int64 {
+	provider.mu.Lock()
+
+	usage, found := provider.charges[key]
+	if found {
+		delete(provider.charges, key)
+		provider.actualSize -= usage
+	}
+
+	newSize := provider.actualSize
+	provider.mu.Unlock()
+
+	if found {
+		provider.metrics.SetActualSize(newSize)
+	}
+
+	return usage
+}
+
+// setEntryFile stores an entry file path in the cache and charges the actual
+// file size synchronously. The charge lands before the cache write, so any
+// eviction event triggered inside the cache already sees it.
+func (provider *Simplefs) setEntryFile(key, path string, ttl time.Duration) *ttlcache.Item[string, []byte] {
+	var usage int64
+
+	if info, err := provider.fs.Stat(path); err == nil {
+		usage = provider.diskUsage(info.Size())
+	} else {
+		provider.logger.Errorf("Impossible to get the file size %s: %#v", key, err)
+	}
+
+	provider.chargeKey(key, usage)
+
+	item := provider.cache.Set(key, []byte(path), ttl)
+
+	provider.checkCapacity() // alert when the capacity watermark is crossed
+
+	// Wake up the background sweep when the entry count crosses the high watermark.
+	if provider.overHighWatermark() {
+		provider.requestEviction()
+	}
+
+	return item
+}
+
+// setRawValue stores an in-memory value like a mapping in the cache and
+// charges its value size synchronously.
+func (provider *Simplefs) setRawValue(key string, value []byte, ttl time.Duration) *ttlcache.Item[string, []byte] {
+	provider.chargeKey(key, int64(len(value)))
+
+	return provider.cache.Set(key, value, ttl)
+}
+
+// discardKeyData discharges a key and removes its backing data: mapping keys
+// drop their persisted copy, entry keys remove the physical file. It returns
+// the freed charged bytes.
+func (provider *Simplefs) discardKeyData(key, path string) int64 {
+	freed := provider.dischargeKey(key)
+
+	if strings.Contains(key, core.MappingKeyPrefix) {
+		provider.removeMappingFile(key)
+
+		return freed
+	}
+
+	if path != "" {
+		if err := provider.fs.Remove(path); err != nil && !os.IsNotExist(err) {
+			provider.logger.Errorf("Impossible to remove the file %s: %#v", key, err)
+		}
+	}
+
+	return freed
+}
+
+// deleteKey synchronously removes a cache key with its charge and backing
+// data, returning the freed charged bytes. The matching eviction event
+// callback returns early on the Deleted reason, so nothing runs twice.
+func (provider *Simplefs) deleteKey(key string) int64 {
+	path := ""
+
+	if item := provider.cache.Get(key, ttlcache.WithDisableTouchOnHit[string, []byte]()); item != nil &&
+		!strings.Contains(key, core.MappingKeyPrefix) {
+		path = string(item.Value())
+	}
+
+	provider.cache.Delete(key)
+
+	return provider.discardKeyData(key, path)
+}
@@ -0,0 +1,279 @@
+package simplefs
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// adminPageTemplate is the minimal HTML template of the admin page.
+const adminPageTemplate = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Simplefs cache</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; font-size: 13px; }
+form { display: inline; }
+</style></head>
+<body>
+<h1>Simplefs cache</h1>
+<p>{{ .Count }} entries, {{ .ActualSize }} on disk</p>
+<form method="POST" action="purge">
+<input type="text" name="pattern" placeholder="regex pattern">
+<button type="submit">Purge matching</button>
+</form>
+<table>
+<tr><th>Key</th><th>Size</th><th>Expires</th><th>ETag</th><th></th></tr>
+{{ range .Entries }}
+<tr>
+<td>{{ .Key }}</td><td>{{ .Size }}</td><td>{{ .ExpiresAt }}</td><td>{{ .Etag }}</td>
+<td><form method="POST" action="purge"><input type="hidden" name="key" value="{{ .Key }}"><button type="submit">Purge</button></form></td>
+</tr>
+{{ end }}
+</table>
+</body>
+</html>`
+
+// adminEntry is the display data of a single row in the template.
+type adminEntry struct {
+	Key       string
+	Size      string
+	ExpiresAt string
+	Etag      string
+}
+
+// adminPage is the data of the admin page template.
+type adminPage struct {
+	Count      int
+	ActualSize string
+	Entries    []adminEntry
+}
+
+// adminStats is the aggregate returned by the stats endpoint.
+type adminStats struct {
+	Count       int                              `json:"count"`
+	ActualSize  int64                            `json:"actual_size"`
+	Latency     map[string]LatencySnapshot       `json:"latency"`
+	Scrub       ScrubStats                       `json:"scrub"`
+	Compression map[string]CompressionGroupStats `json:"compression,omitempty"`
+}
+
+// AdminHandler returns a mountable admin handler exposing the key listing,
+// size and TTL overview, a statistics endpoint and purge operations, so small
+// teams can inspect the cache without building their own panel. With
+// admin_read_token / admin_write_token configured, the read endpoints require
+// the read or write token and the mutating endpoints like purge only accept
+// the write token.
+func (provider *Simplefs) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", provider.requireScope(false, provider.adminIndex))
+	mux.HandleFunc("GET /stats", provider.requireScope(false, provider.adminStatsHandler))
+	mux.HandleFunc("GET /events", provider.requireScope(false, provider.adminEvents))
+	mux.HandleFunc("POST /purge", provider.requireScope(true, provider.adminPurge))
+	mux.HandleFunc("POST /grace", provider.requireScope(true, provider.adminGrace))
+
+	return mux
+}
+
+// requireScope checks the Bearer token. With write set only the write token
+// is accepted, otherwise either token works; with neither token configured
+// there is no check at all.
+func (provider *Simplefs) requireScope(write bool, next http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if provider.adminReadToken == "" && provider.adminWriteToken == "" {
+			next(rw, req)
+
+			return
+		}
+
+		token, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !found {
+			http.Error(rw, "missing Bearer token", http.StatusUnauthorized)
+
+			return
+		}
+
+		if tokenMatches(token, provider.adminWriteToken) || (!write && tokenMatches(token, provider.adminReadToken)) {
+			next(rw, req)
+
+			return
+		}
+
+		http.Error(rw, "invalid token or insufficient scope", http.StatusForbidden)
+	}
+}
+
+// tokenMatches compares tokens in constant time, an empty expected value never matches.
+func tokenMatches(token, expected string) bool {
+	return expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// adminIndex renders the entry listing page. The query parameters sort
+// (expiry/size/last_access), desc, expiring_within (e.g. 5m), min_size
+// (e.g. 10MB), limit and offset control sorting, filtering and pagination.
+func (provider *Simplefs) adminIndex(rw http.ResponseWriter, req *http.Request) {
+	page := adminPage{}
+
+	options := ListOptions{
+		SortBy: req.FormValue("sort"),
+		Desc:   req.FormValue("desc") == "true",
+	}
+
+	if within := req.FormValue("expiring_within"); within != "" {
+		options.ExpiringWithin, _ = time.ParseDuration(within)
+	}
+
+	if minSize := req.FormValue("min_size"); minSize != "" {
+		if parsed, err := humanize.ParseBytes(minSize); err == nil {
+			//nolint:gosec
+			options.MinSize = int64(parsed)
+		}
+	}
+
+	if limit := req.FormValue("limit"); limit != "" {
+		options.Limit, _ = strconv.Atoi(limit)
+	}
+
+	if offset := req.FormValue("offset"); offset != "" {
+		options.Offset, _ = strconv.Atoi(offset)
+	}
+
+	for _, meta := range provider.ListEntries(options) {
+		page.Entries = append(page.Entries, adminEntry{
+			Key:       meta.Key,
+			Size:      humanize.IBytes(uint64(meta.Size)),
+			ExpiresAt: meta.ExpiresAt.Format(time.RFC3339),
+			Etag:      meta.Etag,
+		})
+	}
+
+	page.Count = len(page.Entries)
+
+	provider.mu.Lock()
+	//nolint:gosec
+	page.ActualSize = humanize.IBytes(uint64(provider.actualSize))
+	provider.mu.Unlock()
+
+	tmpl, err := template.New("admin").Parse(adminPageTemplate)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = tmpl.Execute(rw, page)
+}
+
+// adminStatsHandler returns the cache statistics as JSON.
+func (provider *Simplefs) adminStatsHandler(rw http.ResponseWriter, _ *http.Request) {
+	provider.mu.Lock()
+	actualSize := provider.actualSize
+	provider.mu.Unlock()
+
+	stats := adminStats{
+		Count:       provider.cache.Len(),
+		ActualSize:  actualSize,
+		Latency:     provider.LatencyStats(),
+		Scrub:       provider.ScrubberStats(),
+		Compression: provider.CompressionStats(),
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(stats)
+}
+
+// adminEvents streams the cache events over SSE, unsubscribing automatically
+// when the connection drops.
+func (provider *Simplefs) adminEvents(rw http.ResponseWriter, req *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "the response writer does not support streaming", http.StatusInternalServerError)
+
+		return
+	}
+
+	events, cancel := provider.SubscribeEvents()
+	defer cancel()
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case event := <-events:
+			payload, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+
+			if _, err := fmt.Fprintf(rw, "data: %s\n\n", payload); err != nil {
+				return
+			}
+
+			flusher.Flush()
+		}
+	}
+}
+
+// adminGrace toggles the origin health flag. origin_down=true enables grace
+// mode, origin_down=false restores the regular election.
+func (provider *Simplefs) adminGrace(rw http.ResponseWriter, req *http.Request) {
+	switch req.FormValue("origin_down") {
+	case "true":
+		provider.SetOriginHealthy(false)
+	case "false":
+		provider.SetOriginHealthy(true)
+	default:
+		http.Error(rw, "missing origin_down parameter (true or false)", http.StatusBadRequest)
+
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(map[string]bool{"origin_down": provider.OriginDown()})
+}
+
+// adminPurge handles single-key or regex pattern purge requests and returns
+// the deletion report. With dry_run=true it only reports the keys and bytes a
+// deletion would remove, handy to validate a pattern first.
+func (provider *Simplefs) adminPurge(rw http.ResponseWriter, req *http.Request) {
+	var report PurgeReport
+
+	dryRun := req.FormValue("dry_run") == "true"
+
+	switch {
+	case req.FormValue("key") != "":
+		if dryRun {
+			report = provider.measureKey(req.FormValue("key"))
+		} else {
+			report = provider.DeleteWithReport(req.FormValue("key"))
+			provider.audit("purge", req.FormValue("key"), req.RemoteAddr, report)
+		}
+	case req.FormValue("pattern") != "":
+		if dryRun {
+			report, _ = provider.DeleteManyDryRun(req.FormValue("pattern"))
+		} else {
+			report, _ = provider.DeleteManyWithReport(req.FormValue("pattern"))
+			provider.audit("purge", req.FormValue("pattern"), req.RemoteAddr, report)
+		}
+	default:
+		http.Error(rw, "missing key or pattern parameter", http.StatusBadRequest)
+
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(report)
+}
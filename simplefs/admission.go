@@ -0,0 +1,151 @@
+package simplefs
+
+import (
+	"hash/fnv"
+	"strings"
+	"sync"
+)
+
+// sketchDepth is the number of hash functions of the Count-Min Sketch.
+const sketchDepth = 4
+
+// sketchCounterMax is the cap of a 4-bit counter.
+const sketchCounterMax = 15
+
+// admissionFilter estimates key frequencies with a 4-bit Count-Min Sketch,
+// implementing TinyLFU-style admission: only keys seen more than the
+// threshold within the window are worth a disk write, so one-hit wonders stop
+// churning the cache. Once the recorded samples reach the window size every
+// counter is halved, aging out old frequency information.
+type admissionFilter struct {
+	mu         sync.Mutex
+	counters   []byte // two 4-bit counters per byte
+	mask       uint64 // counter index mask (the counter count is a power of two)
+	minHits    int    // minimum occurrences required before persisting
+	samples    int    // samples recorded in the current window
+	windowSize int    // triggers the counter halving when reached
+	bypass     []string
+}
+
+// newAdmissionFilter creates an admission filter. counterCount is rounded up
+// to a power of two and the key prefixes listed in bypass skip the admission
+// check.
+func newAdmissionFilter(minHits, counterCount int, bypass []string) *admissionFilter {
+	size := 1
+	for size < counterCount {
+		size <<= 1
+	}
+
+	return &admissionFilter{
+		counters:   make([]byte, size/2+1),
+		mask:       uint64(size - 1),
+		minHits:    minHits,
+		windowSize: size * 8,
+		bypass:     bypass,
+	}
+}
+
+// counterAt reads the 4-bit counter at index.
+func (filter *admissionFilter) counterAt(index uint64) byte {
+	value := filter.counters[index/2]
+	if index%2 == 0 {
+		return value & 0x0f
+	}
+
+	return value >> 4
+}
+
+// incrementAt increments the 4-bit counter at index (saturating at the cap).
+func (filter *admissionFilter) incrementAt(index uint64) {
+	if filter.counterAt(index) >= sketchCounterMax {
+		return
+	}
+
+	if index%2 == 0 {
+		filter.counters[index/2]++
+	} else {
+		filter.counters[index/2] += 0x10
+	}
+}
+
+// positions computes the sketchDepth counter indexes of a key in the sketch.
+func (filter *admissionFilter) positions(key string) [sketchDepth]uint64 {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(key))
+	base := hasher.Sum64()
+
+	var result [sketchDepth]uint64
+	for i := range result {
+		// Derive independent indexes with distinct odd multipliers.
+		result[i] = (base * (2*uint64(i) + 1)) & filter.mask
+	}
+
+	return result
+}
+
+// admit records one occurrence of the key and reports whether it is already
+// hot enough to deserve a disk write.
+func (filter *admissionFilter) admit(key string) bool {
+	for _, prefix := range filter.bypass {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	filter.mu.Lock()
+	defer filter.mu.Unlock()
+
+	positions := filter.positions(key)
+
+	// Read the current estimate (the minimum across the positions).
+	estimate := filter.counterAt(positions[0])
+	for _, position := range positions[1:] {
+		if count := filter.counterAt(position); count < estimate {
+			estimate = count
+		}
+	}
+
+	// Conservative update: only increment the counters equal to the minimum,
+	// reducing overestimation.
+	for _, position := range positions {
+		if filter.counterAt(position) == estimate {
+			filter.incrementAt(position)
+		}
+	}
+
+	filter.samples++
+	if filter.samples >= filter.windowSize {
+		filter.age()
+	}
+
+	return int(estimate)+1 > filter.minHits
+}
+
+// boost lifts the frequency estimate of a key straight to the admission
+// threshold, so its next write passes admission. Called when the ghost list
+// detects an eviction mistake (requested again right after being evicted).
+func (filter *admissionFilter) boost(key string) {
+	filter.mu.Lock()
+	defer filter.mu.Unlock()
+
+	target := byte(sketchCounterMax)
+	if filter.minHits < sketchCounterMax {
+		target = byte(filter.minHits)
+	}
+
+	for _, position := range filter.positions(key) {
+		for filter.counterAt(position) < target {
+			filter.incrementAt(position)
+		}
+	}
+}
+
+// age halves every counter and resets the window, decaying old frequency
+// information over time.
+func (filter *admissionFilter) age() {
+	for i := range filter.counters {
+		filter.counters[i] = (filter.counters[i] >> 1) & 0x77
+	}
+
+	filter.samples = 0
+}
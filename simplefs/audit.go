@@ -0,0 +1,77 @@
+package simplefs
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/darkweak/storages/core"
+)
+
+// AuditEntry is the structured audit record of one destructive operation.
+type AuditEntry struct {
+	Time       time.Time `json:"time"`
+	Operation  string    `json:"operation"`
+	Pattern    string    `json:"pattern"`
+	Matched    int       `json:"matched"`
+	FreedBytes int64     `json:"freed_bytes"`
+	Caller     string    `json:"caller,omitempty"`
+}
+
+// auditLog appends the audit records as JSON lines to a file, falling back to
+// the log channel without a configured file, so a destructive operation
+// always leaves a trail.
+type auditLog struct {
+	mu     sync.Mutex
+	file   *os.File
+	logger core.Logger
+}
+
+// newAuditLog opens the audit file (append mode). An empty path uses the log
+// channel only.
+func newAuditLog(path string, logger core.Logger) *auditLog {
+	audit := &auditLog{logger: logger}
+
+	if path != "" {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			logger.Errorf("Impossible to open the audit log file %s: %v", path, err)
+		} else {
+			audit.file = file
+		}
+	}
+
+	return audit
+}
+
+// record writes one audit record.
+func (audit *auditLog) record(entry AuditEntry) {
+	entry.Time = time.Now()
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	if audit.file != nil {
+		audit.mu.Lock()
+		_, _ = audit.file.Write(append(line, '\n'))
+		audit.mu.Unlock()
+
+		return
+	}
+
+	audit.logger.Warnf("Audit: %s", line)
+}
+
+// audit records a destructive operation and its deletion report.
+func (provider *Simplefs) audit(operation, pattern, caller string, report PurgeReport) {
+	provider.auditLog.record(AuditEntry{
+		Operation:  operation,
+		Pattern:    pattern,
+		Matched:    len(report.Keys),
+		FreedBytes: report.FreedBytes,
+		Caller:     caller,
+	})
+}
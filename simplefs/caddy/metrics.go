@@ -0,0 +1,89 @@
+package caddy
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// prometheusMetrics implements simplefs.Metrics on top of Caddy's metrics
+// registry, so cache effectiveness and disk pressure can be scraped and
+// alerted on alongside the rest of Caddy's metrics.
+type prometheusMetrics struct {
+	hits       prometheus.Counter
+	misses     prometheus.Counter
+	evictions  prometheus.Counter
+	actualSize prometheus.Gauge
+	ratio      prometheus.Histogram
+	getLatency prometheus.Histogram
+	setLatency prometheus.Histogram
+}
+
+// registerCollector registers a collector, reusing the already registered
+// instance when several simplefs storages share one registry.
+func registerCollector[C prometheus.Collector](registry *prometheus.Registry, collector C) C {
+	if err := registry.Register(collector); err != nil {
+		are := prometheus.AlreadyRegisteredError{}
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+	}
+
+	return collector
+}
+
+// newPrometheusMetrics builds and registers the simplefs metric set.
+func newPrometheusMetrics(registry *prometheus.Registry) *prometheusMetrics {
+	opts := func(name, help string) prometheus.Opts {
+		return prometheus.Opts{Namespace: "souin_storages", Subsystem: moduleName, Name: name, Help: help}
+	}
+
+	return &prometheusMetrics{
+		hits: registerCollector(registry, prometheus.NewCounter(
+			prometheus.CounterOpts(opts("hits_total", "Number of cache hits.")))),
+		misses: registerCollector(registry, prometheus.NewCounter(
+			prometheus.CounterOpts(opts("misses_total", "Number of cache misses.")))),
+		evictions: registerCollector(registry, prometheus.NewCounter(
+			prometheus.CounterOpts(opts("evictions_total", "Number of entries evicted by capacity or expiry.")))),
+		actualSize: registerCollector(registry, prometheus.NewGauge(
+			prometheus.GaugeOpts(opts("actual_size_bytes", "Current on-disk size of the cache.")))),
+		ratio: registerCollector(registry, prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "souin_storages", Subsystem: moduleName, Name: "compression_ratio",
+			Help:    "Compressed/uncompressed size ratio per write.",
+			Buckets: prometheus.LinearBuckets(0.1, 0.1, 10),
+		})),
+		getLatency: registerCollector(registry, prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "souin_storages", Subsystem: moduleName, Name: "get_duration_seconds",
+			Help:    "Latency of entry reads.",
+			Buckets: prometheus.DefBuckets,
+		})),
+		setLatency: registerCollector(registry, prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "souin_storages", Subsystem: moduleName, Name: "set_duration_seconds",
+			Help:    "Latency of entry writes.",
+			Buckets: prometheus.DefBuckets,
+		})),
+	}
+}
+
+func (metrics *prometheusMetrics) CacheHit()  { metrics.hits.Inc() }
+func (metrics *prometheusMetrics) CacheMiss() { metrics.misses.Inc() }
+func (metrics *prometheusMetrics) Eviction()  { metrics.evictions.Inc() }
+
+func (metrics *prometheusMetrics) SetActualSize(bytes int64) {
+	metrics.actualSize.Set(float64(bytes))
+}
+
+func (metrics *prometheusMetrics) CompressionRatio(ratio float64) {
+	metrics.ratio.Observe(ratio)
+}
+
+func (metrics *prometheusMetrics) ObserveGet(duration time.Duration) {
+	metrics.getLatency.Observe(duration.Seconds())
+}
+
+func (metrics *prometheusMetrics) ObserveSet(duration time.Duration) {
+	metrics.setLatency.Observe(duration.Seconds())
+}
@@ -2,11 +2,13 @@ package caddy
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/WJQSERVER/souin-storages/simplefs"
 	caddy "github.com/caddyserver/caddy/v2"
 	"github.com/caddyserver/caddy/v2/modules/caddyhttp"
 	"github.com/darkweak/storages/core"
+	"github.com/dustin/go-humanize"
 )
 
 const moduleName = "simplefs"
@@ -39,13 +41,123 @@ func (b *Simplefs) Provision(ctx caddy.Context) error {
 		return err
 	}
 
-	logger.Info("SimpleFS 存储已启动") // 类型断言失败时的默认日志
+	logger.Info("The SimpleFS storage is up")
+
+	// Register the cache metrics on the Caddy metrics registry.
+	if provider, ok := storer.(*simplefs.Simplefs); ok {
+		provider.SetMetrics(newPrometheusMetrics(ctx.GetMetricsRegistry()))
+	}
+
+	// The memory_front configuration stacks an in-memory LRU layer in front
+	// of SimpleFS: hot keys are promoted to memory through the read repair,
+	// and an LRU eviction only demotes them back to the disk layer.
+	if memoryFront := parseMemoryFront(b.Configuration.Provider.Configuration, logger.Sugar()); memoryFront.size > 0 {
+		if memoryFront.writeBehind {
+			// Opt-in write-behind: writes acknowledge once the memory tier
+			// holds them and a bounded queue persists to disk. Entries
+			// queued but not yet persisted are lost on crash.
+			storer = core.NewWriteBehindStorer(storer, memoryFront.writeBehindQueue, memoryFront.writeBehindBlock, logger.Sugar())
+
+			logger.Warn("Write-behind persistence is enabled: entries queued but not yet persisted are lost on crash")
+		}
+
+		storer = core.NewTieredStorer(
+			true,
+			memoryFront.ttl,
+			core.NewMemoryStorer(memoryFront.size, b.Configuration.Stale, logger.Sugar()),
+			storer,
+		)
+
+		logger.Info("The memory front layer is enabled in front of SimpleFS")
+	}
 
 	core.RegisterStorage(storer)
 
 	return nil
 }
 
+// memoryFrontConfig describes the memory front layer configuration, a size
+// of 0 means disabled.
+type memoryFrontConfig struct {
+	size             int64
+	ttl              time.Duration
+	writeBehind      bool
+	writeBehindQueue int
+	writeBehindBlock bool
+}
+
+// parseMemoryFront parses memory_front (the capacity, humanize size strings
+// are supported), memory_front_ttl (the TTL of the read-repair backfill) and
+// the write_behind options from the provider configuration.
+func parseMemoryFront(configuration interface{}, logger core.Logger) memoryFrontConfig {
+	result := memoryFrontConfig{ttl: time.Minute}
+
+	config, ok := configuration.(map[string]interface{})
+	if !ok {
+		return result
+	}
+
+	if value, ok := config["memory_front"]; ok {
+		switch sizeValue := value.(type) {
+		case int:
+			result.size = int64(sizeValue)
+		case float64:
+			result.size = int64(sizeValue)
+		case string:
+			size, err := humanize.ParseBytes(sizeValue)
+			if err != nil {
+				logger.Errorf("Impossible to parse the memory_front value %v: %v", value, err)
+			} else {
+				result.size = int64(size) //nolint:gosec
+			}
+		}
+	}
+
+	if value, ok := config["memory_front_ttl"]; ok {
+		if ttlValue, ok := value.(string); ok {
+			ttl, err := time.ParseDuration(ttlValue)
+			if err != nil {
+				logger.Errorf("Impossible to parse the memory_front_ttl value %v: %v", value, err)
+			} else {
+				result.ttl = ttl
+			}
+		}
+	}
+
+	if value, ok := config["write_behind"]; ok {
+		if enabled, ok := value.(bool); ok {
+			result.writeBehind = enabled
+		}
+	}
+
+	if value, ok := config["write_behind_queue"]; ok {
+		switch queueValue := value.(type) {
+		case int:
+			result.writeBehindQueue = queueValue
+		case float64:
+			result.writeBehindQueue = int(queueValue)
+		}
+	}
+
+	// write_behind_overflow selects what a full queue does with a write:
+	// "write_through" (the default) applies it synchronously, "block" waits
+	// for a slot. Neither drops it silently.
+	if value, ok := config["write_behind_overflow"]; ok {
+		if overflow, ok := value.(string); ok {
+			switch overflow {
+			case "block":
+				result.writeBehindBlock = true
+			case "write_through":
+				result.writeBehindBlock = false
+			default:
+				logger.Errorf("Impossible to parse the write_behind_overflow value %v, expected block or write_through", value)
+			}
+		}
+	}
+
+	return result
+}
+
 func (b *Simplefs) ServeHTTP(rw http.ResponseWriter, rq *http.Request, next caddyhttp.Handler) error {
 	return next.ServeHTTP(rw, rq)
 }
@@ -0,0 +1,86 @@
+package simplefs
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+
+	"github.com/pierrec/lz4/v4"
+)
+
+// lz4Levels maps the 1-9 configured levels to lz4 compression levels, the
+// higher the better the ratio and the bigger the CPU cost.
+var lz4Levels = [...]lz4.CompressionLevel{
+	lz4.Level1, lz4.Level2, lz4.Level3, lz4.Level4, lz4.Level5,
+	lz4.Level6, lz4.Level7, lz4.Level8, lz4.Level9,
+}
+
+// gzipLevel returns the configured gzip compression level, falling back to
+// the library default at 0 or out of range.
+func (provider *Simplefs) gzipLevel() int {
+	if provider.compressionLevel >= gzip.BestSpeed && provider.compressionLevel <= gzip.BestCompression {
+		return provider.compressionLevel
+	}
+
+	return gzip.DefaultCompression
+}
+
+// lz4Level returns the configured lz4 compression level, the fast mode (the
+// library default) applies at 0 or out of range.
+func (provider *Simplefs) lz4Level() lz4.CompressionLevel {
+	if provider.compressionLevel >= 1 && provider.compressionLevel <= len(lz4Levels) {
+		return lz4Levels[provider.compressionLevel-1]
+	}
+
+	return lz4.Fast
+}
+
+// newCompressionWriter builds the compression writer for the method and the
+// configured level. With a pre-trained dictionary the gzip entries switch to
+// a raw deflate stream using it: the dictionary buys a markedly better ratio
+// on highly repetitive HTML/JSON, but like switching the compression method,
+// changing the dictionary makes the existing entries unreadable. A nil Closer
+// means no flush is needed.
+func (provider *Simplefs) newCompressionWriter(method string, target io.Writer) (io.Writer, io.Closer, error) {
+	switch method {
+	case "gzip":
+		if len(provider.compressionDict) > 0 {
+			zw, err := flate.NewWriterDict(target, provider.gzipLevel(), provider.compressionDict)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			return zw, zw, nil
+		}
+
+		zw, err := gzip.NewWriterLevel(target, provider.gzipLevel())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return zw, zw, nil
+	case "lz4", "":
+		w := lz4.NewWriter(target)
+		_ = w.Apply(lz4.CompressionLevelOption(provider.lz4Level()))
+
+		return w, w, nil
+	default: // none or an unknown method writes as-is
+		return target, nil, nil
+	}
+}
+
+// entryGzipReader builds the reader matching the gzip path of
+// newCompressionWriter, withDict coming from the entry header; entries using
+// the dictionary require the same dictionary to be configured.
+func (provider *Simplefs) entryGzipReader(source io.Reader, withDict bool) (io.ReadCloser, error) {
+	if withDict {
+		if len(provider.compressionDict) == 0 {
+			return nil, errors.New("the entry uses a pre-trained dictionary but no compression_dictionary is configured")
+		}
+
+		return flate.NewReaderDict(source, provider.compressionDict), nil
+	}
+
+	return gzip.NewReader(source)
+}
@@ -0,0 +1,94 @@
+package simplefs
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// compressionRule maps a content type prefix to a compression method, e.g.
+// image/ -> none.
+type compressionRule struct {
+	contentType string // prefix matched against the Content-Type
+	method      string // none, lz4 or gzip
+}
+
+// parseCompressionRules parses the compression_by_type configuration block,
+// shaped like { "image/": "none", "text/": "gzip" }. The rules are sorted by
+// descending prefix length, so the most specific prefix matches first.
+func parseCompressionRules(value interface{}) []compressionRule {
+	configMap, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	rules := make([]compressionRule, 0, len(configMap))
+
+	for contentType, method := range configMap {
+		if methodValue, ok := method.(string); ok {
+			rules = append(rules, compressionRule{contentType: contentType, method: methodValue})
+		}
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		return len(rules[i].contentType) > len(rules[j].contentType)
+	})
+
+	return rules
+}
+
+// compressionFor picks the compression method of an entry from the
+// Content-Type of the stored response headers, falling back to the global
+// configuration without a matching rule, so already-compressed media never
+// wastes CPU on a second compression. With the automatic bypass enabled, a
+// group whose compression ratio stays close to 1.0 switches to uncompressed
+// storage.
+func (provider *Simplefs) compressionFor(value []byte) string {
+	method := provider.compression
+
+	if len(provider.compressionRules) > 0 {
+		if headers := storedResponseHeaders(value); headers != nil {
+			contentType := headers.Get("Content-Type")
+
+			for _, rule := range provider.compressionRules {
+				if strings.HasPrefix(contentType, rule.contentType) {
+					method = rule.method
+
+					break
+				}
+			}
+		}
+	}
+
+	if provider.autoBypass && method != "none" && provider.ratioTracker.shouldBypass(contentTypeGroup(value)) {
+		return "none"
+	}
+
+	return method
+}
+
+// Magic bytes of the compression formats, identifying on read the method an
+// entry actually uses.
+var (
+	lz4Magic  = []byte{0x04, 0x22, 0x4d, 0x18}
+	gzipMagic = []byte{0x1f, 0x8b}
+)
+
+// detectCompression identifies the compression method of an entry through
+// its leading magic bytes. With per-content-type compression the same
+// directory mixes several formats, so the read path cannot assume the global
+// configuration anymore. Identity-stored content that happens to be a
+// gzip/lz4 file would be misdetected, which never happens for HTTP response
+// bodies (they start with the status line).
+func detectCompression(content []byte, fallback string) string {
+	switch {
+	case bytes.HasPrefix(content, lz4Magic):
+		return "lz4"
+	case bytes.HasPrefix(content, gzipMagic):
+		return "gzip"
+	case len(content) > 0:
+		return "none"
+	}
+
+	return fallback
+}
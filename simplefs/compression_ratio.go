@@ -0,0 +1,118 @@
+package simplefs
+
+import (
+	"strings"
+	"sync"
+)
+
+// Decision parameters of the automatic bypass: once the sample count reaches
+// the floor and the average ratio exceeds the threshold, the following
+// entries of the group are stored uncompressed.
+const (
+	ratioBypassMinSamples = 16
+	ratioBypassThreshold  = 0.95
+)
+
+// ratioGroup aggregates the compression effectiveness of one content type group.
+type ratioGroup struct {
+	samples  int64
+	sumRatio float64
+	bypassed bool
+}
+
+// CompressionGroupStats is the per-group compression effectiveness exposed to
+// the statistics endpoint.
+type CompressionGroupStats struct {
+	Samples  int64   `json:"samples"`
+	AvgRatio float64 `json:"avg_ratio"`
+	Bypassed bool    `json:"bypassed"`
+}
+
+// compressionTracker tracks the compression ratio actually achieved per
+// content type, automatically bypassing the compression of the groups whose
+// ratio stays close to 1.0, saving the CPU wasted on already-compressed media.
+type compressionTracker struct {
+	mu     sync.Mutex
+	groups map[string]*ratioGroup
+}
+
+// newCompressionTracker creates an empty compression effectiveness tracker.
+func newCompressionTracker() *compressionTracker {
+	return &compressionTracker{groups: map[string]*ratioGroup{}}
+}
+
+// observe records one compression result, flagging the bypass once the
+// decision criteria are met.
+func (tracker *compressionTracker) observe(group string, originalSize, compressedSize int) {
+	if originalSize <= 0 {
+		return
+	}
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	stats := tracker.groups[group]
+	if stats == nil {
+		stats = &ratioGroup{}
+		tracker.groups[group] = stats
+	}
+
+	stats.samples++
+	stats.sumRatio += float64(compressedSize) / float64(originalSize)
+
+	if !stats.bypassed && stats.samples >= ratioBypassMinSamples && stats.sumRatio/float64(stats.samples) >= ratioBypassThreshold {
+		stats.bypassed = true
+	}
+}
+
+// shouldBypass reports whether the group has been deemed not worth compressing.
+func (tracker *compressionTracker) shouldBypass(group string) bool {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	stats := tracker.groups[group]
+
+	return stats != nil && stats.bypassed
+}
+
+// snapshot exports the compression effectiveness of every group, so the
+// statistics endpoint can report the bypass decisions.
+func (tracker *compressionTracker) snapshot() map[string]CompressionGroupStats {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	result := make(map[string]CompressionGroupStats, len(tracker.groups))
+
+	for group, stats := range tracker.groups {
+		entry := CompressionGroupStats{Samples: stats.samples, Bypassed: stats.bypassed}
+		if stats.samples > 0 {
+			entry.AvgRatio = stats.sumRatio / float64(stats.samples)
+		}
+
+		result[group] = entry
+	}
+
+	return result
+}
+
+// contentTypeGroup extracts the content type (without its parameters) of a
+// stored response as the grouping key.
+func contentTypeGroup(value []byte) string {
+	headers := storedResponseHeaders(value)
+	if headers == nil {
+		return ""
+	}
+
+	contentType := headers.Get("Content-Type")
+	if idx := strings.Index(contentType, ";"); idx >= 0 {
+		contentType = contentType[:idx]
+	}
+
+	return strings.TrimSpace(contentType)
+}
+
+// CompressionStats returns the compression effectiveness and the bypass
+// decisions grouped by content type.
+func (provider *Simplefs) CompressionStats() map[string]CompressionGroupStats {
+	return provider.ratioTracker.snapshot()
+}
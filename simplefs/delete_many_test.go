@@ -0,0 +1,83 @@
+package simplefs
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestDeleteManyFromResume checks that a DeleteManyFrom bounded by the scan
+// cap completes the deletion across several calls: the returned resume key
+// being deleted itself does not break the next positioning, and every
+// matching key ends up deleted exactly once.
+func TestDeleteManyFromResume(t *testing.T) {
+	provider := newTestProvider(t, 1<<20)
+	provider.deleteManyMaxScan = 3
+
+	total := 10
+	for i := 0; i < total; i++ {
+		fillEntry(t, provider, fmt.Sprintf("GET-http-test.local-/entry/%d", i), 64)
+	}
+
+	fillEntry(t, provider, "GET-http-test.local-/survivor", 64)
+
+	pattern := "^GET-http-test.local-/entry/.*"
+
+	after := ""
+	calls := 0
+
+	for {
+		calls++
+		if calls > 50 {
+			t.Fatal("the resumed scan should complete in a bounded number of calls")
+		}
+
+		next := provider.DeleteManyFrom(pattern, after)
+		if next == "" {
+			break
+		}
+
+		after = next
+	}
+
+	if calls < 2 {
+		t.Errorf("deleting %d keys with a scan cap of %d should take several calls, got %d", total, provider.deleteManyMaxScan, calls)
+	}
+
+	for i := 0; i < total; i++ {
+		key := fmt.Sprintf("GET-http-test.local-/entry/%d", i)
+		if provider.cache.Get(key) != nil {
+			t.Errorf("the key %s should have been deleted", key)
+		}
+	}
+
+	if provider.cache.Get("GET-http-test.local-/survivor") == nil {
+		t.Error("a key that does not match the pattern should be kept")
+	}
+}
+
+// TestDeleteManyFromBoundaryKeyIsProcessed checks that when the resume key
+// itself was not processed by the previous call it is included in the current
+// scan instead of being skipped.
+func TestDeleteManyFromBoundaryKeyIsProcessed(t *testing.T) {
+	provider := newTestProvider(t, 1<<20)
+
+	for i := 0; i < 4; i++ {
+		fillEntry(t, provider, fmt.Sprintf("GET-http-test.local-/entry/%d", i), 64)
+	}
+
+	// Resume from an existing key; that key must be deleted by this call.
+	boundary := "GET-http-test.local-/entry/2"
+
+	if next := provider.DeleteManyFrom("^GET-http-test.local-/entry/.*", boundary); next != "" {
+		t.Errorf("an unbounded scan should complete in one call, got %s", next)
+	}
+
+	if provider.cache.Get(boundary) != nil {
+		t.Errorf("the resume key %s should have been deleted", boundary)
+	}
+
+	// Keys sorting before the resume point are out of this scan and are kept.
+	if provider.cache.Get("GET-http-test.local-/entry/0") == nil {
+		t.Error("keys before the resume point should not be deleted")
+	}
+}
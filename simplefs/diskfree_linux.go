@@ -0,0 +1,16 @@
+//go:build linux
+
+package simplefs
+
+import "syscall"
+
+// diskFree returns the available bytes of the filesystem holding path, 0 on failure.
+func diskFree(path string) uint64 {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0
+	}
+
+	//nolint:gosec
+	return stat.Bavail * uint64(stat.Bsize)
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package simplefs
+
+// diskFree returns 0 on platforms without Statfs, meaning unavailable.
+func diskFree(_ string) uint64 {
+	return 0
+}
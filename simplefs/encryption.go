@@ -0,0 +1,202 @@
+package simplefs
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/darkweak/storages/core"
+)
+
+// Header of encrypted entries: magic bytes + version + key id. Writing the
+// key id into the header makes key rotation possible: new entries are sealed
+// with the current key while old ones stay decryptable with the matching
+// previous_keys entry. Encryption happens after compression, so the
+// ciphertext wraps the compressed bytes with their entry header.
+var encryptionMagic = []byte{0xF5, 'e', 'n', 'c'}
+
+// encryptionVersion is the current encryption header format version.
+const encryptionVersion = 1
+
+// encryptionHeaderLen is the encryption header length: 4 magic bytes + 1 version byte + 1 key id byte.
+const encryptionHeaderLen = 6
+
+// entryEncryptor holds the AEADs indexed by key id, activeID seals new entries.
+type entryEncryptor struct {
+	activeID byte
+	aeads    map[byte]cipher.AEAD
+}
+
+// seal encrypts the compressed entry bytes, emitting header + random nonce + ciphertext.
+func (encryptor *entryEncryptor) seal(plain []byte) ([]byte, error) {
+	aead := encryptor.aeads[encryptor.activeID]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, encryptionHeaderLen+len(nonce)+len(plain)+aead.Overhead())
+	out = append(out, encryptionMagic...)
+	out = append(out, encryptionVersion, encryptor.activeID)
+	out = append(out, nonce...)
+
+	return aead.Seal(out, nonce, plain, nil), nil
+}
+
+// open decrypts the entry bytes. Content without an encryption header (legacy
+// entries written before encryption was enabled) is returned as-is; an
+// unknown key id or an authentication failure returns an error.
+func (encryptor *entryEncryptor) open(content []byte) ([]byte, error) {
+	if len(content) < encryptionHeaderLen ||
+		string(content[:len(encryptionMagic)]) != string(encryptionMagic) {
+		return content, nil
+	}
+
+	if content[len(encryptionMagic)] != encryptionVersion {
+		return nil, fmt.Errorf("unsupported encryption header version %d", content[len(encryptionMagic)])
+	}
+
+	keyID := content[len(encryptionMagic)+1]
+
+	aead, found := encryptor.aeads[keyID]
+	if !found {
+		return nil, fmt.Errorf("no decryption key configured for the key id %d", keyID)
+	}
+
+	rest := content[encryptionHeaderLen:]
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("truncated encrypted entry")
+	}
+
+	return aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], nil)
+}
+
+// parseEncryptionKey normalizes the key material into a raw AES-128/192/256
+// key: when the content hex-decodes to a legal length the decoded bytes are
+// used, otherwise the raw bytes are.
+func parseEncryptionKey(material []byte) ([]byte, error) {
+	trimmed := strings.TrimSpace(string(material))
+
+	if decoded, err := hex.DecodeString(trimmed); err == nil {
+		switch len(decoded) {
+		case 16, 24, 32:
+			return decoded, nil
+		}
+	}
+
+	switch len(material) {
+	case 16, 24, 32:
+		return material, nil
+	}
+
+	return nil, fmt.Errorf("invalid key length %d, expected 16, 24 or 32 bytes", len(material))
+}
+
+// newEncryptionAEAD builds an AES-GCM AEAD from the key material.
+func newEncryptionAEAD(material []byte) (cipher.AEAD, error) {
+	key, err := parseEncryptionKey(material)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// parseEncryption parses the encryption configuration block and builds the
+// encryptor. The key comes from key_file or key_env (hex), key_id labels the
+// current key (default 1), and previous_keys maps ids to the old key files
+// still needed for decryption after a rotation.
+func parseEncryption(config map[string]interface{}, logger core.Logger) (*entryEncryptor, error) {
+	activeID := 1
+
+	if v, found := config["key_id"]; found && v != nil {
+		if val, ok := v.(int); ok {
+			activeID = val
+		} else if val, ok := v.(float64); ok {
+			activeID = int(val)
+		} else if val, ok := v.(string); ok {
+			activeID, _ = strconv.Atoi(val)
+		}
+	}
+
+	if activeID < 1 || activeID > 255 {
+		return nil, fmt.Errorf("key_id %d is out of the 1-255 range", activeID)
+	}
+
+	var material []byte
+
+	if v, found := config["key_file"]; found && v != nil {
+		if val, ok := v.(string); ok && val != "" {
+			content, err := os.ReadFile(val)
+			if err != nil {
+				return nil, fmt.Errorf("impossible to read the key file %s: %w", val, err)
+			}
+
+			material = content
+		}
+	}
+
+	if v, found := config["key_env"]; found && v != nil && material == nil {
+		if val, ok := v.(string); ok && val != "" {
+			material = []byte(os.Getenv(val))
+		}
+	}
+
+	if len(material) == 0 {
+		return nil, errors.New("the encryption configuration requires a key through key_file or key_env")
+	}
+
+	active, err := newEncryptionAEAD(material)
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:gosec
+	encryptor := &entryEncryptor{activeID: byte(activeID), aeads: map[byte]cipher.AEAD{byte(activeID): active}}
+
+	// Rotated old keys, only used to decrypt existing entries.
+	if v, found := config["previous_keys"]; found && v != nil {
+		if previous, ok := v.(map[string]interface{}); ok {
+			for rawID, rawPath := range previous {
+				keyID, idErr := strconv.Atoi(rawID)
+				if idErr != nil || keyID < 1 || keyID > 255 {
+					logger.Errorf("Invalid key id %s in previous_keys, ignored", rawID)
+
+					continue
+				}
+
+				path, ok := rawPath.(string)
+				if !ok || path == "" {
+					continue
+				}
+
+				content, readErr := os.ReadFile(path)
+				if readErr != nil {
+					return nil, fmt.Errorf("impossible to read the key file %s: %w", path, readErr)
+				}
+
+				aead, aeadErr := newEncryptionAEAD(content)
+				if aeadErr != nil {
+					return nil, aeadErr
+				}
+
+				//nolint:gosec
+				encryptor.aeads[byte(keyID)] = aead
+			}
+		}
+	}
+
+	return encryptor, nil
+}
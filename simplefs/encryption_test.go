@@ -0,0 +1,155 @@
+package simplefs
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"testing"
+)
+
+// newTestEncryptor builds an encryptor from the given raw keys.
+func newTestEncryptor(t *testing.T, activeID byte, keys map[byte][]byte) *entryEncryptor {
+	t.Helper()
+
+	aeads := map[byte]cipher.AEAD{}
+
+	for id, material := range keys {
+		aead, err := newEncryptionAEAD(material)
+		if err != nil {
+			t.Fatalf("impossible to build the AEAD: %v", err)
+		}
+
+		aeads[id] = aead
+	}
+
+	return &entryEncryptor{activeID: activeID, aeads: aeads}
+}
+
+// TestEncryptionSealOpenRoundTrip checks that the seal output round-trips
+// through open and that the ciphertext differs from the plaintext.
+func TestEncryptionSealOpenRoundTrip(t *testing.T) {
+	encryptor := newTestEncryptor(t, 1, map[byte][]byte{1: bytes.Repeat([]byte{'k'}, 32)})
+
+	plain := []byte("compressed entry bytes")
+
+	sealed, err := encryptor.seal(plain)
+	if err != nil {
+		t.Fatalf("seal should not fail: %v", err)
+	}
+
+	if bytes.Contains(sealed, plain) {
+		t.Error("the ciphertext should not contain the plaintext")
+	}
+
+	opened, err := encryptor.open(sealed)
+	if err != nil {
+		t.Fatalf("open should not fail: %v", err)
+	}
+
+	if !bytes.Equal(opened, plain) {
+		t.Error("the decrypted content should match the plaintext")
+	}
+}
+
+// TestEncryptionOpenWithRotatedKey checks that after a rotation an entry
+// sealed with the old key id still decrypts with the matching previous_keys
+// entry.
+func TestEncryptionOpenWithRotatedKey(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{'a'}, 32)
+	newKey := bytes.Repeat([]byte{'b'}, 32)
+
+	oldEncryptor := newTestEncryptor(t, 1, map[byte][]byte{1: oldKey})
+
+	sealed, err := oldEncryptor.seal([]byte("entry"))
+	if err != nil {
+		t.Fatalf("seal should not fail: %v", err)
+	}
+
+	rotated := newTestEncryptor(t, 2, map[byte][]byte{1: oldKey, 2: newKey})
+
+	if _, err := rotated.open(sealed); err != nil {
+		t.Errorf("the old entry should still decrypt after the rotation: %v", err)
+	}
+}
+
+// TestEncryptionOpenRejectsWrongKey checks that a wrong key fails the
+// authentication instead of returning garbage.
+func TestEncryptionOpenRejectsWrongKey(t *testing.T) {
+	encryptor := newTestEncryptor(t, 1, map[byte][]byte{1: bytes.Repeat([]byte{'a'}, 32)})
+
+	sealed, err := encryptor.seal([]byte("entry"))
+	if err != nil {
+		t.Fatalf("seal should not fail: %v", err)
+	}
+
+	wrong := newTestEncryptor(t, 1, map[byte][]byte{1: bytes.Repeat([]byte{'b'}, 32)})
+
+	if _, err := wrong.open(sealed); err == nil {
+		t.Error("a wrong key should fail the decryption")
+	}
+}
+
+// TestEncryptionOpenRejectsUnknownKeyID checks that a missing key id errors
+// out instead of attempting the decryption.
+func TestEncryptionOpenRejectsUnknownKeyID(t *testing.T) {
+	encryptor := newTestEncryptor(t, 2, map[byte][]byte{2: bytes.Repeat([]byte{'a'}, 32)})
+
+	sealed, err := encryptor.seal([]byte("entry"))
+	if err != nil {
+		t.Fatalf("seal should not fail: %v", err)
+	}
+
+	other := newTestEncryptor(t, 1, map[byte][]byte{1: bytes.Repeat([]byte{'a'}, 32)})
+
+	if _, err := other.open(sealed); err == nil {
+		t.Error("an unknown key id should fail the decryption")
+	}
+}
+
+// TestEncryptionOpenRejectsTruncatedEntry checks that a truncated encrypted
+// entry errors out.
+func TestEncryptionOpenRejectsTruncatedEntry(t *testing.T) {
+	encryptor := newTestEncryptor(t, 1, map[byte][]byte{1: bytes.Repeat([]byte{'a'}, 32)})
+
+	sealed, err := encryptor.seal([]byte("entry"))
+	if err != nil {
+		t.Fatalf("seal should not fail: %v", err)
+	}
+
+	if _, err := encryptor.open(sealed[:encryptionHeaderLen+4]); err == nil {
+		t.Error("a truncated entry should fail the decryption")
+	}
+}
+
+// TestEncryptionOpenPassesThroughLegacyContent checks that a legacy entry
+// written before encryption was enabled (no encryption header) is returned
+// as-is.
+func TestEncryptionOpenPassesThroughLegacyContent(t *testing.T) {
+	encryptor := newTestEncryptor(t, 1, map[byte][]byte{1: bytes.Repeat([]byte{'a'}, 32)})
+
+	legacy := []byte("legacy plain entry")
+
+	opened, err := encryptor.open(legacy)
+	if err != nil {
+		t.Fatalf("a legacy entry should not error: %v", err)
+	}
+
+	if !bytes.Equal(opened, legacy) {
+		t.Error("a legacy entry should be returned as-is")
+	}
+}
+
+// TestParseEncryptionKey checks the key material normalization: the hex
+// decoding wins, an invalid length errors out.
+func TestParseEncryptionKey(t *testing.T) {
+	if key, err := parseEncryptionKey([]byte("00112233445566778899aabbccddeeff")); err != nil || len(key) != 16 {
+		t.Errorf("a hex key should decode to 16 bytes, got %d, %v", len(key), err)
+	}
+
+	if key, err := parseEncryptionKey(bytes.Repeat([]byte{'k'}, 24)); err != nil || len(key) != 24 {
+		t.Errorf("a raw byte key should be used as-is, got %d, %v", len(key), err)
+	}
+
+	if _, err := parseEncryptionKey([]byte("short")); err == nil {
+		t.Error("a key with an invalid length should error out")
+	}
+}
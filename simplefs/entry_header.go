@@ -0,0 +1,84 @@
+package simplefs
+
+// Header of compressed entries: magic bytes + version + compression method
+// id. With it Get no longer assumes the current global configuration, so old
+// entries stay readable after a compression setting change without flushing
+// the cache. Uncompressed entries carry no header, keeping the raw bytes so
+// GetFile can emit them directly.
+var entryMagic = []byte{0xF5, 's', 'f', 's'}
+
+// entryHeaderVersion is the current header format version.
+const entryHeaderVersion = 1
+
+// entryHeaderLen is the total header length: 4 magic bytes + 1 version byte + 1 method id byte.
+const entryHeaderLen = 6
+
+// Compression method ids in the header.
+const (
+	entryCompressionLZ4      = 1
+	entryCompressionGzip     = 2
+	entryCompressionGzipDict = 3
+)
+
+// methodGzipDict is the read-path method name of a deflate stream using the
+// pre-trained dictionary.
+const methodGzipDict = "gzip+dict"
+
+// entryHeader builds the header for the given compression method id.
+func entryHeader(compressionID byte) []byte {
+	header := make([]byte, 0, entryHeaderLen)
+	header = append(header, entryMagic...)
+	header = append(header, entryHeaderVersion, compressionID)
+
+	return header
+}
+
+// entryHeaderFor returns the header the compressed write path must prepend,
+// nil for uncompressed or unknown methods (the entry keeps its raw bytes).
+func (provider *Simplefs) entryHeaderFor(method string) []byte {
+	switch method {
+	case "lz4", "":
+		return entryHeader(entryCompressionLZ4)
+	case "gzip":
+		if len(provider.compressionDict) > 0 {
+			return entryHeader(entryCompressionGzipDict)
+		}
+
+		return entryHeader(entryCompressionGzip)
+	default:
+		return nil
+	}
+}
+
+// splitEntryHeader parses the entry header, returning the compression method
+// and the body. ok is false without a header (legacy or uncompressed entries)
+// or with an unrecognized version.
+func splitEntryHeader(content []byte) (string, []byte, bool) {
+	if len(content) < entryHeaderLen ||
+		string(content[:len(entryMagic)]) != string(entryMagic) ||
+		content[len(entryMagic)] != entryHeaderVersion {
+		return "", nil, false
+	}
+
+	switch content[len(entryMagic)+1] {
+	case entryCompressionLZ4:
+		return "lz4", content[entryHeaderLen:], true
+	case entryCompressionGzip:
+		return "gzip", content[entryHeaderLen:], true
+	case entryCompressionGzipDict:
+		return methodGzipDict, content[entryHeaderLen:], true
+	default:
+		return "", nil, false
+	}
+}
+
+// resolveEntryMethod determines the compression method an entry actually
+// uses: the entry header wins, legacy files fall back to magic-byte sniffing,
+// and the globally configured method applies when nothing can be sniffed.
+func (provider *Simplefs) resolveEntryMethod(content []byte) (string, []byte) {
+	if method, body, ok := splitEntryHeader(content); ok {
+		return method, body
+	}
+
+	return detectCompression(content, provider.compression), content
+}
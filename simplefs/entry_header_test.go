@@ -0,0 +1,105 @@
+package simplefs
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestEntryHeaderRoundTrip checks that the header prepended by the write path
+// yields the same compression method on the read path, with a body untouched
+// by the header.
+func TestEntryHeaderRoundTrip(t *testing.T) {
+	body := []byte("compressed body bytes")
+
+	cases := []struct {
+		name     string
+		provider *Simplefs
+		written  string
+		resolved string
+	}{
+		{name: "lz4", provider: &Simplefs{}, written: "lz4", resolved: "lz4"},
+		{name: "gzip", provider: &Simplefs{}, written: "gzip", resolved: "gzip"},
+		{
+			name:     "gzip with dictionary",
+			provider: &Simplefs{compressionDict: []byte("dict")},
+			written:  "gzip",
+			resolved: methodGzipDict,
+		},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			header := testCase.provider.entryHeaderFor(testCase.written)
+			if header == nil {
+				t.Fatalf("the method %s should produce a header", testCase.written)
+			}
+
+			content := append(append([]byte{}, header...), body...)
+
+			method, rest, ok := splitEntryHeader(content)
+			if !ok {
+				t.Fatal("the header should be recognized")
+			}
+
+			if method != testCase.resolved {
+				t.Errorf("the resolved method %s does not match %s", method, testCase.resolved)
+			}
+
+			if !bytes.Equal(rest, body) {
+				t.Error("the body should match the written content")
+			}
+		})
+	}
+}
+
+// TestEntryHeaderForUncompressed checks that uncompressed entries carry no
+// header, keeping the raw bytes so GetFile can emit them directly.
+func TestEntryHeaderForUncompressed(t *testing.T) {
+	provider := &Simplefs{}
+
+	if header := provider.entryHeaderFor("none"); header != nil {
+		t.Error("an uncompressed entry should not produce a header")
+	}
+}
+
+// TestSplitEntryHeaderRejectsCorruptContent checks that too-short content, a
+// wrong magic or an unrecognized version are all treated as the legacy format.
+func TestSplitEntryHeaderRejectsCorruptContent(t *testing.T) {
+	cases := []struct {
+		name    string
+		content []byte
+	}{
+		{name: "too short", content: entryMagic},
+		{name: "wrong magic", content: []byte("xxxxxx body")},
+		{name: "unknown version", content: append(append([]byte{}, entryMagic...), 99, entryCompressionLZ4)},
+		{name: "unknown method", content: append(append([]byte{}, entryMagic...), entryHeaderVersion, 99)},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if _, _, ok := splitEntryHeader(testCase.content); ok {
+				t.Error("a corrupted header should not be recognized")
+			}
+		})
+	}
+}
+
+// TestResolveEntryMethodLegacyFallback checks that legacy entries without a
+// header fall back to the magic-byte sniffing, and the globally configured
+// method applies when nothing can be sniffed.
+func TestResolveEntryMethodLegacyFallback(t *testing.T) {
+	provider := &Simplefs{compression: "lz4"}
+
+	method, body := provider.resolveEntryMethod([]byte("plain legacy bytes"))
+	if method != "none" {
+		t.Errorf("a legacy entry without magic bytes should resolve to none, got %s", method)
+	}
+
+	if string(body) != "plain legacy bytes" {
+		t.Error("the body of a legacy entry should be returned as-is")
+	}
+
+	if method, _ := provider.resolveEntryMethod(nil); method != "lz4" {
+		t.Errorf("empty content should fall back to the configured method, got %s", method)
+	}
+}
@@ -0,0 +1,110 @@
+package simplefs
+
+import "sync"
+
+// etagIndex maintains the reverse index from an etag to its key set, so
+// ETag-based revalidation and purges never scan every mapping.
+type etagIndex struct {
+	mu     sync.RWMutex
+	byEtag map[string]map[string]struct{} // etag -> key set
+	byKey  map[string]string              // key -> etag, for the reverse cleanup on eviction
+}
+
+// newEtagIndex creates an empty reverse index.
+func newEtagIndex() *etagIndex {
+	return &etagIndex{
+		byEtag: map[string]map[string]struct{}{},
+		byKey:  map[string]string{},
+	}
+}
+
+// add registers a key under the given etag, an empty etag is ignored.
+func (index *etagIndex) add(etag, key string) {
+	if etag == "" {
+		return
+	}
+
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	// When the etag of a key changes, remove it from the old etag set first.
+	if previous, found := index.byKey[key]; found && previous != etag {
+		index.removeLocked(previous, key)
+	}
+
+	if index.byEtag[etag] == nil {
+		index.byEtag[etag] = map[string]struct{}{}
+	}
+
+	index.byEtag[etag][key] = struct{}{}
+	index.byKey[key] = etag
+}
+
+// removeKey cleans the index when a key is deleted or evicted.
+func (index *etagIndex) removeKey(key string) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	if etag, found := index.byKey[key]; found {
+		index.removeLocked(etag, key)
+	}
+}
+
+// removeLocked removes a key from an etag set with the lock held.
+func (index *etagIndex) removeLocked(etag, key string) {
+	if keys, found := index.byEtag[etag]; found {
+		delete(keys, key)
+
+		if len(keys) == 0 {
+			delete(index.byEtag, etag)
+		}
+	}
+
+	delete(index.byKey, key)
+}
+
+// keys returns every key registered under the given etag.
+func (index *etagIndex) keys(etag string) []string {
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+
+	keys := make([]string, 0, len(index.byEtag[etag]))
+	for key := range index.byEtag[etag] {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// KeysByEtag returns every key storing a response with the given ETag.
+func (provider *Simplefs) KeysByEtag(etag string) []string {
+	return provider.etagIndex.keys(etag)
+}
+
+// DeleteByEtag deletes every key storing a response with the given ETag and
+// reports the deletions.
+func (provider *Simplefs) DeleteByEtag(etag string) PurgeReport {
+	report := PurgeReport{}
+
+	for _, key := range provider.etagIndex.keys(etag) {
+		keyReport := provider.DeleteWithReport(key)
+		report.Keys = append(report.Keys, keyReport.Keys...)
+		report.FreedBytes += keyReport.FreedBytes
+	}
+
+	return report
+}
+
+// DeleteByEtagDryRun reports the keys and bytes a purge by the given ETag
+// would remove, without deleting anything.
+func (provider *Simplefs) DeleteByEtagDryRun(etag string) PurgeReport {
+	report := PurgeReport{}
+
+	for _, key := range provider.etagIndex.keys(etag) {
+		keyReport := provider.measureKey(key)
+		report.Keys = append(report.Keys, keyReport.Keys...)
+		report.FreedBytes += keyReport.FreedBytes
+	}
+
+	return report
+}
@@ -0,0 +1,178 @@
+package simplefs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/darkweak/storages/core"
+)
+
+// Batch parameters of the event delivery: a batch is sent when it reaches the
+// size cap or when the flush interval elapses.
+const (
+	eventBatchSize     = 100
+	eventFlushInterval = 5 * time.Second
+)
+
+// CacheEvent is a single cache event, consumed by the webhook delivery, the
+// subscription channels and downstream automation (e.g. a CDN purge fan-out
+// or a live dashboard).
+type CacheEvent struct {
+	Time  time.Time `json:"time"`
+	Type  string    `json:"type"` // set, hit, purge, evict or corruption
+	Key   string    `json:"key"`
+	Hash  string    `json:"hash"` // SHA-256 hash prefix of the key, for consumers that cannot expose the raw key
+	Size  int64     `json:"size,omitempty"`
+	Trace string    `json:"trace,omitempty"` // trace id of the request that triggered the event (when available)
+}
+
+// eventNotifier buffers the cache events and delivers them in batches to the
+// configured webhook. The webhook is the only built-in transport; SQS or NATS
+// consumers can run a small relay receiving the webhook and forwarding it,
+// keeping message queue dependencies out of the storage.
+type eventNotifier struct {
+	queue   chan CacheEvent
+	webhook string
+	logger  core.Logger
+
+	mu          sync.Mutex
+	subscribers map[chan CacheEvent]struct{}
+	active      atomic.Int32 // current subscriber count, the fast-path check on the publish side
+}
+
+// newEventNotifier creates the event notifier, events are simply dropped with
+// an empty webhook.
+func newEventNotifier(webhook string, logger core.Logger) *eventNotifier {
+	notifier := &eventNotifier{
+		webhook:     webhook,
+		logger:      logger,
+		subscribers: map[chan CacheEvent]struct{}{},
+	}
+
+	if webhook != "" {
+		notifier.queue = make(chan CacheEvent, 1024)
+
+		go notifier.deliver()
+	}
+
+	return notifier
+}
+
+// publish dispatches one event without blocking, dropping it when the queue
+// or a subscriber cannot keep up, protecting the hot path.
+func (notifier *eventNotifier) publish(event CacheEvent) {
+	if notifier.queue == nil && notifier.active.Load() == 0 {
+		return
+	}
+
+	event.Time = time.Now()
+
+	hash := sha256.Sum256([]byte(event.Key))
+	event.Hash = hex.EncodeToString(hash[:8])
+
+	if notifier.queue != nil {
+		select {
+		case notifier.queue <- event:
+		default:
+		}
+	}
+
+	if notifier.active.Load() == 0 {
+		return
+	}
+
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+
+	for subscriber := range notifier.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// subscribe registers an event subscription channel, returning the channel
+// and its cancel function. A slow consumer never blocks the cache operations,
+// events are dropped when it cannot keep up.
+func (notifier *eventNotifier) subscribe() (<-chan CacheEvent, func()) {
+	channel := make(chan CacheEvent, 64)
+
+	notifier.mu.Lock()
+	notifier.subscribers[channel] = struct{}{}
+	notifier.mu.Unlock()
+	notifier.active.Add(1)
+
+	return channel, func() {
+		notifier.mu.Lock()
+		delete(notifier.subscribers, channel)
+		notifier.mu.Unlock()
+		notifier.active.Add(-1)
+	}
+}
+
+// deliver collects the events in the background and POSTs them in batches to
+// the webhook.
+func (notifier *eventNotifier) deliver() {
+	ticker := time.NewTicker(eventFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]CacheEvent, 0, eventBatchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		payload, err := json.Marshal(batch)
+		batch = batch[:0]
+
+		if err != nil {
+			return
+		}
+
+		response, err := http.Post(notifier.webhook, "application/json", bytes.NewReader(payload))
+		if err != nil {
+			notifier.logger.Errorf("Impossible to deliver the cache events to the webhook: %v", err)
+
+			return
+		}
+
+		_ = response.Body.Close()
+	}
+
+	for {
+		select {
+		case event := <-notifier.queue:
+			batch = append(batch, event)
+			if len(batch) >= eventBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// notify emits one cache event.
+func (provider *Simplefs) notify(eventType, key string, size int64) {
+	provider.events.publish(CacheEvent{Type: eventType, Key: key, Size: size})
+}
+
+// notifyTraced emits one cache event carrying the request trace id.
+func (provider *Simplefs) notifyTraced(eventType, key string, size int64, trace string) {
+	provider.events.publish(CacheEvent{Type: eventType, Key: key, Size: size, Trace: trace})
+}
+
+// SubscribeEvents returns a channel receiving the cache events and the
+// matching cancel function, letting in-process consumers (live dashboards,
+// external invalidation bridges) subscribe to the set/hit/evict/purge events.
+func (provider *Simplefs) SubscribeEvents() (<-chan CacheEvent, func()) {
+	return provider.events.subscribe()
+}
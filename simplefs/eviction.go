@@ -0,0 +1,246 @@
+package simplefs
+
+import (
+	"strings"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/jellydator/ttlcache/v3"
+)
+
+// overBudget reports whether the current actual size exceeds the configured
+// directory size limit.
+func (provider *Simplefs) overBudget() bool {
+	if provider.directorySize <= 0 {
+		return false
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	return provider.actualSize > provider.directorySize
+}
+
+// evictSampleSize is the number of candidates sampled from the LRU tail
+// during an eviction. The victim is picked by recorded access time within the
+// sample, so access history restored after a restart can correct the drift
+// between the cache-internal (insertion) order and the real access order.
+const evictSampleSize = 16
+
+// evictOne removes one least recently used entry (skipping mapping keys) and
+// returns the freed (block-aligned) bytes and whether an entry was removed.
+func (provider *Simplefs) evictOne() (int64, bool) {
+	var victim string
+
+	var victimAccess time.Time
+
+	var victimLevel int
+
+	var sampled int
+
+	// Iterate the cache backwards (LRU order), picking the entry with the
+	// oldest access time within the tail sample.
+	provider.cache.RangeBackwards(func(item *ttlcache.Item[string, []byte]) bool {
+		if strings.HasPrefix(item.Key(), core.MappingKeyPrefix) {
+			return true // skip mapping keys, keep looking at more recent entries
+		}
+
+		if provider.pins.pinned(item.Key()) {
+			return true // pinned entries are exempt from pressure eviction
+		}
+
+		lastAccess := provider.access.last(item.Key())
+		level := provider.priorities.level(item.Key())
+
+		// Low priority entries go first; within the same level the least
+		// recently accessed one is picked.
+		if victim == "" || level < victimLevel ||
+			(level == victimLevel && lastAccess.Before(victimAccess)) {
+			victim = item.Key()
+			victimAccess = lastAccess
+			victimLevel = level
+		}
+
+		sampled++
+
+		return sampled < evictSampleSize
+	})
+
+	if victim == "" {
+		return 0, false
+	}
+
+	// Remove the cache item, the charge and the physical file synchronously,
+	// so the caller immediately observes the freed space.
+	freed := provider.deleteKey(victim)
+
+	provider.tenants.recordEviction(victim)
+	provider.ghosts.add(victim) // feed the ghost list to detect eviction mistakes
+	provider.metrics.Eviction()
+	provider.notify("evict", victim, freed)
+
+	return freed, true
+}
+
+// evictUntilFree synchronously evicts LRU entries until a write of size bytes
+// fits (or no evictable entry remains), returning the total freed bytes.
+func (provider *Simplefs) evictUntilFree(size int64) int64 {
+	if provider.directorySize <= 0 {
+		return 0
+	}
+
+	var freedTotal int64
+
+	for {
+		provider.mu.Lock()
+		projected := provider.actualSize + size
+		provider.mu.Unlock()
+
+		if projected <= provider.directorySize {
+			return freedTotal
+		}
+
+		freed, ok := provider.evictOne()
+		if !ok {
+			return freedTotal // no evictable entry left
+		}
+
+		freedTotal += freed
+	}
+}
+
+// requestEviction wakes up the background evictor without blocking.
+func (provider *Simplefs) requestEviction() {
+	select {
+	case provider.evictWake <- struct{}{}:
+	default: // the evictor is already awake, no need to notify again
+	}
+}
+
+// evictionLoop is the background eviction loop: under space pressure it
+// evicts LRU entries one by one at the configured deletion rate, so a write
+// burst doesn't turn into an IO spike of mass unlinks.
+func (provider *Simplefs) evictionLoop() {
+	interval := time.Second
+	if provider.evictionsPerSecond > 0 {
+		interval = time.Second / time.Duration(provider.evictionsPerSecond)
+	}
+
+	for range provider.evictWake {
+		for provider.overBudget() {
+			if _, ok := provider.evictOne(); !ok {
+				break // no evictable entry left
+			}
+
+			time.Sleep(interval)
+		}
+
+		// Past the entry count high watermark, sweep down to the low
+		// watermark with the same policy.
+		if provider.overHighWatermark() {
+			for provider.cache.Len() > provider.lowWatermark {
+				if _, ok := provider.evictOne(); !ok {
+					break
+				}
+
+				time.Sleep(interval)
+			}
+		}
+	}
+}
+
+// when_full policy values: evict old entries when the cache is full, reject
+// new writes, or reject only large entries while evicting for small ones.
+const (
+	whenFullEvict       = "evict"
+	whenFullReject      = "reject"
+	whenFullRejectLarge = "reject-large"
+)
+
+// rejectLargeDivisor defines what counts as a "large entry" under the
+// reject-large policy: writes over that fraction of directory_size are
+// rejected when the cache is full.
+const rejectLargeDivisor = 100
+
+// admitWrite applies the when_full policy before a write and returns whether
+// the write is admitted. evict (the default) keeps the historical behavior:
+// admit and evict as needed; reject refuses every new write when full;
+// reject-large only refuses large entries, small ones still evict for room.
+func (provider *Simplefs) admitWrite(size int64) bool {
+	if provider.directorySize <= 0 || provider.whenFull == whenFullEvict {
+		provider.ensureCapacity(size)
+
+		return true
+	}
+
+	provider.mu.Lock()
+	projected := provider.actualSize + size
+	provider.mu.Unlock()
+
+	if projected <= provider.directorySize {
+		return true
+	}
+
+	if provider.whenFull == whenFullReject || size >= provider.directorySize/rejectLargeDivisor {
+		provider.rejectedFills.Add(1)
+
+		return false
+	}
+
+	provider.whenFullEvicts.Add(1)
+	provider.ensureCapacity(size)
+
+	return true
+}
+
+// diskUsage rounds a file size up to the configured file system block size,
+// so actualSize tracks the real disk usage du would report instead of the sum
+// of byte lengths.
+func (provider *Simplefs) diskUsage(size int64) int64 {
+	if provider.blockSize <= 0 {
+		return size
+	}
+
+	if size == 0 {
+		return provider.blockSize // an empty file still costs a directory entry and an inode
+	}
+
+	return (size + provider.blockSize - 1) / provider.blockSize * provider.blockSize
+}
+
+// overHighWatermark reports whether the entry count exceeds the configured
+// high watermark.
+func (provider *Simplefs) overHighWatermark() bool {
+	return provider.highWatermark > 0 && provider.cache.Len() > provider.highWatermark
+}
+
+// ensureCapacity checks the space pressure before a write. Within the soft
+// overshoot allowance the write goes straight through and the background
+// evictor reclaims gradually; only past the hard limit is space reclaimed
+// synchronously.
+func (provider *Simplefs) ensureCapacity(size int64) {
+	if provider.directorySize <= 0 {
+		return
+	}
+
+	provider.mu.Lock()
+	projected := provider.actualSize + size
+	provider.mu.Unlock()
+
+	if projected <= provider.directorySize {
+		return
+	}
+
+	// Within the soft overshoot allowance: write as usual, wake up the
+	// background evictor.
+	if projected <= provider.directorySize+provider.evictionOvershoot {
+		provider.requestEviction()
+
+		return
+	}
+
+	// Past the hard limit: reclaim synchronously until back under the soft limit.
+	provider.evictUntilFree(size)
+
+	provider.requestEviction()
+}
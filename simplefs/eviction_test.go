@@ -0,0 +1,149 @@
+package simplefs
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darkweak/storages/core"
+)
+
+// nopLogger is the no-op logger used by the tests.
+type nopLogger struct{}
+
+func (nopLogger) Debug(_ ...interface{})            {}
+func (nopLogger) Info(_ ...interface{})             {}
+func (nopLogger) Warn(_ ...interface{})             {}
+func (nopLogger) Error(_ ...interface{})            {}
+func (nopLogger) DPanic(_ ...interface{})           {}
+func (nopLogger) Panic(_ ...interface{})            {}
+func (nopLogger) Fatal(_ ...interface{})            {}
+func (nopLogger) Debugf(_ string, _ ...interface{}) {}
+func (nopLogger) Infof(_ string, _ ...interface{})  {}
+func (nopLogger) Warnf(_ string, _ ...interface{})  {}
+func (nopLogger) Errorf(_ string, _ ...interface{}) {}
+func (nopLogger) DPanicf(_ string, _ ...interface{}) {
+}
+func (nopLogger) Panicf(_ string, _ ...interface{}) {}
+func (nopLogger) Fatalf(_ string, _ ...interface{}) {}
+
+// newTestProvider creates a test provider bounded by a small directory budget.
+func newTestProvider(t *testing.T, directorySize int64) *Simplefs {
+	t.Helper()
+
+	storer, err := Factory(core.CacheProvider{
+		Path: t.TempDir(),
+		Configuration: map[string]interface{}{
+			"compression":    "none",
+			"directory_size": directorySize,
+		},
+	}, nopLogger{}, time.Hour)
+	if err != nil {
+		t.Fatalf("impossible to create the provider: %v", err)
+	}
+
+	provider, ok := storer.(*Simplefs)
+	if !ok {
+		t.Fatalf("unexpected provider type %T", storer)
+	}
+
+	if err := provider.Init(); err != nil {
+		t.Fatalf("impossible to initialize the provider: %v", err)
+	}
+
+	return provider
+}
+
+// fillEntry writes an entry of the given size through SetMultiLevel.
+func fillEntry(t *testing.T, provider *Simplefs, key string, size int) {
+	t.Helper()
+
+	payload := bytes.Repeat([]byte{'x'}, size)
+
+	if err := provider.SetMultiLevel(key, key, payload, http.Header{}, "", time.Hour, key); err != nil {
+		t.Fatalf("impossible to write the key %s: %v", key, err)
+	}
+}
+
+// TestEvictUntilFreeReclaimsSpace checks that the eviction removes entries by
+// key and frees enough space, instead of stopping after a single deletion.
+func TestEvictUntilFreeReclaimsSpace(t *testing.T) {
+	directorySize := int64(64 << 10)
+	provider := newTestProvider(t, directorySize)
+
+	entrySize := 8 << 10
+	for i := 0; i < 32; i++ {
+		fillEntry(t, provider, fmt.Sprintf("GET-http-test.local-/entry/%d", i), entrySize)
+	}
+
+	provider.evictUntilFree(0)
+
+	provider.mu.Lock()
+	actualSize := provider.actualSize
+	provider.mu.Unlock()
+
+	if actualSize > directorySize {
+		t.Fatalf("the actual size %d still exceeds the budget %d after the eviction", actualSize, directorySize)
+	}
+
+	// The evicted entries must leave the cache and their files must be removed.
+	remaining := 0
+
+	for i := 0; i < 32; i++ {
+		key := fmt.Sprintf("GET-http-test.local-/entry/%d", i)
+		if provider.cache.Get(key) != nil {
+			remaining++
+		}
+	}
+
+	if remaining == 0 {
+		t.Fatal("the eviction should not empty the whole cache")
+	}
+
+	if rebuilt := provider.scanDirSize(provider.path, true); rebuilt > directorySize+provider.diskUsage(0) {
+		t.Fatalf("the disk usage %d does not match the budget %d, some files were not removed", rebuilt, directorySize)
+	}
+}
+
+// TestEvictionUnderConcurrentSetPressure checks that the size accounting does
+// not drift under concurrent write pressure and that the synchronous eviction
+// brings the usage back under the budget.
+func TestEvictionUnderConcurrentSetPressure(t *testing.T) {
+	directorySize := int64(128 << 10)
+	provider := newTestProvider(t, directorySize)
+
+	var wg sync.WaitGroup
+
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+
+		go func(worker int) {
+			defer wg.Done()
+
+			for i := 0; i < 20; i++ {
+				key := fmt.Sprintf("GET-http-test.local-/w%d/entry/%d", worker, i)
+				payload := bytes.Repeat([]byte{'y'}, 8<<10)
+				_ = provider.SetMultiLevel(key, key, payload, http.Header{}, "", time.Hour, key)
+			}
+		}(worker)
+	}
+
+	wg.Wait()
+
+	provider.evictUntilFree(0)
+
+	provider.mu.Lock()
+	actualSize := provider.actualSize
+	provider.mu.Unlock()
+
+	if actualSize > directorySize {
+		t.Fatalf("the actual size %d exceeds the budget %d after the concurrent writes", actualSize, directorySize)
+	}
+
+	if actualSize < 0 {
+		t.Fatalf("the size accounting went negative: %d", actualSize)
+	}
+}
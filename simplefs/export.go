@@ -0,0 +1,184 @@
+package simplefs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/jellydator/ttlcache/v3"
+)
+
+// ExportEntry is one record of the static export index (index.json),
+// describing an exported entry and where it landed on disk.
+type ExportEntry struct {
+	Key         string    `json:"key"`                    // cache key
+	File        string    `json:"file"`                   // file path relative to the export directory
+	Status      int       `json:"status,omitempty"`       // response status code (0 when unparseable)
+	ContentType string    `json:"content_type,omitempty"` // response Content-Type
+	Size        int64     `json:"size"`                   // exported body bytes
+	ExpiresAt   time.Time `json:"expires_at"`             // cache expiry time
+}
+
+// ExportReport summarizes one static export run.
+type ExportReport struct {
+	Entries int   `json:"entries"` // exported entries
+	Skipped int   `json:"skipped"` // entries skipped for an unusual key shape or missing content
+	Bytes   int64 `json:"bytes"`   // total exported body bytes
+}
+
+// ExportStatic exports the cache content as a browsable static snapshot: the
+// decompressed response bodies are written under dir in a host/path shape and
+// an index.json records the metadata of every entry, so operators can inspect
+// what the cache would actually serve with ordinary tools (a browser, grep,
+// diff). The export goes through the same read path as Get, so decryption and
+// decompression are transparent to it.
+func (provider *Simplefs) ExportStatic(dir string) (ExportReport, error) {
+	report := ExportReport{}
+
+	if dir == "" {
+		return report, fmt.Errorf("no export directory given")
+	}
+
+	index := []ExportEntry{}
+	used := map[string]int{}
+
+	var failure error
+
+	provider.cache.Range(func(item *ttlcache.Item[string, []byte]) bool {
+		if strings.Contains(item.Key(), core.MappingKeyPrefix) {
+			return true // mapping keys are not entry bodies
+		}
+
+		if _, isFile := provider.entryMeta(item); !isFile {
+			return true // non-file entries (raw values) are not exported
+		}
+
+		relative, ok := exportFilePath(item.Key())
+		if !ok {
+			report.Skipped++
+
+			return true
+		}
+
+		// Variants or query strings may map to the same file name, append a
+		// sequence number so they don't overwrite each other.
+		used[relative]++
+		if n := used[relative]; n > 1 {
+			relative = fmt.Sprintf("%s.%d", relative, n)
+		}
+
+		// Go through the full read path to get the decrypted and
+		// decompressed response bytes.
+		content := provider.Get(item.Key())
+		if len(content) == 0 {
+			report.Skipped++
+
+			return true
+		}
+
+		entry := ExportEntry{
+			Key:       item.Key(),
+			File:      relative,
+			ExpiresAt: item.ExpiresAt(),
+		}
+
+		body := content
+
+		// Entries store a full HTTP response dump; parse out the body and
+		// the metadata. When parsing fails the raw dump is exported as-is,
+		// still inspectable.
+		if response, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(content)), nil); err == nil {
+			if parsed, err := io.ReadAll(response.Body); err == nil {
+				body = parsed
+			}
+
+			_ = response.Body.Close()
+
+			entry.Status = response.StatusCode
+			entry.ContentType = response.Header.Get("Content-Type")
+		}
+
+		target := filepath.Join(dir, relative)
+
+		if err := provider.fs.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			failure = fmt.Errorf("impossible to create the export directory %s: %w", filepath.Dir(target), err)
+
+			return false
+		}
+
+		if err := provider.fs.WriteFile(target, body, 0o644); err != nil {
+			failure = fmt.Errorf("impossible to write the export file %s: %w", target, err)
+
+			return false
+		}
+
+		entry.Size = int64(len(body))
+		report.Entries++
+		report.Bytes += entry.Size
+		index = append(index, entry)
+
+		return true
+	})
+
+	if failure != nil {
+		return report, failure
+	}
+
+	payload, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return report, fmt.Errorf("impossible to encode the export index: %w", err)
+	}
+
+	if err := provider.fs.MkdirAll(dir, 0o755); err != nil {
+		return report, fmt.Errorf("impossible to create the export directory %s: %w", dir, err)
+	}
+
+	if err := provider.fs.WriteFile(filepath.Join(dir, "index.json"), payload, 0o644); err != nil {
+		return report, fmt.Errorf("impossible to write the export index: %w", err)
+	}
+
+	provider.logger.Infof("Static export done: %d entries (%d bytes) written to %s, %d skipped", report.Entries, report.Bytes, dir, report.Skipped)
+
+	return report, nil
+}
+
+// exportFilePath converts a cache key (method-scheme-host-path) to a relative
+// file path under the export directory. Directory-shaped paths get an
+// index.html appended, and path.Clean keeps ".." from escaping the export
+// directory. The host segment comes from the request Host header and is just
+// as untrusted: a host containing path separators or shaped like ".." would
+// escape the export directory, so it is rejected outright.
+func exportFilePath(key string) (string, bool) {
+	parts := strings.SplitN(key, "-", 4)
+	if len(parts) < 4 || parts[2] == "" {
+		return "", false // the key is not the usual method-scheme-host-path shape
+	}
+
+	host := parts[2]
+	if host == "." || host == ".." || strings.ContainsAny(host, `/\`) {
+		return "", false // the host segment would escape the export directory
+	}
+
+	urlPath := parts[3]
+
+	// Drop the query string, keeping it in a static file name only makes the
+	// export hard to browse.
+	if idx := strings.IndexByte(urlPath, '?'); idx >= 0 {
+		urlPath = urlPath[:idx]
+	}
+
+	cleaned := path.Clean("/" + urlPath)
+	if strings.HasSuffix(urlPath, "/") || cleaned == "/" {
+		cleaned = cleaned + "/index.html"
+	}
+
+	return filepath.Join(host, filepath.FromSlash(strings.TrimPrefix(cleaned, "/"))), true
+}
@@ -0,0 +1,173 @@
+package simplefs
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// preallocateThreshold is the write size above which disk space is preallocated first.
+const preallocateThreshold = 1 << 20
+
+// errTmpfileUnsupported reports that the platform or file system doesn't
+// support O_TMPFILE writes.
+var errTmpfileUnsupported = errors.New("O_TMPFILE writes are not supported on this platform")
+
+// errUringUnsupported reports that the build or the kernel doesn't support
+// io_uring submission.
+var errUringUnsupported = errors.New("io_uring is not supported in this build")
+
+// GetFile returns the backing *os.File of the entry for the key along with
+// the compression method actually used by the file content. For uncompressed
+// entries, or when the caller can pass the compression encoding through
+// as-is, Caddy can send the cached body zero-copy via sendfile/splice without
+// a userspace copy. The method is identified from the entry file header (not
+// the global configuration) and the file offset is already past the header on
+// return, so the caller sends from the current offset. Dictionary-compressed
+// entries can't be passed through and return an error.
+// The caller is responsible for closing the returned file.
+func (provider *Simplefs) GetFile(key string) (*os.File, string, error) {
+	// With at-rest encryption the disk bytes are ciphertext, useless to the caller.
+	if provider.encryption != nil {
+		return nil, "", errors.New("impossible to serve the disk file directly with at-rest encryption enabled")
+	}
+
+	item := provider.cache.Get(key)
+	if item == nil {
+		return nil, "", errors.New("impossible to find the key " + key + " in Simplefs")
+	}
+
+	file, err := os.Open(string(item.Value()))
+	if err != nil {
+		return nil, "", err
+	}
+
+	header := make([]byte, entryHeaderLen)
+
+	read, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		_ = file.Close()
+
+		return nil, "", err
+	}
+
+	if method, _, ok := splitEntryHeader(header[:read]); ok {
+		if method == methodGzipDict {
+			// A dictionary deflate stream can't be decoded away from its
+			// dictionary, so it can't be sent to the client as-is.
+			_ = file.Close()
+
+			return nil, "", errors.New("dictionary-compressed entries cannot be passed through")
+		}
+
+		// The file offset stops right after the header, the body starts at
+		// the current offset.
+		return file, method, nil
+	}
+
+	// Legacy format without a header: sniff the method from the magic bytes
+	// and rewind to the beginning.
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		_ = file.Close()
+
+		return nil, "", err
+	}
+
+	return file, detectCompression(header[:read], provider.compression), nil
+}
+
+// writeEntryFile writes the data to a cache file. It prefers an anonymous
+// O_TMPFILE write (Linux) and falls back to a same-directory temporary file
+// with an atomic rename, so readers never observe a half-written file.
+func (provider *Simplefs) writeEntryFile(path string, data []byte) error {
+	// With a non-default file system injected (tests, fault injection) go
+	// through the interface path; O_TMPFILE and the temporary file rename
+	// only make sense on a real disk.
+	if _, isOS := provider.fs.(osFS); !isOS {
+		return provider.fs.WriteFile(path, data, 0o644)
+	}
+
+	err := provider.writeEntryFileTmpfile(path, data)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, errTmpfileUnsupported) {
+		provider.logger.Debugf("O_TMPFILE write to %s failed, falling back to a temporary file: %v", path, err)
+	}
+
+	return provider.writeEntryFileRename(path, data)
+}
+
+// readFileRaw performs one low-level file read. With a non-default file
+// system injected (tests, fault injection) it goes through the interface
+// path; built with the simplefs_iouring tag on a supporting kernel the read
+// is submitted through io_uring, otherwise a plain ReadFile.
+func (provider *Simplefs) readFileRaw(path string) ([]byte, error) {
+	if _, isOS := provider.fs.(osFS); isOS {
+		content, err := provider.readEntryFileUring(path)
+		if !errors.Is(err, errUringUnsupported) {
+			return content, err
+		}
+	}
+
+	return provider.fs.ReadFile(path)
+}
+
+// writeFileData writes the entry data to an already opened file. Built with
+// the simplefs_iouring tag on a supporting kernel the write is submitted
+// through io_uring, otherwise a plain write.
+func (provider *Simplefs) writeFileData(file *os.File, data []byte) error {
+	if err := provider.writeEntryFileUring(file, data); !errors.Is(err, errUringUnsupported) {
+		return err
+	}
+
+	_, err := file.Write(data)
+
+	return err
+}
+
+// writeEntryFileRename writes to a same-directory temporary file first, then
+// atomically renames it to the target path.
+func (provider *Simplefs) writeEntryFileRename(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), ".simplefs-*")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := file.Name()
+
+	if len(data) >= preallocateThreshold {
+		// A failed preallocation is not fatal, keep writing normally.
+		if err := preallocate(file, int64(len(data))); err != nil {
+			provider.logger.Debugf("Impossible to preallocate %d bytes for the file %s: %v", len(data), tmpPath, err)
+		}
+	}
+
+	if err := provider.writeFileData(file, data); err != nil {
+		_ = file.Close()
+		_ = os.Remove(tmpPath)
+
+		return err
+	}
+
+	// With sync_writes enabled, force the data to disk so the renamed file
+	// content survives a crash.
+	if provider.syncWrites {
+		if err := file.Sync(); err != nil {
+			_ = file.Close()
+			_ = os.Remove(tmpPath)
+
+			return err
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,77 @@
+package simplefs
+
+import (
+	"sync"
+	"time"
+)
+
+// ghostList remembers the recently evicted keys (the keys only, no body). A
+// key requested again shortly after its eviction means the eviction was a
+// mistake — an undersized directory_size or an over-strict admission filter.
+// These events feed the statistics for tuning and can be fed back to the
+// admission filter to raise the priority of the next write of the key. nil
+// means disabled.
+type ghostList struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[string]time.Time // key to eviction time
+	order    []string             // in eviction order, the oldest goes first when full
+}
+
+// newGhostList creates the ghost list, a non-positive capacity returns nil
+// (disabled).
+func newGhostList(capacity int, ttl time.Duration) *ghostList {
+	if capacity <= 0 {
+		return nil
+	}
+
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+
+	return &ghostList{capacity: capacity, ttl: ttl, entries: map[string]time.Time{}}
+}
+
+// add records a freshly evicted key.
+func (ghosts *ghostList) add(key string) {
+	if ghosts == nil {
+		return
+	}
+
+	ghosts.mu.Lock()
+	defer ghosts.mu.Unlock()
+
+	if _, exists := ghosts.entries[key]; !exists {
+		ghosts.order = append(ghosts.order, key)
+	}
+
+	ghosts.entries[key] = time.Now()
+
+	// Drop the oldest records when over capacity.
+	for len(ghosts.entries) > ghosts.capacity && len(ghosts.order) > 0 {
+		oldest := ghosts.order[0]
+		ghosts.order = ghosts.order[1:]
+		delete(ghosts.entries, oldest)
+	}
+}
+
+// hit checks whether a key is within the ghost list time window, removing it
+// and returning true on a hit.
+func (ghosts *ghostList) hit(key string) bool {
+	if ghosts == nil {
+		return false
+	}
+
+	ghosts.mu.Lock()
+	defer ghosts.mu.Unlock()
+
+	evictedAt, found := ghosts.entries[key]
+	if !found {
+		return false
+	}
+
+	delete(ghosts.entries, key)
+
+	return time.Since(evictedAt) <= ghosts.ttl
+}
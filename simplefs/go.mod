@@ -9,11 +9,8 @@ require (
 	github.com/dustin/go-humanize v1.0.1
 	github.com/jellydator/ttlcache/v3 v3.3.0
 	github.com/pierrec/lz4/v4 v4.1.22
-)
-
-require (
-	golang.org/x/sync v0.13.0 // indirect
-	google.golang.org/protobuf v1.36.6 // indirect
+	golang.org/x/sync v0.13.0
+	google.golang.org/protobuf v1.36.6
 )
 
 
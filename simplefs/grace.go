@@ -0,0 +1,86 @@
+package simplefs
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/darkweak/storages/core"
+)
+
+// SetOriginHealthy flags the origin health. With the origin flagged
+// unhealthy, GetMultiLevel elects already-expired entries within the grace
+// window after the regular election came up empty, implementing the
+// stale-if-error semantic at the storage level.
+func (provider *Simplefs) SetOriginHealthy(healthy bool) {
+	provider.originDown.Store(!healthy)
+
+	if healthy {
+		provider.logger.Info("The origin is flagged healthy, back to the regular election")
+	} else {
+		provider.logger.Warn("The origin is flagged unhealthy, enabling the grace mode")
+	}
+}
+
+// OriginDown reports whether the origin is currently flagged unhealthy.
+func (provider *Simplefs) OriginDown() bool {
+	return provider.originDown.Load()
+}
+
+// graceMatchesVariedHeaders mirrors the Vary matching of the core election.
+func graceMatchesVariedHeaders(req *http.Request, keyItem *core.KeyIndex) bool {
+	for hname, hval := range keyItem.GetVariedHeaders() {
+		if req.Header.Get(hname) != strings.Join(hval.GetHeaderValue(), ", ") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// graceElection elects entries past their stale deadline within the grace
+// window. Only variants expired for at most maxGrace qualify, never serving
+// stale content indefinitely.
+func (provider *Simplefs) graceElection(item []byte, req *http.Request) *http.Response {
+	mapping, err := core.DecodeMapping(item)
+	if err != nil {
+		return nil
+	}
+
+	for keyName, keyItem := range mapping.GetMapping() {
+		if !graceMatchesVariedHeaders(req, keyItem) {
+			continue
+		}
+
+		// Skip the entries not yet expired (the regular election already
+		// handled them) and the ones beyond the grace window.
+		sinceStale := core.Since(keyItem.GetStaleTime().AsTime())
+		if sinceStale < 0 || sinceStale > provider.maxGrace {
+			continue
+		}
+
+		body := provider.Get(keyName)
+		if body == nil {
+			continue
+		}
+
+		response, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(body)), req)
+		if err != nil {
+			provider.logger.Errorf("Impossible to parse the response of the key %s in grace mode: %v", keyName, err)
+
+			continue
+		}
+
+		core.SetAgeHeader(response, keyItem.GetStoredAt().AsTime())
+		core.RecordDecision(req, "Hit", "grace")
+		core.RecordDecision(req, "Key", keyItem.GetRealKey())
+
+		provider.logger.Warnf("Grace mode: serving the entry of the key %s expired for %s", keyName, sinceStale.Truncate(time.Second))
+
+		return response
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package simplefs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httputil"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// ImportReport summarizes one static import run.
+type ImportReport struct {
+	Entries int   `json:"entries"` // imported entries
+	Skipped int   `json:"skipped"` // skipped files (e.g. index.json or read failures)
+	Bytes   int64 `json:"bytes"`   // total imported body bytes
+}
+
+// ImportStatic fills the cache from a directory of static files: the layout
+// matches ExportStatic (first level is the host name, the rest the URL path),
+// every file is wrapped into a synthetic 200 response and written through
+// SetMultiLevel with ttl as the TTL. Build artifacts can therefore pre-warm
+// the cache at deploy time, so the first wave of traffic never hits the origin.
+func (provider *Simplefs) ImportStatic(dir string, ttl time.Duration) (ImportReport, error) {
+	report := ImportReport{}
+
+	if dir == "" {
+		return report, fmt.Errorf("no import directory given")
+	}
+
+	hosts, err := provider.fs.ReadDir(dir)
+	if err != nil {
+		return report, fmt.Errorf("impossible to read the import directory %s: %w", dir, err)
+	}
+
+	for _, host := range hosts {
+		if !host.IsDir() {
+			report.Skipped++ // loose top-level files (e.g. index.json) are not entries
+
+			continue
+		}
+
+		if err := provider.importTree(filepath.Join(dir, host.Name()), host.Name(), "", ttl, &report); err != nil {
+			return report, err
+		}
+	}
+
+	provider.logger.Infof("Static import done: %d entries (%d bytes) from %s, %d skipped", report.Entries, report.Bytes, dir, report.Skipped)
+
+	return report, nil
+}
+
+// importTree recursively imports the files under fsDir, urlDir being the
+// matching URL path prefix.
+func (provider *Simplefs) importTree(fsDir, host, urlDir string, ttl time.Duration, report *ImportReport) error {
+	entries, err := provider.fs.ReadDir(fsDir)
+	if err != nil {
+		return fmt.Errorf("impossible to read the import directory %s: %w", fsDir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if err := provider.importTree(filepath.Join(fsDir, entry.Name()), host, urlDir+"/"+entry.Name(), ttl, report); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		content, err := provider.fs.ReadFile(filepath.Join(fsDir, entry.Name()))
+		if err != nil {
+			provider.logger.Warnf("Skipping the unreadable import file %s: %v", filepath.Join(fsDir, entry.Name()), err)
+			report.Skipped++
+
+			continue
+		}
+
+		// index.html maps back to a directory-shaped URL, mirroring the
+		// ExportStatic naming rule.
+		urlPath := path.Clean("/" + urlDir + "/" + entry.Name())
+		if entry.Name() == "index.html" {
+			urlPath = path.Clean("/" + urlDir)
+			if urlPath != "/" {
+				urlPath += "/"
+			}
+		}
+
+		key := "GET-https-" + host + "-" + urlPath
+
+		dump, err := synthesizeResponse(entry.Name(), content)
+		if err != nil {
+			provider.logger.Warnf("Impossible to synthesize a response for the import file %s: %v", entry.Name(), err)
+			report.Skipped++
+
+			continue
+		}
+
+		if err := provider.SetMultiLevel(key, key, dump, nil, "", ttl, key); err != nil {
+			return fmt.Errorf("impossible to import the key %s: %w", key, err)
+		}
+
+		report.Entries++
+		report.Bytes += int64(len(content))
+	}
+
+	return nil
+}
+
+// synthesizeResponse wraps a static file into a 200 response dump the
+// election can serve directly, the Content-Type inferred from the extension.
+func synthesizeResponse(name string, content []byte) ([]byte, error) {
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+
+	response := &http.Response{
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{contentType}},
+		Body:          io.NopCloser(bytes.NewReader(content)),
+		ContentLength: int64(len(content)),
+	}
+
+	return httputil.DumpResponse(response, true)
+}
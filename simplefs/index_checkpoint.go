@@ -0,0 +1,112 @@
+package simplefs
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/jellydator/ttlcache/v3"
+)
+
+// indexCheckpointFile is the file name of the key index checkpoint in the
+// storage directory. The ttlcache index only lives in memory; without this
+// checkpoint the files survive a restart but their keys and TTLs are lost, so
+// the entries can neither be hit nor ever be evicted.
+const indexCheckpointFile = ".index-checkpoint.json"
+
+// writeIndexCheckpoint atomically persists every current entry key and its
+// expiry time.
+func (provider *Simplefs) writeIndexCheckpoint() {
+	index := map[string]time.Time{}
+
+	provider.cache.Range(func(item *ttlcache.Item[string, []byte]) bool {
+		if strings.HasPrefix(item.Key(), core.MappingKeyPrefix) {
+			return true // mappings are persisted separately through the mappings directory
+		}
+
+		index[item.Key()] = item.ExpiresAt()
+
+		return true
+	})
+
+	payload, err := json.Marshal(index)
+	if err != nil {
+		return
+	}
+
+	path := filepath.Join(provider.path, indexCheckpointFile)
+	tmpPath := path + ".tmp"
+
+	if err := provider.fs.WriteFile(tmpPath, payload, 0o644); err != nil {
+		provider.logger.Errorf("Impossible to write the key index checkpoint %s: %v", tmpPath, err)
+
+		return
+	}
+
+	if err := provider.fs.Rename(tmpPath, path); err != nil {
+		provider.logger.Errorf("Impossible to replace the key index checkpoint %s: %v", path, err)
+	}
+}
+
+// loadIndexCheckpoint restores the key index at startup: entries still within
+// their lifetime whose file exists are re-registered with their remaining
+// TTL, the files of expired entries are cleaned up right away.
+func (provider *Simplefs) loadIndexCheckpoint() {
+	path := filepath.Join(provider.path, indexCheckpointFile)
+
+	payload, err := provider.fs.ReadFile(path)
+	if err != nil {
+		return // no checkpoint file, skip
+	}
+
+	index := map[string]time.Time{}
+	if err := json.Unmarshal(payload, &index); err != nil {
+		provider.logger.Warnf("Impossible to parse the key index checkpoint %s: %v", path, err)
+
+		return
+	}
+
+	restored := 0
+
+	for key, expiresAt := range index {
+		filePath := provider.entryFilePath(key)
+
+		remaining := core.Until(expiresAt)
+		if remaining <= 0 {
+			// An entry expired during the previous run is not reachable anymore, remove the leftover file.
+			_ = provider.fs.Remove(filePath)
+
+			continue
+		}
+
+		if _, err := provider.fs.Stat(filePath); err != nil {
+			continue // the file is gone, skip
+		}
+
+		_ = provider.setEntryFile(key, filePath, remaining)
+		restored++
+	}
+
+	if restored > 0 {
+		provider.logger.Infof("Restored %d entries from the key index checkpoint", restored)
+	}
+}
+
+// startIndexCheckpoint starts the periodic key index checkpoint loop, a zero
+// interval disables it.
+func (provider *Simplefs) startIndexCheckpoint(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			provider.writeIndexCheckpoint()
+		}
+	}()
+}
@@ -0,0 +1,150 @@
+package simplefs
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/darkweak/storages/core"
+)
+
+// Supported key escaping strategies. The PathEscape output is unfriendly to
+// some object stores and to keys containing % sequences, hence the base64url
+// and hex alternatives.
+const (
+	keyEncodingPathEscape = "pathescape"
+	keyEncodingBase64URL  = "base64url"
+	keyEncodingHex        = "hex"
+	keyEncodingHash       = "hash"
+)
+
+// maxFileNameLength is the conservative cap on a single file name. Beyond it
+// the hashed file name takes over, staying compatible with the 255-byte limit
+// of filesystems like NTFS and ext4.
+const maxFileNameLength = 200
+
+// windowsMaxPath is the traditional Windows path length limit without the
+// long-path prefix.
+const windowsMaxPath = 260
+
+// windowsReservedNames lists the reserved device names that cannot be used as
+// file names on Windows.
+var windowsReservedNames = map[string]struct{}{
+	"con": {}, "prn": {}, "aux": {}, "nul": {},
+	"com1": {}, "com2": {}, "com3": {}, "com4": {}, "com5": {},
+	"com6": {}, "com7": {}, "com8": {}, "com9": {},
+	"lpt1": {}, "lpt2": {}, "lpt3": {}, "lpt4": {}, "lpt5": {},
+	"lpt6": {}, "lpt7": {}, "lpt8": {}, "lpt9": {},
+}
+
+// isWindowsReserved checks whether the file name (ignoring the extension and
+// the case) collides with a Windows reserved device name.
+func isWindowsReserved(name string) bool {
+	base := strings.ToLower(name)
+	if dot := strings.IndexByte(base, '.'); dot >= 0 {
+		base = base[:dot]
+	}
+
+	_, reserved := windowsReservedNames[base]
+
+	return reserved
+}
+
+// hashFileName returns the SHA-256 hex file name of a key, the last-resort
+// strategy for collisions and oversized names.
+func hashFileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// encodeFileName converts a cache key into a file name following the
+// configured escaping strategy.
+func (provider *Simplefs) encodeFileName(key string) string {
+	switch provider.keyEncoding {
+	case keyEncodingBase64URL:
+		return base64.RawURLEncoding.EncodeToString([]byte(key))
+	case keyEncodingHex:
+		return hex.EncodeToString([]byte(key))
+	case keyEncodingHash:
+		return hashFileName(key)
+	default:
+		return url.PathEscape(key)
+	}
+}
+
+// hashedFileNameLength is the fixed length of a SHA-256 hex file name.
+const hashedFileNameLength = 64
+
+// platformMaxPath returns the path length limit of the current platform.
+func platformMaxPath() int {
+	if runtime.GOOS == "windows" {
+		return windowsMaxPath
+	}
+
+	return 4096 // Linux PATH_MAX
+}
+
+// validateKeyEncodingForPath checks at Factory time whether the configured
+// directory plus the worst-case file name exceeds the platform limit, falling
+// back automatically to the hashed file name strategy instead of failing at
+// write time.
+func validateKeyEncodingForPath(storagePath, keyEncoding string, logger core.Logger) string {
+	absolute, err := filepath.Abs(storagePath)
+	if err != nil {
+		absolute = storagePath
+	}
+
+	budget := platformMaxPath() - len(absolute) - len(string(filepath.Separator))
+
+	// In the worst case safeFileName produces a maxFileNameLength-long file name.
+	if keyEncoding != keyEncodingHash && budget < maxFileNameLength {
+		if budget >= hashedFileNameLength {
+			logger.Warnf("The storage path %s is too deep to fit the worst-case %s-encoded file name, falling back to hashed file names", absolute, keyEncoding)
+
+			return keyEncodingHash
+		}
+	}
+
+	if budget < hashedFileNameLength {
+		logger.Errorf("The storage path %s is too deep, even hashed file names exceed the platform path limit %d", absolute, platformMaxPath())
+	}
+
+	return keyEncoding
+}
+
+// safeFileName falls back to the hashed file name when the escaped name is
+// too long or collides with a Windows reserved name.
+func (provider *Simplefs) safeFileName(key string) string {
+	name := provider.encodeFileName(key)
+	if len(name) > maxFileNameLength || isWindowsReserved(name) {
+		return hashFileName(key)
+	}
+
+	return name
+}
+
+// entryFilePath joins the storage directory, the shard subdirectory and the
+// safe file name, adding the `\\?\` prefix on Windows for oversized paths.
+func (provider *Simplefs) entryFilePath(key string) string {
+	name := provider.safeFileName(key)
+	joined := filepath.Join(provider.path, provider.shardDir(name), name)
+
+	// With the sharded layout the subdirectories are created on demand, so
+	// writers never care whether the directory exists.
+	if provider.shardDepth > 0 {
+		_ = provider.fs.MkdirAll(filepath.Dir(joined), 0o755)
+	}
+
+	if runtime.GOOS == "windows" && len(joined) >= windowsMaxPath && !strings.HasPrefix(joined, `\\?\`) {
+		if absolute, err := filepath.Abs(joined); err == nil {
+			joined = `\\?\` + absolute
+		}
+	}
+
+	return joined
+}
@@ -0,0 +1,96 @@
+package simplefs
+
+import (
+	"sync"
+	"time"
+
+	"github.com/darkweak/storages/core"
+)
+
+// opLatency aggregates the latency statistics of one disk operation type.
+type opLatency struct {
+	count      int64
+	totalNanos int64
+	maxNanos   int64
+}
+
+// LatencySnapshot is the exposed latency statistics of one operation type.
+type LatencySnapshot struct {
+	Count        int64 `json:"count"`         // number of operations
+	AverageNanos int64 `json:"average_nanos"` // average latency in nanoseconds
+	MaxNanos     int64 `json:"max_nanos"`     // maximum latency in nanoseconds
+}
+
+// latencyTracker records the latency of each disk operation type and logs a
+// warning for the slow ones above the threshold, helping operators spot disk
+// failures or NFS stalls that only show up at p99.
+type latencyTracker struct {
+	mu        sync.Mutex
+	threshold time.Duration
+	logger    core.Logger
+	ops       map[string]*opLatency
+}
+
+// newLatencyTracker creates the latency tracker, threshold is the slow
+// operation logging threshold.
+func newLatencyTracker(threshold time.Duration, logger core.Logger) *latencyTracker {
+	return &latencyTracker{
+		threshold: threshold,
+		logger:    logger,
+		ops:       map[string]*opLatency{},
+	}
+}
+
+// observe records the latency of one disk operation. Operations above the
+// threshold are logged with the key hash and the size, never leaking the full
+// cache key into the log.
+func (tracker *latencyTracker) observe(operation, key string, size int, elapsed time.Duration) {
+	tracker.mu.Lock()
+
+	latency := tracker.ops[operation]
+	if latency == nil {
+		latency = &opLatency{}
+		tracker.ops[operation] = latency
+	}
+
+	latency.count++
+	latency.totalNanos += elapsed.Nanoseconds()
+
+	if elapsed.Nanoseconds() > latency.maxNanos {
+		latency.maxNanos = elapsed.Nanoseconds()
+	}
+
+	tracker.mu.Unlock()
+
+	if tracker.threshold > 0 && elapsed >= tracker.threshold {
+		tracker.logger.Warnf("Slow %s operation: key hash %s, %d bytes, took %v", operation, hashFileName(key)[:16], size, elapsed)
+	}
+}
+
+// snapshot returns the current latency statistics of every operation type.
+func (tracker *latencyTracker) snapshot() map[string]LatencySnapshot {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	snapshots := make(map[string]LatencySnapshot, len(tracker.ops))
+
+	for operation, latency := range tracker.ops {
+		snapshot := LatencySnapshot{
+			Count:    latency.count,
+			MaxNanos: latency.maxNanos,
+		}
+
+		if latency.count > 0 {
+			snapshot.AverageNanos = latency.totalNanos / latency.count
+		}
+
+		snapshots[operation] = snapshot
+	}
+
+	return snapshots
+}
+
+// LatencyStats returns the disk latency statistics aggregated by operation type.
+func (provider *Simplefs) LatencyStats() map[string]LatencySnapshot {
+	return provider.latency.snapshot()
+}
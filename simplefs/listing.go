@@ -0,0 +1,129 @@
+package simplefs
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/jellydator/ttlcache/v3"
+)
+
+// ListOptions controls the sorting, filtering and pagination of the entry
+// listing, letting the admin endpoints find problematic entries quickly by
+// criteria like "about to expire" or "larger than 10MB".
+type ListOptions struct {
+	SortBy         string        // expiry, size or last_access, empty sorts by key
+	Desc           bool          // descending order
+	ExpiringWithin time.Duration // keep only the entries expiring within this duration, 0 disables the filter
+	MinSize        int64         // keep only the entries larger than this byte count, 0 disables the filter
+	Limit          int           // maximum number of returned entries, 0 means unlimited
+	Offset         int           // number of entries to skip
+}
+
+// accessTracker records the last read time of each key, feeding the
+// last-access sorting.
+type accessTracker struct {
+	mu    sync.Mutex
+	times map[string]time.Time
+}
+
+// touch updates the last access time of a key.
+func (tracker *accessTracker) touch(key string) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	if tracker.times == nil {
+		tracker.times = map[string]time.Time{}
+	}
+
+	tracker.times[key] = time.Now()
+}
+
+// last returns the last access time of a key, the zero value when unrecorded.
+func (tracker *accessTracker) last(key string) time.Time {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	return tracker.times[key]
+}
+
+// drop cleans the record when a key is deleted or evicted.
+func (tracker *accessTracker) drop(key string) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	delete(tracker.times, key)
+}
+
+// ListEntries returns the metadata of the entries matching the filters,
+// sorted and paginated following the options.
+func (provider *Simplefs) ListEntries(options ListOptions) []EntryMeta {
+	entries := []EntryMeta{}
+	now := time.Now()
+
+	provider.cache.Range(func(item *ttlcache.Item[string, []byte]) bool {
+		meta, isFile := provider.entryMeta(item)
+		if !isFile {
+			return true // mapping keys are not listed
+		}
+
+		if options.ExpiringWithin > 0 && meta.ExpiresAt.After(now.Add(options.ExpiringWithin)) {
+			return true
+		}
+
+		if options.MinSize > 0 && meta.Size < options.MinSize {
+			return true
+		}
+
+		meta.LastAccess = provider.access.last(meta.Key)
+		entries = append(entries, meta)
+
+		return true
+	})
+
+	sort.Slice(entries, func(i, j int) bool {
+		less := false
+
+		switch options.SortBy {
+		case "expiry":
+			less = entries[i].ExpiresAt.Before(entries[j].ExpiresAt)
+		case "size":
+			less = entries[i].Size < entries[j].Size
+		case "last_access":
+			less = entries[i].LastAccess.Before(entries[j].LastAccess)
+		default:
+			less = entries[i].Key < entries[j].Key
+		}
+
+		if options.Desc {
+			return !less
+		}
+
+		return less
+	})
+
+	if options.Offset > 0 {
+		if options.Offset >= len(entries) {
+			return []EntryMeta{}
+		}
+
+		entries = entries[options.Offset:]
+	}
+
+	if options.Limit > 0 && len(entries) > options.Limit {
+		entries = entries[:options.Limit]
+	}
+
+	return entries
+}
+
+// touchAccess records the read time of a non-mapping key.
+func (provider *Simplefs) touchAccess(key string) {
+	if strings.Contains(key, core.MappingKeyPrefix) {
+		return
+	}
+
+	provider.access.touch(key)
+}
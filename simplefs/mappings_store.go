@@ -0,0 +1,193 @@
+package simplefs
+
+import (
+	"encoding/binary"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/jellydator/ttlcache/v3"
+)
+
+// mappingsDirName is the dedicated subdirectory for mapping values under the
+// storage directory. Keeping mappings apart from bodies means their frequent
+// small writes never interleave with large body IO, and GetMultiLevel works
+// right after a restart without waiting for a repopulation.
+const mappingsDirName = "mappings"
+
+// mappingFilePath returns the persistence file path of a mapping key.
+func (provider *Simplefs) mappingFilePath(mappingKey string) string {
+	return filepath.Join(provider.path, mappingsDirName, provider.safeFileName(mappingKey))
+}
+
+// encodeMappingEnvelope packs a mapping key and its value into the
+// persistence format: 4 big-endian key-length bytes + key + raw mapping
+// bytes. The file name is escaped (possibly hashed) and cannot be reversed
+// into the key, so the key is stored alongside the content.
+func encodeMappingEnvelope(mappingKey string, value []byte) []byte {
+	payload := make([]byte, 4+len(mappingKey)+len(value))
+	binary.BigEndian.PutUint32(payload, uint32(len(mappingKey))) //nolint:gosec
+	copy(payload[4:], mappingKey)
+	copy(payload[4+len(mappingKey):], value)
+
+	return payload
+}
+
+// decodeMappingEnvelope unpacks the persistence format, returning false on a
+// corrupted payload.
+func decodeMappingEnvelope(payload []byte) (string, []byte, bool) {
+	if len(payload) < 4 {
+		return "", nil, false
+	}
+
+	keyLength := int(binary.BigEndian.Uint32(payload))
+	if keyLength <= 0 || len(payload) < 4+keyLength {
+		return "", nil, false
+	}
+
+	return string(payload[4 : 4+keyLength]), payload[4+keyLength:], true
+}
+
+// persistMapping writes the mapping value atomically into the mappings
+// directory (temporary file then rename). A failure is only logged: the
+// persistence is a restart accelerator and must not fail the write path.
+func (provider *Simplefs) persistMapping(mappingKey string, value []byte) {
+	path := provider.mappingFilePath(mappingKey)
+	tmpPath := path + ".tmp"
+
+	if err := provider.fs.WriteFile(tmpPath, encodeMappingEnvelope(mappingKey, value), 0o644); err != nil {
+		provider.logger.Errorf("Impossible to persist the mapping %s: %v", mappingKey, err)
+
+		return
+	}
+
+	if err := provider.fs.Rename(tmpPath, path); err != nil {
+		provider.logger.Errorf("Impossible to replace the mapping file %s: %v", path, err)
+	}
+}
+
+// removeMappingFile deletes the persistence file when a mapping leaves the cache.
+func (provider *Simplefs) removeMappingFile(mappingKey string) {
+	_ = provider.fs.Remove(provider.mappingFilePath(mappingKey))
+}
+
+// loadMappings restores the mappings from the mappings directory into the
+// cache at startup. Mappings whose entries are all past the expiration window
+// are cleaned up right away instead of taking up memory.
+func (provider *Simplefs) loadMappings() {
+	dir := filepath.Join(provider.path, mappingsDirName)
+
+	if err := provider.fs.MkdirAll(dir, 0o755); err != nil {
+		provider.logger.Errorf("Impossible to create the mappings directory %s: %v", dir, err)
+
+		return
+	}
+
+	files, err := provider.fs.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	restored := 0
+
+	for _, file := range files {
+		if file.IsDir() || strings.HasSuffix(file.Name(), ".tmp") {
+			continue
+		}
+
+		filePath := filepath.Join(dir, file.Name())
+
+		payload, err := provider.fs.ReadFile(filePath)
+		if err != nil {
+			continue
+		}
+
+		mappingKey, value, ok := decodeMappingEnvelope(payload)
+		if !ok {
+			provider.logger.Warnf("The mapping file %s is corrupted, removing it", filePath)
+			_ = provider.fs.Remove(filePath)
+
+			continue
+		}
+
+		mapping, err := core.DecodeMapping(value)
+		if err != nil {
+			provider.logger.Warnf("The mapping file %s cannot be decoded, removing it: %v", filePath, err)
+			_ = provider.fs.Remove(filePath)
+
+			continue
+		}
+
+		// A mapping whose variants are all past the serveable window is not worth restoring.
+		alive := false
+
+		for _, keyIndex := range mapping.GetMapping() {
+			if core.Until(keyIndex.GetStaleTime().AsTime().Add(provider.maxGrace)) > 0 {
+				alive = true
+
+				break
+			}
+		}
+
+		if !alive {
+			_ = provider.fs.Remove(filePath)
+
+			continue
+		}
+
+		// Store back into the cache with the same negative TTL semantic as SetMultiLevel.
+		_ = provider.setRawValue(mappingKey, value, -time.Nanosecond)
+		restored++
+	}
+
+	if restored > 0 {
+		provider.logger.Infof("Restored %d mappings from the mappings directory", restored)
+	}
+}
+
+// pruneMappings cleans the mappings after a DeleteMany variant batch removal:
+// it drops the references pointing at deleted variants and removes, along
+// with its persisted copy, any mapping left without a single variant, so the
+// election never chases entries that no longer exist. Collect first, rewrite
+// after — never mutate the cache while iterating over it.
+func (provider *Simplefs) pruneMappings(deleted map[string]bool) {
+	type mappingUpdate struct {
+		key    string
+		value  []byte
+		ttl    time.Duration
+		remove bool
+	}
+
+	updates := []mappingUpdate{}
+
+	provider.cache.Range(func(item *ttlcache.Item[string, []byte]) bool {
+		if !strings.Contains(item.Key(), core.MappingKeyPrefix) {
+			return true
+		}
+
+		pruned, remaining, _, changed := core.PruneMapping(item.Value(), func(variedKey string) bool {
+			return deleted[variedKey]
+		})
+		if !changed {
+			return true
+		}
+
+		updates = append(updates, mappingUpdate{key: item.Key(), value: pruned, ttl: item.TTL(), remove: remaining == 0})
+
+		return true
+	})
+
+	for _, update := range updates {
+		if update.remove {
+			// Drop the accounting and the persisted copy of the mapping together.
+			provider.deleteKey(update.key)
+			provider.logger.Debugf("Removing the mapping %s left without variants after DeleteMany", update.key)
+
+			continue
+		}
+
+		_ = provider.setRawValue(update.key, update.value, update.ttl)
+		provider.persistMapping(update.key, update.value)
+	}
+}
@@ -0,0 +1,117 @@
+package simplefs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/jellydator/ttlcache/v3"
+)
+
+// EntryMeta describes the metadata of a single cache entry, exposed to
+// callers as a consistent structure instead of leaking implementation details
+// like the internal file path.
+type EntryMeta struct {
+	Key              string              `json:"key"`                         // cache key
+	Size             int64               `json:"size"`                        // (compressed) size on disk
+	UncompressedSize int64               `json:"uncompressed_size,omitempty"` // size after decompression
+	StoredAt         time.Time           `json:"stored_at"`                   // write time
+	ExpiresAt        time.Time           `json:"expires_at"`                  // expiry time
+	FreshTime        time.Time           `json:"fresh_time,omitzero"`         // end of the freshness window
+	StaleTime        time.Time           `json:"stale_time,omitzero"`         // end of the staleness window
+	VariedHeaders    map[string][]string `json:"varied_headers,omitempty"`    // Vary-related request headers
+	Compression      string              `json:"compression,omitempty"`       // compression method used
+	Etag             string              `json:"etag,omitempty"`              // response ETag (when known)
+	LastAccess       time.Time           `json:"last_access,omitzero"`        // last read time (filled by the listing API only)
+}
+
+// entryMeta builds an EntryMeta from a cache item. The second return value
+// reports whether the item is an entry pointing at a physical file (as
+// opposed to a mapping key or a raw value).
+func (provider *Simplefs) entryMeta(item *ttlcache.Item[string, []byte]) (EntryMeta, bool) {
+	meta := EntryMeta{
+		Key:         item.Key(),
+		ExpiresAt:   item.ExpiresAt(),
+		Compression: provider.compression,
+	}
+
+	// Get the size and write time from the physical file.
+	info, err := provider.fs.Stat(string(item.Value()))
+	if err != nil {
+		return meta, false
+	}
+
+	meta.Size = info.Size()
+	meta.StoredAt = info.ModTime()
+
+	return meta, true
+}
+
+// Meta returns the full metadata of the entry for the key, including the
+// fresh/stale deadlines, the Vary request headers and the ETag, so questions
+// like "why is this entry stale" can be debugged without parsing raw mapping
+// bytes.
+func (provider *Simplefs) Meta(key string) (EntryMeta, error) {
+	item := provider.cache.Get(key, ttlcache.WithDisableTouchOnHit[string, []byte]())
+	if item == nil {
+		return EntryMeta{}, fmt.Errorf("impossible to find the key %s in Simplefs", key)
+	}
+
+	meta, _ := provider.entryMeta(item)
+
+	// Uncompressed size: prefer the value recorded at write time, so the
+	// whole entry isn't decompressed just for metadata; without a record
+	// (e.g. after a restart) fall back to decompressing.
+	if recorded, found := provider.sizes.lookup(key); found {
+		meta.UncompressedSize = recorded.Uncompressed
+	} else if provider.compression != "" {
+		meta.UncompressedSize = int64(len(provider.Get(key)))
+	} else {
+		meta.UncompressedSize = meta.Size
+	}
+
+	// Scan the mapping keys to fill in the election-related metadata.
+	provider.cache.Range(func(mappingItem *ttlcache.Item[string, []byte]) bool {
+		if !strings.HasPrefix(mappingItem.Key(), core.MappingKeyPrefix) {
+			return true
+		}
+
+		mapping, err := core.DecodeMapping(mappingItem.Value())
+		if err != nil {
+			return true
+		}
+
+		keyIndex, found := mapping.GetMapping()[key]
+		if !found {
+			return true
+		}
+
+		meta.StoredAt = keyIndex.GetStoredAt().AsTime()
+		meta.FreshTime = keyIndex.GetFreshTime().AsTime()
+		meta.StaleTime = keyIndex.GetStaleTime().AsTime()
+		meta.Etag = keyIndex.GetEtag()
+
+		if len(keyIndex.GetVariedHeaders()) > 0 {
+			meta.VariedHeaders = map[string][]string{}
+			for hname, hval := range keyIndex.GetVariedHeaders() {
+				meta.VariedHeaders[hname] = hval.GetHeaderValue()
+			}
+		}
+
+		return false // found the matching mapping entry, stop the iteration
+	})
+
+	return meta, nil
+}
+
+// encodeEntryMeta serializes an EntryMeta to a JSON string.
+func encodeEntryMeta(meta EntryMeta) string {
+	encoded, err := json.Marshal(meta)
+	if err != nil {
+		return ""
+	}
+
+	return string(encoded)
+}
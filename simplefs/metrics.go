@@ -0,0 +1,46 @@
+package simplefs
+
+import "time"
+
+// Metrics is the integration point of the storage metrics. simplefs itself
+// depends on no metrics library; the host (e.g. the Caddy module) injects an
+// implementation through SetMetrics, wiring the hits, evictions, disk usage,
+// compression ratio and read/write latencies into its own metrics system.
+type Metrics interface {
+	// CacheHit is called on a GetMultiLevel hit.
+	CacheHit()
+	// CacheMiss is called on a GetMultiLevel miss.
+	CacheMiss()
+	// Eviction is called when an entry is evicted for capacity or expiry.
+	Eviction()
+	// SetActualSize reports the current disk usage in bytes.
+	SetActualSize(bytes int64)
+	// CompressionRatio reports the compression ratio of one write (compressed/uncompressed).
+	CompressionRatio(ratio float64)
+	// ObserveGet reports the duration of one entry read.
+	ObserveGet(duration time.Duration)
+	// ObserveSet reports the duration of one entry write.
+	ObserveSet(duration time.Duration)
+}
+
+// nopMetrics is the empty implementation used when no metrics are injected.
+type nopMetrics struct{}
+
+func (nopMetrics) CacheHit()                  {}
+func (nopMetrics) CacheMiss()                 {}
+func (nopMetrics) Eviction()                  {}
+func (nopMetrics) SetActualSize(_ int64)      {}
+func (nopMetrics) CompressionRatio(_ float64) {}
+func (nopMetrics) ObserveGet(_ time.Duration) {}
+func (nopMetrics) ObserveSet(_ time.Duration) {}
+
+// SetMetrics injects the metrics implementation, nil restores the empty one.
+func (provider *Simplefs) SetMetrics(metrics Metrics) {
+	if metrics == nil {
+		provider.metrics = nopMetrics{}
+
+		return
+	}
+
+	provider.metrics = metrics
+}
@@ -0,0 +1,118 @@
+package simplefs
+
+import (
+	"net/http"
+	"sync"
+)
+
+// ownerIndex maintains the reverse index from a user/session identifier to
+// its key set, so a GDPR-style per-user purge never scans the whole cache.
+type ownerIndex struct {
+	mu      sync.RWMutex
+	byOwner map[string]map[string]struct{} // identifier -> key set
+	byKey   map[string]string              // key -> identifier, for the reverse cleanup on eviction
+}
+
+// newOwnerIndex creates an empty reverse index.
+func newOwnerIndex() *ownerIndex {
+	return &ownerIndex{
+		byOwner: map[string]map[string]struct{}{},
+		byKey:   map[string]string{},
+	}
+}
+
+// add registers a key under the given identifier, an empty identifier is ignored.
+func (index *ownerIndex) add(owner, key string) {
+	if owner == "" {
+		return
+	}
+
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	// When the ownership of a key changes, remove it from the old identifier set first.
+	if previous, found := index.byKey[key]; found && previous != owner {
+		index.removeLocked(previous, key)
+	}
+
+	if index.byOwner[owner] == nil {
+		index.byOwner[owner] = map[string]struct{}{}
+	}
+
+	index.byOwner[owner][key] = struct{}{}
+	index.byKey[key] = owner
+}
+
+// removeKey cleans the index when a key is deleted or evicted.
+func (index *ownerIndex) removeKey(key string) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	if owner, found := index.byKey[key]; found {
+		index.removeLocked(owner, key)
+	}
+}
+
+// removeLocked removes a key from an identifier set with the lock held.
+func (index *ownerIndex) removeLocked(owner, key string) {
+	if keys, found := index.byOwner[owner]; found {
+		delete(keys, key)
+
+		if len(keys) == 0 {
+			delete(index.byOwner, owner)
+		}
+	}
+
+	delete(index.byKey, key)
+}
+
+// keys returns every key registered under the given identifier.
+func (index *ownerIndex) keys(owner string) []string {
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+
+	keys := make([]string, 0, len(index.byOwner[owner]))
+	for key := range index.byOwner[owner] {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// ownerFromHeaders extracts the user identifier from the configured request
+// header or cookie name.
+func (provider *Simplefs) ownerFromHeaders(variedHeaders http.Header) string {
+	if provider.ownerHeader != "" {
+		if owner := variedHeaders.Get(provider.ownerHeader); owner != "" {
+			return owner
+		}
+	}
+
+	if provider.ownerCookie != "" {
+		request := http.Request{Header: variedHeaders}
+		if cookie, err := request.Cookie(provider.ownerCookie); err == nil {
+			return cookie.Value
+		}
+	}
+
+	return ""
+}
+
+// KeysByOwner returns every key owned by the given user identifier.
+func (provider *Simplefs) KeysByOwner(owner string) []string {
+	return provider.ownerIndex.keys(owner)
+}
+
+// DeleteByOwner deletes every key owned by the given user identifier and
+// reports the deletions, serving data removal requests.
+func (provider *Simplefs) DeleteByOwner(owner string) PurgeReport {
+	report := PurgeReport{}
+
+	for _, key := range provider.ownerIndex.keys(owner) {
+		keyReport := provider.DeleteWithReport(key)
+		report.Keys = append(report.Keys, keyReport.Keys...)
+		report.FreedBytes += keyReport.FreedBytes
+	}
+
+	return report
+}
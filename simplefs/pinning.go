@@ -0,0 +1,98 @@
+package simplefs
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/jellydator/ttlcache/v3"
+)
+
+// pinSet tracks the pinned keys and their disk footprint. A pinned entry is
+// exempt from the space-pressure eviction (critical landing pages, service
+// workers, API schemas should never be squeezed out of the cache), but an
+// explicit purge still applies.
+type pinSet struct {
+	mu    sync.Mutex
+	sizes map[string]int64
+	total int64
+}
+
+// newPinSet creates an empty pin set.
+func newPinSet() *pinSet {
+	return &pinSet{sizes: map[string]int64{}}
+}
+
+// pinned reports whether a key is pinned.
+func (pins *pinSet) pinned(key string) bool {
+	pins.mu.Lock()
+	defer pins.mu.Unlock()
+
+	_, found := pins.sizes[key]
+
+	return found
+}
+
+// add pins a key. With a positive limit an error is returned when the pinned
+// total would exceed it.
+func (pins *pinSet) add(key string, size, limit int64) error {
+	pins.mu.Lock()
+	defer pins.mu.Unlock()
+
+	if _, found := pins.sizes[key]; found {
+		return nil // already pinned, idempotent
+	}
+
+	if limit > 0 && pins.total+size > limit {
+		return fmt.Errorf("pinning %s (%d bytes) would exceed the pinned total limit of %d bytes", key, size, limit)
+	}
+
+	pins.sizes[key] = size
+	pins.total += size
+
+	return nil
+}
+
+// remove unpins a key.
+func (pins *pinSet) remove(key string) {
+	pins.mu.Lock()
+	defer pins.mu.Unlock()
+
+	if size, found := pins.sizes[key]; found {
+		pins.total -= size
+		delete(pins.sizes, key)
+	}
+}
+
+// totalBytes returns the total disk footprint of the pinned entries.
+func (pins *pinSet) totalBytes() int64 {
+	pins.mu.Lock()
+	defer pins.mu.Unlock()
+
+	return pins.total
+}
+
+// Pin pins an existing entry, exempting it from the space-pressure eviction.
+// An explicit Delete/purge is not affected by the pin.
+func (provider *Simplefs) Pin(key string) error {
+	item := provider.cache.Get(key, ttlcache.WithDisableTouchOnHit[string, []byte]())
+	if item == nil {
+		return fmt.Errorf("impossible to find the key %s in Simplefs", key)
+	}
+
+	var size int64
+	if info, err := provider.fs.Stat(string(item.Value())); err == nil {
+		size = provider.diskUsage(info.Size())
+	}
+
+	return provider.pins.add(key, size, provider.maxPinnedBytes)
+}
+
+// Unpin unpins an entry, making it evictable again.
+func (provider *Simplefs) Unpin(key string) {
+	provider.pins.remove(key)
+}
+
+// PinnedBytes returns the total disk footprint of the pinned entries.
+func (provider *Simplefs) PinnedBytes() int64 {
+	return provider.pins.totalBytes()
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package simplefs
+
+import "os"
+
+// preallocate is a no-op on platforms without fallocate.
+func preallocate(_ *os.File, _ int64) error {
+	return nil
+}
@@ -0,0 +1,134 @@
+package simplefs
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Default capacity alert thresholds.
+var pressureThresholds = []float64{0.8, 0.95}
+
+// pressureAlert is the alert payload sent to the log and the webhook.
+type pressureAlert struct {
+	Time                time.Time `json:"time"`
+	Reason              string    `json:"reason"`
+	ActualSize          int64     `json:"actual_size"`
+	DirectorySize       int64     `json:"directory_size"`
+	Ratio               float64   `json:"ratio,omitempty"`
+	EvictionsLastMinute int64     `json:"evictions_last_minute,omitempty"`
+}
+
+// pressureMonitor tracks the capacity watermark and the eviction rate,
+// emitting structured alerts when a threshold is crossed, so operators spot
+// an undersized cache before the hit ratio collapses.
+type pressureMonitor struct {
+	mu        sync.Mutex
+	lastLevel int // current threshold level, 0 means below every threshold
+
+	evictionWindow time.Time // start of the current eviction counting window
+	evictionCount  int64     // evictions within the window
+	evictionAlert  int64     // evictions-per-minute alert threshold, 0 disables it
+
+	webhook string // alert webhook URL, empty means log only
+}
+
+// checkCapacity runs after an actualSize change, alerting when a higher
+// threshold is crossed.
+func (provider *Simplefs) checkCapacity() {
+	if provider.directorySize <= 0 {
+		return
+	}
+
+	provider.mu.Lock()
+	ratio := float64(provider.actualSize) / float64(provider.directorySize)
+	actualSize := provider.actualSize
+	provider.mu.Unlock()
+
+	level := 0
+
+	for i, threshold := range pressureThresholds {
+		if ratio >= threshold {
+			level = i + 1
+		}
+	}
+
+	monitor := &provider.pressure
+
+	monitor.mu.Lock()
+	crossed := level > monitor.lastLevel
+	monitor.lastLevel = level
+	monitor.mu.Unlock()
+
+	if crossed {
+		provider.sendPressureAlert(pressureAlert{
+			Reason:        "capacity",
+			ActualSize:    actualSize,
+			DirectorySize: provider.directorySize,
+			Ratio:         ratio,
+		})
+	}
+}
+
+// noteEviction counts the eviction rate, alerting once when the threshold is
+// reached within the window.
+func (provider *Simplefs) noteEviction() {
+	monitor := &provider.pressure
+
+	monitor.mu.Lock()
+
+	now := time.Now()
+	if now.Sub(monitor.evictionWindow) >= time.Minute {
+		monitor.evictionWindow = now
+		monitor.evictionCount = 0
+	}
+
+	monitor.evictionCount++
+	spike := monitor.evictionAlert > 0 && monitor.evictionCount == monitor.evictionAlert
+	count := monitor.evictionCount
+
+	monitor.mu.Unlock()
+
+	if spike {
+		provider.mu.Lock()
+		actualSize := provider.actualSize
+		provider.mu.Unlock()
+
+		provider.sendPressureAlert(pressureAlert{
+			Reason:              "eviction_rate",
+			ActualSize:          actualSize,
+			DirectorySize:       provider.directorySize,
+			EvictionsLastMinute: count,
+		})
+	}
+}
+
+// sendPressureAlert logs the structured alert and delivers it asynchronously
+// to the webhook (when configured).
+func (provider *Simplefs) sendPressureAlert(alert pressureAlert) {
+	alert.Time = time.Now()
+
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return
+	}
+
+	provider.logger.Warnf("Cache pressure alert: %s", payload)
+
+	if provider.pressure.webhook == "" {
+		return
+	}
+
+	go func() {
+		response, err := http.Post(provider.pressure.webhook, "application/json", bytes.NewReader(payload))
+		if err != nil {
+			provider.logger.Errorf("Impossible to deliver the pressure alert to the webhook: %v", err)
+
+			return
+		}
+
+		_ = response.Body.Close()
+	}()
+}
@@ -0,0 +1,115 @@
+package simplefs
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// priorityRule maps a key prefix to a priority level, the higher the later
+// the eviction.
+type priorityRule struct {
+	prefix string
+	level  int
+}
+
+// priorityIndex keeps the priority of each entry in memory. The
+// space-pressure eviction removes the low-priority entries first, letting
+// operators protect the pages that are expensive to generate.
+type priorityIndex struct {
+	mu     sync.Mutex
+	levels map[string]int
+}
+
+// newPriorityIndex creates an empty priority index.
+func newPriorityIndex() *priorityIndex {
+	return &priorityIndex{levels: map[string]int{}}
+}
+
+// set registers the priority of an entry, 0 (the default level) takes no record.
+func (index *priorityIndex) set(key string, level int) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	if level == 0 {
+		delete(index.levels, key)
+
+		return
+	}
+
+	index.levels[key] = level
+}
+
+// level returns the priority of an entry, 0 when unrecorded.
+func (index *priorityIndex) level(key string) int {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	return index.levels[key]
+}
+
+// drop cleans the priority record when an entry is deleted or evicted.
+func (index *priorityIndex) drop(key string) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	delete(index.levels, key)
+}
+
+// parsePriorityRules parses the key prefix to priority rules from the
+// configuration, shaped like {"GET-https-example.com-/expensive": 10}. Longer
+// prefixes match first.
+func parsePriorityRules(raw interface{}) []priorityRule {
+	config, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	rules := make([]priorityRule, 0, len(config))
+
+	for prefix, v := range config {
+		level := 0
+		if val, ok := v.(int); ok {
+			level = val
+		} else if val, ok := v.(float64); ok {
+			level = int(val)
+		} else if val, ok := v.(string); ok {
+			level, _ = strconv.Atoi(val)
+		}
+
+		if level != 0 {
+			rules = append(rules, priorityRule{prefix: prefix, level: level})
+		}
+	}
+
+	// Longer prefixes first, so the most specific rule wins.
+	sort.Slice(rules, func(i, j int) bool {
+		return len(rules[i].prefix) > len(rules[j].prefix)
+	})
+
+	return rules
+}
+
+// priorityFor computes the priority of an entry: with priority_header
+// configured and present in the response the header value wins, otherwise
+// the key prefix rules apply, the default level being 0.
+func (provider *Simplefs) priorityFor(key string, value []byte) int {
+	if provider.priorityHeader != "" {
+		if headers := storedResponseHeaders(value); headers != nil {
+			if raw := headers.Get(provider.priorityHeader); raw != "" {
+				if level, err := strconv.Atoi(raw); err == nil {
+					return level
+				}
+			}
+		}
+	}
+
+	for _, rule := range provider.priorityRules {
+		if strings.HasPrefix(key, rule.prefix) {
+			return rule.level
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,276 @@
+package simplefs
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RangeKeyPrefix is the cache key prefix of range piece entries.
+const RangeKeyPrefix = "RANGE_"
+
+// rangeSpan describes the byte span a piece covers (inclusive).
+type rangeSpan struct {
+	start int64
+	end   int64
+}
+
+// rangeIndex maintains the index from base key to stored pieces, so the
+// pieces of 206 responses can assemble larger ranges or even the full object
+// on later requests.
+type rangeIndex struct {
+	mu     sync.RWMutex
+	pieces map[string]map[string]rangeSpan // base key -> piece key -> span
+	totals map[string]int64                // base key -> total object size (when known)
+}
+
+// newRangeIndex creates an empty piece index.
+func newRangeIndex() *rangeIndex {
+	return &rangeIndex{
+		pieces: map[string]map[string]rangeSpan{},
+		totals: map[string]int64{},
+	}
+}
+
+// add registers a piece.
+func (index *rangeIndex) add(key, pieceKey string, span rangeSpan, total int64) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	if index.pieces[key] == nil {
+		index.pieces[key] = map[string]rangeSpan{}
+	}
+
+	index.pieces[key][pieceKey] = span
+
+	if total > 0 {
+		index.totals[key] = total
+	}
+}
+
+// removePiece cleans up the index when a piece entry is deleted or evicted.
+func (index *rangeIndex) removePiece(pieceKey string) {
+	key, found := baseFromRangeKey(pieceKey)
+	if !found {
+		return
+	}
+
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	if spans, ok := index.pieces[key]; ok {
+		delete(spans, pieceKey)
+
+		if len(spans) == 0 {
+			delete(index.pieces, key)
+			delete(index.totals, key)
+		}
+	}
+}
+
+// spans returns the stored pieces of a base key, sorted by start offset.
+func (index *rangeIndex) spans(key string) ([]string, []rangeSpan) {
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+
+	pieceKeys := make([]string, 0, len(index.pieces[key]))
+	for pieceKey := range index.pieces[key] {
+		pieceKeys = append(pieceKeys, pieceKey)
+	}
+
+	sort.Slice(pieceKeys, func(i, j int) bool {
+		return index.pieces[key][pieceKeys[i]].start < index.pieces[key][pieceKeys[j]].start
+	})
+
+	spans := make([]rangeSpan, len(pieceKeys))
+	for i, pieceKey := range pieceKeys {
+		spans[i] = index.pieces[key][pieceKey]
+	}
+
+	return pieceKeys, spans
+}
+
+// rangePieceKey builds the cache key of a piece entry.
+func rangePieceKey(key string, span rangeSpan) string {
+	return fmt.Sprintf("%s%s#%d-%d", RangeKeyPrefix, key, span.start, span.end)
+}
+
+// baseFromRangeKey recovers the base key from a piece key.
+func baseFromRangeKey(pieceKey string) (string, bool) {
+	trimmed, found := strings.CutPrefix(pieceKey, RangeKeyPrefix)
+	if !found {
+		return "", false
+	}
+
+	idx := strings.LastIndex(trimmed, "#")
+	if idx < 0 {
+		return "", false
+	}
+
+	return trimmed[:idx], true
+}
+
+// SetRange stores one piece of a 206 response. start is the piece offset
+// within the full object, total the full object size (0 when unknown). Pieces
+// are written to disk as-is without compression, so assembly can read them by
+// offset directly.
+func (provider *Simplefs) SetRange(key string, start, total int64, value []byte, duration time.Duration) error {
+	if len(value) == 0 {
+		return nil
+	}
+
+	span := rangeSpan{start: start, end: start + int64(len(value)) - 1}
+	pieceKey := rangePieceKey(key, span)
+
+	joinedFP := provider.entryFilePath(pieceKey)
+	if err := provider.writeEntryFileTimed(joinedFP, value); err != nil {
+		provider.logger.Errorf("Impossible to write the piece file %s: %#v", pieceKey, err)
+
+		return err
+	}
+
+	_ = provider.setEntryFile(pieceKey, joinedFP, duration)
+	provider.rangeIdx.add(key, pieceKey, span, total)
+
+	return nil
+}
+
+// GetRange assembles the [start, end] span from the stored pieces. It returns
+// false when the pieces leave a gap, and the caller should go to the origin.
+func (provider *Simplefs) GetRange(key string, start, end int64) ([]byte, bool) {
+	if end < start {
+		return nil, false
+	}
+
+	pieceKeys, spans := provider.rangeIdx.spans(key)
+	assembled := make([]byte, 0, end-start+1)
+	cursor := start
+
+	for i, span := range spans {
+		if span.end < cursor {
+			continue // the piece lies entirely before the requested span
+		}
+
+		if span.start > cursor {
+			return nil, false // gap between pieces
+		}
+
+		item := provider.cache.Get(pieceKeys[i])
+		if item == nil {
+			return nil, false // the piece has been evicted
+		}
+
+		content, err := provider.readEntryFile(string(item.Value()))
+		if err != nil {
+			provider.logger.Errorf("Impossible to read the piece file %s: %#v", pieceKeys[i], err)
+
+			return nil, false
+		}
+
+		// Slice the part of the piece that falls within the requested span.
+		from := cursor - span.start
+		to := span.end
+
+		if to > end {
+			to = end
+		}
+
+		assembled = append(assembled, content[from:from+(to-cursor)+1]...)
+		cursor = to + 1
+
+		if cursor > end {
+			return assembled, true
+		}
+	}
+
+	return nil, false
+}
+
+// GetFull assembles the full response body when the pieces cover the whole object.
+func (provider *Simplefs) GetFull(key string) ([]byte, bool) {
+	provider.rangeIdx.mu.RLock()
+	total := provider.rangeIdx.totals[key]
+	provider.rangeIdx.mu.RUnlock()
+
+	if total <= 0 {
+		return nil, false // unknown total size, completeness can't be decided
+	}
+
+	return provider.GetRange(key, 0, total-1)
+}
+
+// RangeCoverage returns the covered bytes and the total object size (0 when
+// unknown) of a base key, so callers can decide between filling the gaps and
+// going straight to the origin.
+func (provider *Simplefs) RangeCoverage(key string) (covered, total int64) {
+	_, spans := provider.rangeIdx.spans(key)
+
+	var cursor int64 = -1
+
+	for _, span := range spans {
+		start := span.start
+		if start <= cursor {
+			start = cursor + 1
+		}
+
+		if span.end >= start {
+			covered += span.end - start + 1
+			cursor = span.end
+		}
+	}
+
+	provider.rangeIdx.mu.RLock()
+	total = provider.rangeIdx.totals[key]
+	provider.rangeIdx.mu.RUnlock()
+
+	return covered, total
+}
+
+// parseContentRange parses a Content-Range header shaped like
+// "bytes 0-1023/146515", so the middleware gets the offset and total size
+// when storing a 206 response.
+func parseContentRange(header string) (start, end, total int64, err error) {
+	value, found := strings.CutPrefix(header, "bytes ")
+	if !found {
+		return 0, 0, 0, fmt.Errorf("impossible to parse the Content-Range header: %s", header)
+	}
+
+	rangePart, totalPart, found := strings.Cut(value, "/")
+	if !found {
+		return 0, 0, 0, fmt.Errorf("impossible to parse the Content-Range header: %s", header)
+	}
+
+	startPart, endPart, found := strings.Cut(rangePart, "-")
+	if !found {
+		return 0, 0, 0, fmt.Errorf("impossible to parse the Content-Range header: %s", header)
+	}
+
+	if start, err = strconv.ParseInt(startPart, 10, 64); err != nil {
+		return 0, 0, 0, err
+	}
+
+	if end, err = strconv.ParseInt(endPart, 10, 64); err != nil {
+		return 0, 0, 0, err
+	}
+
+	if totalPart != "*" {
+		if total, err = strconv.ParseInt(totalPart, 10, 64); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+
+	return start, end, total, nil
+}
+
+// SetRangeFromResponse stores a 206 piece according to its Content-Range header.
+func (provider *Simplefs) SetRangeFromResponse(key, contentRange string, value []byte, duration time.Duration) error {
+	start, _, total, err := parseContentRange(contentRange)
+	if err != nil {
+		return err
+	}
+
+	return provider.SetRange(key, start, total, value, duration)
+}
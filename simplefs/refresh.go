@@ -0,0 +1,68 @@
+package simplefs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/jellydator/ttlcache/v3"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// RefreshMultiLevel only updates the fresh/stale deadlines and the validators
+// in the mapping after the origin answered 304, without rewriting the
+// (possibly large) response body file — before it the middleware had to go
+// through a full SetMultiLevel. Empty etag and lastModified keep the previous
+// validators.
+func (provider *Simplefs) RefreshMultiLevel(baseKey, variedKey string, etag, lastModified string, duration time.Duration) error {
+	now := core.Now() // injectable clock, keeping the time behavior deterministic in tests
+
+	mappingKey := core.MappingKeyPrefix + baseKey
+
+	item := provider.cache.Get(mappingKey)
+	if item == nil {
+		return fmt.Errorf("impossible to find the mapping key %s in Simplefs", mappingKey)
+	}
+
+	mapping, err := core.DecodeMapping(item.Value())
+	if err != nil {
+		return fmt.Errorf("impossible to decode the mapping key %s: %w", mappingKey, err)
+	}
+
+	keyIndex, found := mapping.GetMapping()[variedKey]
+	if !found {
+		return fmt.Errorf("the mapping %s does not contain the variant %s", mappingKey, variedKey)
+	}
+
+	// Only the timestamps and the validators change, the body file stays untouched.
+	keyIndex.StoredAt = timestamppb.New(now)
+	keyIndex.FreshTime = timestamppb.New(now.Add(duration))
+	keyIndex.StaleTime = timestamppb.New(now.Add(duration + provider.stale))
+
+	if etag != "" {
+		keyIndex.Etag = etag
+	}
+
+	if lastModified != "" {
+		keyIndex.LastModified = lastModified
+	}
+
+	val, err := proto.Marshal(mapping)
+	if err != nil {
+		return fmt.Errorf("impossible to encode the mapping key %s: %w", mappingKey, err)
+	}
+
+	// Extend the cache TTL of the variant entry to match the new expiry.
+	if entry := provider.cache.Get(variedKey, ttlcache.WithDisableTouchOnHit[string, []byte]()); entry != nil {
+		_ = provider.setEntryFile(variedKey, string(entry.Value()), duration)
+	}
+
+	_ = provider.setRawValue(mappingKey, val, item.TTL())
+
+	provider.persistMapping(mappingKey, val) // keep the persisted mapping copy in sync
+
+	provider.logger.Debugf("Refreshed the freshness deadline of the variant %s in Simplefs", variedKey)
+
+	return nil
+}
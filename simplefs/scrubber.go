@@ -0,0 +1,150 @@
+package simplefs
+
+import (
+	"bytes"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/pierrec/lz4/v4"
+)
+
+// ScrubStats aggregates the results of the background integrity checker.
+type ScrubStats struct {
+	Scanned   int64     `json:"scanned"`   // checked entries
+	Corrupted int64     `json:"corrupted"` // corrupted entries found and evicted
+	Missing   int64     `json:"missing"`   // entries with a missing file
+	LastRun   time.Time `json:"last_run"`  // time of the latest pass
+}
+
+// scrubber samples a fraction of the cache entries in the background at low
+// priority, verifying that the file exists and decompresses successfully, and
+// evicting the corrupted entries.
+type scrubber struct {
+	scanned   atomic.Int64
+	corrupted atomic.Int64
+	missing   atomic.Int64
+	lastRun   atomic.Int64 // Unix nanosecond timestamp
+}
+
+// ScrubberStats returns the statistics of the background integrity checker.
+func (provider *Simplefs) ScrubberStats() ScrubStats {
+	return ScrubStats{
+		Scanned:   provider.scrub.scanned.Load(),
+		Corrupted: provider.scrub.corrupted.Load(),
+		Missing:   provider.scrub.missing.Load(),
+		LastRun:   time.Unix(0, provider.scrub.lastRun.Load()),
+	}
+}
+
+// verifyEntry verifies that the file of a single entry exists and that its
+// content decompresses successfully with the configured compression method.
+func (provider *Simplefs) verifyEntry(item *ttlcache.Item[string, []byte]) {
+	provider.scrub.scanned.Add(1)
+
+	filePath := string(item.Value())
+
+	content, err := provider.fs.ReadFile(filePath)
+	if err != nil {
+		provider.scrub.missing.Add(1)
+		provider.logger.Warnf("Integrity check: the file of the key %s is missing, evicting the entry", item.Key())
+		provider.deleteKey(item.Key())
+
+		return
+	}
+
+	valid := true
+
+	// With encryption at rest decrypt before verifying, a decryption failure is itself a corruption.
+	if provider.encryption != nil {
+		decrypted, decErr := provider.encryption.open(content)
+		if decErr != nil {
+			valid = false
+		} else {
+			content = decrypted
+		}
+	}
+
+	if valid {
+		// Prefer the entry header to identify the compression method, legacy
+		// files fall back to magic-byte sniffing.
+		method, body := provider.resolveEntryMethod(content)
+
+		switch method {
+		case "lz4", "":
+			_, err := io.Copy(io.Discard, lz4.NewReader(bytes.NewReader(body)))
+			valid = err == nil
+		case "gzip", methodGzipDict:
+			reader, err := provider.entryGzipReader(bytes.NewReader(body), method == methodGzipDict)
+			if err != nil {
+				valid = false
+			} else {
+				_, err = io.Copy(io.Discard, reader)
+				_ = reader.Close()
+				valid = err == nil
+			}
+		}
+	}
+
+	if !valid {
+		provider.scrub.corrupted.Add(1)
+		provider.logger.Warnf("Integrity check: the file of the key %s cannot be decompressed, evicting the entry", item.Key())
+		provider.notify("corruption", item.Key(), int64(len(content)))
+		provider.deleteKey(item.Key())
+	}
+}
+
+// runScrubPass samples one round of cache entries, fraction controlling the
+// share of entries checked per pass.
+func (provider *Simplefs) runScrubPass(fraction float64) {
+	total := provider.cache.Len()
+	if total == 0 {
+		provider.scrub.lastRun.Store(time.Now().UnixNano())
+
+		return
+	}
+
+	budget := int(float64(total) * fraction)
+	if budget < 1 {
+		budget = 1
+	}
+
+	var checked int
+
+	provider.cache.Range(func(item *ttlcache.Item[string, []byte]) bool {
+		if checked >= budget {
+			return false
+		}
+
+		// Mapping keys have no physical file, skip them.
+		if _, isFile := provider.entryMeta(item); !isFile {
+			return true
+		}
+
+		checked++
+		provider.verifyEntry(item)
+
+		return true
+	})
+
+	provider.scrub.lastRun.Store(time.Now().UnixNano())
+	provider.logger.Debugf("Integrity check done: %d entries checked this pass", checked)
+}
+
+// startScrubber starts the background integrity check loop, a zero interval
+// disables it.
+func (provider *Simplefs) startScrubber(interval time.Duration, fraction float64) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			provider.runScrubPass(fraction)
+		}
+	}()
+}
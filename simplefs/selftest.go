@@ -0,0 +1,78 @@
+package simplefs
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SelfTestReport aggregates the startup self-test results, exposing
+// configuration or disk problems at startup instead of under production
+// traffic.
+type SelfTestReport struct {
+	Path         string        `json:"path"`
+	WriteLatency time.Duration `json:"write_latency"`
+	ReadLatency  time.Duration `json:"read_latency"`
+	FreeBytes    uint64        `json:"free_bytes"` // 0 when the current platform cannot report it
+}
+
+// SelfTest writes, reads back and removes a probe file in the storage
+// directory, measuring the read and write latencies and checking the
+// directory permissions and the remaining space. Any failing step returns an
+// error with context.
+func (provider *Simplefs) SelfTest() (SelfTestReport, error) {
+	report := SelfTestReport{Path: provider.path}
+
+	info, err := provider.fs.Stat(provider.path)
+	if err != nil {
+		return report, fmt.Errorf("impossible to access the storage directory %s: %w", provider.path, err)
+	}
+
+	if !info.IsDir() {
+		return report, fmt.Errorf("the storage path %s is not a directory", provider.path)
+	}
+
+	probePath := filepath.Join(provider.path, fmt.Sprintf(".selftest-%d", os.Getpid()))
+	payload := []byte("simplefs self-test probe")
+
+	writeStart := time.Now()
+
+	if err := provider.fs.WriteFile(probePath, payload, 0o644); err != nil {
+		return report, fmt.Errorf("impossible to write the probe file, check the directory permissions: %w", err)
+	}
+
+	report.WriteLatency = time.Since(writeStart)
+
+	readStart := time.Now()
+
+	content, err := provider.fs.ReadFile(probePath)
+	if err != nil {
+		_ = provider.fs.Remove(probePath)
+
+		return report, fmt.Errorf("impossible to read the probe file back: %w", err)
+	}
+
+	report.ReadLatency = time.Since(readStart)
+
+	if !bytes.Equal(content, payload) {
+		_ = provider.fs.Remove(probePath)
+
+		return report, fmt.Errorf("the probe file content does not match, the disk may be faulty")
+	}
+
+	if err := provider.fs.Remove(probePath); err != nil {
+		return report, fmt.Errorf("impossible to remove the probe file: %w", err)
+	}
+
+	report.FreeBytes = diskFree(provider.path)
+
+	// Fail early when the configured capacity exceeds the available space,
+	// instead of hitting ENOSPC mid-run.
+	if report.FreeBytes > 0 && provider.directorySize > 0 && uint64(provider.directorySize) > report.FreeBytes {
+		return report, fmt.Errorf("the configured directory_size (%d bytes) exceeds the available space (%d bytes)", provider.directorySize, report.FreeBytes)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,104 @@
+package simplefs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+)
+
+// maxShardDepth caps the shard depth, going deeper only multiplies directories.
+const maxShardDepth = 4
+
+// shardDir returns the relative shard subdirectory path from the file name
+// hash, e.g. "ab/cd" at depth 2. The sharding is based on the final file name
+// rather than the original key, so the old flat layout can be migrated
+// without knowing the original keys (the file name itself is the shard
+// function input).
+func (provider *Simplefs) shardDir(fileName string) string {
+	if provider.shardDepth <= 0 {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(fileName))
+
+	parts := make([]string, provider.shardDepth)
+	for i := range parts {
+		parts[i] = hex.EncodeToString(sum[i : i+1])
+	}
+
+	return filepath.Join(parts...)
+}
+
+// migrateFlatLayout migrates the old flat layout into the shard
+// subdirectories: the entry files at the root move to their shard location,
+// the dot-prefixed sidecar files and the subdirectories stay in place.
+func (provider *Simplefs) migrateFlatLayout() {
+	if provider.shardDepth <= 0 {
+		return
+	}
+
+	files, err := provider.fs.ReadDir(provider.path)
+	if err != nil {
+		return
+	}
+
+	moved := 0
+
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		target := filepath.Join(provider.path, provider.shardDir(name), name)
+
+		if err := provider.fs.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			provider.logger.Errorf("Impossible to create the shard directory %s: %v", filepath.Dir(target), err)
+
+			continue
+		}
+
+		if err := provider.fs.Rename(filepath.Join(provider.path, name), target); err != nil {
+			provider.logger.Errorf("Impossible to migrate the file %s to the sharded layout: %v", name, err)
+
+			continue
+		}
+
+		moved++
+	}
+
+	if moved > 0 {
+		provider.logger.Infof("Migrated %d files to the sharded layout of depth %d", moved, provider.shardDepth)
+	}
+}
+
+// scanDirSize recursively sums the (block-aligned) disk usage of every file
+// under dir. With root set the mappings subdirectory is skipped, its overhead
+// is charged separately during the restore.
+func (provider *Simplefs) scanDirSize(dir string, root bool) int64 {
+	files, err := provider.fs.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+
+	var total int64
+
+	for _, file := range files {
+		if file.IsDir() {
+			if root && file.Name() == mappingsDirName {
+				continue
+			}
+
+			total += provider.scanDirSize(filepath.Join(dir, file.Name()), false)
+
+			continue
+		}
+
+		if info, err := file.Info(); err == nil {
+			total += provider.diskUsage(info.Size())
+		}
+	}
+
+	return total
+}
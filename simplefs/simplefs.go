@@ -2,61 +2,285 @@ package simplefs
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"net/url"
 	"os"
-	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/darkweak/storages/core"
 	"github.com/dustin/go-humanize"
 	"github.com/jellydator/ttlcache/v3"
 	"github.com/pierrec/lz4/v4"
+	"golang.org/x/sync/semaphore"
 )
 
-// Simplefs 提供程序类型。
+// Simplefs provider type.
 type Simplefs struct {
 	cache         *ttlcache.Cache[string, []byte]
-	stale         time.Duration // 过期时间
-	size          int           // 缓存的最大项目数
-	path          string        // 存储目录路径
-	logger        core.Logger   // 日志记录器
-	actualSize    int64         // 当前缓存的实际大小（字节）
-	directorySize int64         // 最大目录大小（字节），-1 表示无限制
-	mu            sync.Mutex    // 互斥锁，用于同步访问 actualSize 和 directorySize
-	compression   string        // 使用的压缩方法 ("lz4", "gzip", "" 表示不压缩)
+	stale         time.Duration    // stale duration
+	size          int              // maximum number of cached items
+	path          string           // storage directory path
+	logger        core.Logger      // logger
+	actualSize    int64            // current on-disk size of the cache (bytes)
+	charges       map[string]int64 // charged size per key, actualSize is always their sum
+	directorySize int64            // maximum directory size (bytes), -1 means unlimited
+	mu            sync.Mutex       // guards actualSize, charges and directorySize
+	compression   string           // compression method ("lz4", "gzip", "" for none)
+
+	compressionLevel int    // compression level, 0 uses the library default
+	compressionDict  []byte // pre-trained compression dictionary, empty disables it
+
+	encryption *entryEncryptor // at-rest encryptor, nil disables encryption
+
+	tenants *tenantStats // per-host multi-tenant statistics, nil when disabled
+
+	metrics Metrics // host-injected metrics implementation, defaults to a no-op
+
+	decompressionBudget     *semaphore.Weighted // decompression memory budget (nil means unlimited)
+	decompressionBudgetSize int64               // total budget in bytes
+
+	deleteManyMaxScan int           // maximum keys scanned per DeleteMany call
+	deleteManyTimeout time.Duration // deadline for a single DeleteMany call
+
+	ttlOverrides    []ttlOverride   // response-header based TTL override rules
+	ttlOverrideFunc TTLOverrideFunc // programmatic TTL adjustment callback
+
+	etagIndex *etagIndex // reverse index from etag to key set
+
+	ownerIndex  *ownerIndex // reverse index from user identity to key set
+	ownerHeader string      // request header the user identity is extracted from
+	ownerCookie string      // cookie the user identity is extracted from
+
+	varyOptions varyOptions // Vary header normalization options
+
+	keyEncoding string // key escaping strategy used for file names
+
+	latency *latencyTracker // disk operation latency statistics and slow-op logging
+
+	scrub         scrubber      // state of the background integrity scrubber
+	scrubInterval time.Duration // integrity check interval, 0 disables it
+	scrubFraction float64       // fraction of entries checked per pass
+
+	auditLog *auditLog // audit log of destructive operations
+
+	adminReadToken  string // Bearer token for the admin read endpoints
+	adminWriteToken string // Bearer token for the admin write endpoints
+
+	pressure pressureMonitor // capacity watermark and eviction rate alerting
+	events   *eventNotifier  // cache event webhook notifier
+
+	hitStats         hitStats      // hit and miss counters
+	snapshotPath     string        // statistics snapshot file path, empty disables it
+	snapshotInterval time.Duration // statistics snapshot interval
+
+	selfTest bool // run a read/write self-test during initialization
+
+	syncWrites bool // fsync after writes, trading latency for crash durability
+
+	access accessTracker // last access time per key, used by sorted listings
+
+	maxVariants      int          // maximum variants per base key, 0 means unlimited
+	variantOverflows atomic.Int64 // variants evicted for exceeding the cap
+
+	rangeIdx *rangeIndex // range index for 206 response pieces
+
+	transcoded *transcodeCache // small cache of re-compressed variants
+	sizes      *sizeIndex      // in-memory index of compressed/uncompressed entry sizes
+
+	admission *admissionFilter // TinyLFU-style admission filter, nil admits everything
+
+	pins           *pinSet // pinned entries exempt from pressure eviction
+	maxPinnedBytes int64   // disk budget for pinned entries, 0 means unlimited
+
+	priorities     *priorityIndex // entry priority index, low priorities are evicted first
+	priorityRules  []priorityRule // key prefix to priority rules
+	priorityHeader string         // response header the priority is extracted from
+
+	compressionRules []compressionRule   // per content-type compression method rules
+	autoBypass       bool                // bypass compression for groups that compress poorly
+	ratioTracker     *compressionTracker // per content-type compression effectiveness statistics
+
+	originDown atomic.Bool   // whether the origin is marked unhealthy
+	maxGrace   time.Duration // maximum staleness served in grace mode, 0 disables it
+
+	accessCheckpoint time.Duration // access time checkpoint interval, 0 disables it
+	indexCheckpoint  time.Duration // key index checkpoint interval, 0 disables it
+
+	fs fileSystem // file system used by the data path, swappable in tests
+
+	readTimeout  time.Duration // per-read time limit, 0 means unlimited
+	writeTimeout time.Duration // per-write time limit, 0 means unlimited
+
+	highWatermark int // entry count high watermark triggering a background sweep, 0 disables it
+	lowWatermark  int // entry count low watermark the sweep shrinks back to
+
+	blockSize int64 // file system block size, usage is rounded up to it, 0 disables rounding
+
+	shardDepth int // sharded subdirectory depth, 0 keeps the flat layout
+
+	startupMode string // startup recovery mode: block, background or lazy
+
+	whenFull       string       // policy when the cache is full: evict, reject or reject-large
+	rejectedFills  atomic.Int64 // writes rejected by the when_full policy
+	whenFullEvicts atomic.Int64 // writes admitted through a policy-triggered eviction
+
+	ghosts           *ghostList   // ghost list of recently evicted keys, nil when disabled
+	evictionMistakes atomic.Int64 // keys requested again shortly after being evicted
+
+	evictWake          chan struct{} // channel waking up the background evictor
+	evictionsPerSecond int           // background eviction deletions per second cap
+	evictionOvershoot  int64         // soft overshoot in bytes allowed for writes
+}
+
+// acquireDecompressionBudget claims memory budget before decompressing, so a
+// burst of concurrent Gets cannot blow up memory. It returns the weight
+// actually acquired; callers must return it through releaseDecompressionBudget.
+func (provider *Simplefs) acquireDecompressionBudget(size int64) int64 {
+	if provider.decompressionBudget == nil {
+		return 0 // no budget configured, no limit applied
+	}
+
+	// Entries larger than the whole budget claim the full budget instead,
+	// so they cannot block forever.
+	if size > provider.decompressionBudgetSize {
+		size = provider.decompressionBudgetSize
+	}
+
+	// Block until enough budget is available.
+	_ = provider.decompressionBudget.Acquire(context.Background(), size)
+
+	return size
 }
 
-// onEvict 是一个回调函数，当缓存中的项目被驱逐时调用。
-func onEvict(path string) error {
-	return os.Remove(path) // 从文件系统中删除被驱逐的文件
+// releaseDecompressionBudget returns previously acquired memory budget.
+func (provider *Simplefs) releaseDecompressionBudget(size int64) {
+	if provider.decompressionBudget == nil || size == 0 {
+		return
+	}
+
+	provider.decompressionBudget.Release(size)
 }
 
-// Factory 函数创建新的 Simplefs 实例。
+// Factory function create new Simplefs instance.
 func Factory(simplefsCfg core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
 	var directorySize int64
 
+	var decompressionBudgetSize int64
+
+	deleteManyMaxScan := 100000           // default scan cap per call
+	deleteManyTimeout := 30 * time.Second // default per-call deadline
+
+	var ttlOverrides []ttlOverride
+
+	var varyOpts varyOptions
+
+	keyEncoding := keyEncodingPathEscape // default keeps the PathEscape behavior
+
+	slowOpThreshold := 500 * time.Millisecond // default slow-op logging threshold
+
+	var scrubInterval time.Duration // integrity checks are disabled by default
+
+	scrubFraction := 0.1 // default checks 10% of the entries per pass
+
+	evictionsPerSecond := 100 // default background eviction rate cap
+
+	var evictionOvershoot int64 // derived from directory_size by default
+
+	ownerHeader := "" // no user identity registration by default
+	ownerCookie := ""
+
+	auditLogPath := "" // audit entries only go to the log channel by default
+
+	adminReadToken := "" // no token check on the admin endpoints by default
+	adminWriteToken := ""
+
+	pressureWebhook := "" // pressure alerts only log by default
+
+	eventsWebhook := "" // no cache event delivery by default
+
+	snapshotPath := "" // no statistics snapshot file by default
+
+	selfTest := false // no startup self-test by default
+
+	syncWrites := false // no forced fsync by default
+
+	var maxVariants int // unlimited variants per base key by default
+
+	transcodeCacheEntries := 128 // default caches up to 128 re-compressed variants
+
+	var compressionRules []compressionRule // every content type uses the global method by default
+
+	autoBypass := false // no automatic compression bypass by default
+
+	var maxGrace time.Duration // grace mode is disabled by default
+
+	var accessCheckpoint time.Duration // access time checkpoints are disabled by default
+
+	indexCheckpoint := time.Minute // default flushes the key index every minute
+
+	var readTimeout, writeTimeout time.Duration // unlimited by default
+
+	highWatermark := 0 // entry count watermarks are disabled by default
+	lowWatermark := 0
+
+	blockSize := int64(4096) // default estimates disk usage in 4 KiB blocks
+
+	startupMode := startupBlock // default blocking startup recovery
+
+	ghostListSize := 0 // no ghost list of evicted keys by default
+
+	ghostListTTL := 5 * time.Minute // default "requested again shortly" window
+
+	whenFull := whenFullEvict // default keeps the evict-oldest behavior
+
+	var maxPinnedBytes int64 // unlimited pinned total by default
+
+	shardDepth := 0 // default keeps the flat layout
+
+	var priorityRules []priorityRule // every entry shares the same priority by default
+
+	priorityHeader := "" // no priority extraction from response headers by default
+
+	admissionMinHits := 0        // admission filtering is disabled by default
+	admissionSketchSize := 65536 // default number of frequency sketch counters
+
+	var admissionBypass []string // key prefixes exempt from admission checks
+
+	var snapshotInterval time.Duration
+
+	var evictionRateAlert int64 // no eviction rate alerting by default
+
 	// debug
-	logger.Infof("Debug, SimpleFS with GZIP and LZ4") // 修改 debug 日志
+	logger.Infof("Debug, SimpleFS with GZIP and LZ4")
+
+	storagePath := simplefsCfg.Path // get the storage path from the configuration
+	size := 0                       // default cache size
+	directorySize = -1              // default directory size is unlimited
+	compression := ""               // compression is disabled by default
+
+	compressionLevel := 0 // default uses the library compression level
+
+	var compressionDict []byte // no pre-trained dictionary by default
+
+	var encryption *entryEncryptor // no encryption by default
 
-	storagePath := simplefsCfg.Path // 从配置中获取存储路径
-	size := 0                       // 默认缓存大小
-	directorySize = -1              // 默认目录大小无限制
-	compression := ""               // 默认不启用压缩
+	tenantStatsEnabled := false // no per-host statistics by default
+
+	tenantStatsSegments := 0 // group by host only by default
 
 	simplefsConfiguration := simplefsCfg.Configuration
 	if simplefsConfiguration != nil {
 		if sfsconfig, ok := simplefsConfiguration.(map[string]interface{}); ok {
-			// 大小配置
+			// size configuration
 			if v, found := sfsconfig["size"]; found && v != nil {
 				if val, ok := v.(int); ok && val > 0 {
 					size = val
@@ -67,14 +291,14 @@ func Factory(simplefsCfg core.CacheProvider, logger core.Logger, stale time.Dura
 				}
 			}
 
-			// 路径配置
+			// path configuration
 			if v, found := sfsconfig["path"]; found && v != nil {
 				if val, ok := v.(string); ok {
 					storagePath = val
 				}
 			}
 
-			// 目录大小配置
+			// directory size configuration
 			if v, found := sfsconfig["directory_size"]; found && v != nil {
 				if val, ok := v.(int64); ok && val > 0 {
 					directorySize = val
@@ -86,10 +310,405 @@ func Factory(simplefsCfg core.CacheProvider, logger core.Logger, stale time.Dura
 					directorySize = int64(s)
 				}
 			}
-			// 压缩方法配置
+			// compression method configuration
 			if v, found := sfsconfig["compression"]; found && v != nil {
 				if val, ok := v.(string); ok {
-					compression = strings.ToLower(val) // 将压缩方法转换为小写
+					compression = strings.ToLower(val) // lowercase the compression method
+				}
+			}
+			// compression level configuration
+			if v, found := sfsconfig["compression_level"]; found && v != nil {
+				if val, ok := v.(int); ok {
+					compressionLevel = val
+				} else if val, ok := v.(float64); ok {
+					compressionLevel = int(val)
+				} else if val, ok := v.(string); ok {
+					compressionLevel, _ = strconv.Atoi(val)
+				}
+			}
+			// pre-trained compression dictionary configuration
+			if v, found := sfsconfig["compression_dictionary"]; found && v != nil {
+				if val, ok := v.(string); ok && val != "" {
+					dict, dictErr := os.ReadFile(val)
+					if dictErr != nil {
+						logger.Errorf("Impossible to read the compression dictionary %s: %v", val, dictErr)
+					} else {
+						compressionDict = dict
+					}
+				}
+			}
+			// per-host multi-tenant statistics configuration
+			if v, found := sfsconfig["tenant_stats"]; found && v != nil {
+				if val, ok := v.(bool); ok {
+					tenantStatsEnabled = val
+				}
+			}
+
+			if v, found := sfsconfig["tenant_stats_segments"]; found && v != nil {
+				if val, ok := v.(int); ok && val > 0 {
+					tenantStatsSegments = val
+				} else if val, ok := v.(float64); ok && val > 0 {
+					tenantStatsSegments = int(val)
+				} else if val, ok := v.(string); ok {
+					tenantStatsSegments, _ = strconv.Atoi(val)
+				}
+			}
+			// at-rest encryption configuration. A broken configuration is
+			// fatal instead of silently falling back to plaintext writes.
+			if v, found := sfsconfig["encryption"]; found && v != nil {
+				if cfg, ok := v.(map[string]interface{}); ok {
+					enc, encErr := parseEncryption(cfg, logger)
+					if encErr != nil {
+						logger.Errorf("Impossible to initialize the at-rest encryption: %v", encErr)
+
+						return nil, encErr
+					}
+
+					encryption = enc
+				}
+			}
+			// DeleteMany scan cap configuration
+			if v, found := sfsconfig["delete_many_max_scan"]; found && v != nil {
+				if val, ok := v.(int); ok && val > 0 {
+					deleteManyMaxScan = val
+				} else if val, ok := v.(float64); ok && val > 0 {
+					deleteManyMaxScan = int(val)
+				} else if val, ok := v.(string); ok {
+					deleteManyMaxScan, _ = strconv.Atoi(val)
+				}
+			}
+			// DeleteMany deadline configuration
+			if v, found := sfsconfig["delete_many_timeout"]; found && v != nil {
+				if val, ok := v.(string); ok && val != "" {
+					deleteManyTimeout, _ = time.ParseDuration(val)
+				}
+			}
+			// response-header based TTL override rules configuration
+			if v, found := sfsconfig["ttl_overrides"]; found && v != nil {
+				ttlOverrides = parseTTLOverrides(v)
+			}
+			// Vary header normalization options configuration
+			varyOpts = parseVaryOptions(sfsconfig)
+			// key escaping strategy configuration
+			if v, found := sfsconfig["key_encoding"]; found && v != nil {
+				if val, ok := v.(string); ok {
+					keyEncoding = strings.ToLower(val)
+				}
+			}
+			// user identity source configuration, used for per-user purges
+			if v, found := sfsconfig["owner_header"]; found && v != nil {
+				if val, ok := v.(string); ok {
+					ownerHeader = val
+				}
+			}
+
+			if v, found := sfsconfig["owner_cookie"]; found && v != nil {
+				if val, ok := v.(string); ok {
+					ownerCookie = val
+				}
+			}
+			// pressure alerting configuration
+			if v, found := sfsconfig["pressure_webhook"]; found && v != nil {
+				if val, ok := v.(string); ok {
+					pressureWebhook = val
+				}
+			}
+
+			// cache event webhook configuration
+			if v, found := sfsconfig["events_webhook"]; found && v != nil {
+				if val, ok := v.(string); ok {
+					eventsWebhook = val
+				}
+			}
+
+			if v, found := sfsconfig["eviction_rate_alert"]; found && v != nil {
+				if val, ok := v.(int); ok && val > 0 {
+					evictionRateAlert = int64(val)
+				} else if val, ok := v.(float64); ok && val > 0 {
+					evictionRateAlert = int64(val)
+				}
+			}
+			// per content-type compression policy configuration
+			if v, found := sfsconfig["compression_by_type"]; found && v != nil {
+				compressionRules = parseCompressionRules(v)
+			}
+			// access time checkpoint interval configuration
+			if v, found := sfsconfig["access_checkpoint"]; found && v != nil {
+				if val, ok := v.(string); ok && val != "" {
+					accessCheckpoint, _ = time.ParseDuration(val)
+				}
+			}
+			// key index checkpoint interval configuration, "0" disables it
+			if v, found := sfsconfig["index_checkpoint"]; found && v != nil {
+				if val, ok := v.(string); ok && val != "" {
+					indexCheckpoint, _ = time.ParseDuration(val)
+				}
+			}
+			// grace mode maximum staleness configuration
+			if v, found := sfsconfig["max_grace"]; found && v != nil {
+				if val, ok := v.(string); ok && val != "" {
+					maxGrace, _ = time.ParseDuration(val)
+				}
+			}
+			// disk read/write time limit configuration
+			if v, found := sfsconfig["read_timeout"]; found && v != nil {
+				if val, ok := v.(string); ok && val != "" {
+					readTimeout, _ = time.ParseDuration(val)
+				}
+			}
+
+			if v, found := sfsconfig["write_timeout"]; found && v != nil {
+				if val, ok := v.(string); ok && val != "" {
+					writeTimeout, _ = time.ParseDuration(val)
+				}
+			}
+			// entry count watermark configuration
+			if v, found := sfsconfig["high_watermark"]; found && v != nil {
+				if val, ok := v.(int); ok && val > 0 {
+					highWatermark = val
+				} else if val, ok := v.(float64); ok && val > 0 {
+					highWatermark = int(val)
+				}
+			}
+
+			if v, found := sfsconfig["low_watermark"]; found && v != nil {
+				if val, ok := v.(int); ok && val > 0 {
+					lowWatermark = val
+				} else if val, ok := v.(float64); ok && val > 0 {
+					lowWatermark = int(val)
+				}
+			}
+			// TinyLFU admission filter configuration
+			if v, found := sfsconfig["admission_min_hits"]; found && v != nil {
+				if val, ok := v.(int); ok && val > 0 {
+					admissionMinHits = val
+				} else if val, ok := v.(float64); ok && val > 0 {
+					admissionMinHits = int(val)
+				}
+			}
+
+			if v, found := sfsconfig["admission_sketch_size"]; found && v != nil {
+				if val, ok := v.(int); ok && val > 0 {
+					admissionSketchSize = val
+				} else if val, ok := v.(float64); ok && val > 0 {
+					admissionSketchSize = int(val)
+				}
+			}
+
+			if v, found := sfsconfig["admission_bypass"]; found && v != nil {
+				if val, ok := v.(string); ok && val != "" {
+					for _, prefix := range strings.Split(val, ",") {
+						if prefix = strings.TrimSpace(prefix); prefix != "" {
+							admissionBypass = append(admissionBypass, prefix)
+						}
+					}
+				} else if val, ok := v.([]interface{}); ok {
+					for _, entry := range val {
+						if prefix, ok := entry.(string); ok && prefix != "" {
+							admissionBypass = append(admissionBypass, prefix)
+						}
+					}
+				}
+			}
+			// entry priority configuration
+			if v, found := sfsconfig["priorities"]; found && v != nil {
+				priorityRules = parsePriorityRules(v)
+			}
+
+			if v, found := sfsconfig["priority_header"]; found && v != nil {
+				if val, ok := v.(string); ok {
+					priorityHeader = val
+				}
+			}
+			// sharded subdirectory depth configuration
+			if v, found := sfsconfig["shard_depth"]; found && v != nil {
+				if val, ok := v.(int); ok && val > 0 {
+					shardDepth = val
+				} else if val, ok := v.(float64); ok && val > 0 {
+					shardDepth = int(val)
+				}
+
+				if shardDepth > maxShardDepth {
+					logger.Warnf("shard_depth %d exceeds the limit, truncated to %d", shardDepth, maxShardDepth)
+					shardDepth = maxShardDepth
+				}
+			}
+			// pinned entries budget configuration
+			if v, found := sfsconfig["max_pinned_bytes"]; found && v != nil {
+				if val, ok := v.(int64); ok && val > 0 {
+					maxPinnedBytes = val
+				} else if val, ok := v.(float64); ok && val > 0 {
+					maxPinnedBytes = int64(val)
+				} else if val, ok := v.(string); ok && val != "" {
+					s, _ := humanize.ParseBytes(val)
+					//nolint:gosec
+					maxPinnedBytes = int64(s)
+				}
+			}
+			// ghost list of evicted keys configuration
+			if v, found := sfsconfig["ghost_list"]; found && v != nil {
+				if val, ok := v.(int); ok && val > 0 {
+					ghostListSize = val
+				} else if val, ok := v.(float64); ok && val > 0 {
+					ghostListSize = int(val)
+				} else if val, ok := v.(string); ok {
+					ghostListSize, _ = strconv.Atoi(val)
+				}
+			}
+
+			if v, found := sfsconfig["ghost_list_ttl"]; found && v != nil {
+				if val, ok := v.(string); ok && val != "" {
+					ghostListTTL, _ = time.ParseDuration(val)
+				}
+			}
+			// startup recovery mode configuration
+			if v, found := sfsconfig["startup"]; found && v != nil {
+				if val, ok := v.(string); ok {
+					switch strings.ToLower(val) {
+					case startupBlock, startupBackground, startupLazy:
+						startupMode = strings.ToLower(val)
+					default:
+						logger.Warnf("Unknown startup mode %q, keeping the default %s", val, startupBlock)
+					}
+				}
+			}
+			// behavior when the cache is full configuration
+			if v, found := sfsconfig["when_full"]; found && v != nil {
+				if val, ok := v.(string); ok {
+					switch strings.ToLower(val) {
+					case whenFullEvict, whenFullReject, whenFullRejectLarge:
+						whenFull = strings.ToLower(val)
+					default:
+						logger.Warnf("Unknown when_full policy %q, keeping the default %s", val, whenFullEvict)
+					}
+				}
+			}
+			// file system block size configuration, 0 disables block rounding
+			if v, found := sfsconfig["fs_block_size"]; found && v != nil {
+				if val, ok := v.(int); ok && val >= 0 {
+					blockSize = int64(val)
+				} else if val, ok := v.(float64); ok && val >= 0 {
+					blockSize = int64(val)
+				}
+			}
+			// automatic compression bypass configuration
+			if v, found := sfsconfig["compression_auto_bypass"]; found && v != nil {
+				if val, ok := v.(bool); ok {
+					autoBypass = val
+				}
+			}
+			// re-compressed variant cache capacity configuration
+			if v, found := sfsconfig["transcode_cache_entries"]; found && v != nil {
+				if val, ok := v.(int); ok && val >= 0 {
+					transcodeCacheEntries = val
+				} else if val, ok := v.(float64); ok && val >= 0 {
+					transcodeCacheEntries = int(val)
+				} else if val, ok := v.(string); ok {
+					transcodeCacheEntries, _ = strconv.Atoi(val)
+				}
+			}
+			// variant cap configuration
+			if v, found := sfsconfig["max_variants"]; found && v != nil {
+				if val, ok := v.(int); ok && val > 0 {
+					maxVariants = val
+				} else if val, ok := v.(float64); ok && val > 0 {
+					maxVariants = int(val)
+				} else if val, ok := v.(string); ok {
+					maxVariants, _ = strconv.Atoi(val)
+				}
+			}
+			// startup self-test configuration
+			if v, found := sfsconfig["self_test"]; found && v != nil {
+				if val, ok := v.(bool); ok {
+					selfTest = val
+				}
+			}
+			// write durability configuration: fsync every write when enabled
+			if v, found := sfsconfig["sync_writes"]; found && v != nil {
+				if val, ok := v.(bool); ok {
+					syncWrites = val
+				}
+			}
+			// statistics snapshot configuration
+			if v, found := sfsconfig["stats_snapshot"]; found && v != nil {
+				if val, ok := v.(string); ok {
+					snapshotPath = val
+				}
+			}
+
+			if v, found := sfsconfig["stats_snapshot_interval"]; found && v != nil {
+				if val, ok := v.(string); ok && val != "" {
+					snapshotInterval, _ = time.ParseDuration(val)
+				}
+			}
+			// admin endpoint token configuration
+			if v, found := sfsconfig["admin_read_token"]; found && v != nil {
+				if val, ok := v.(string); ok {
+					adminReadToken = val
+				}
+			}
+
+			if v, found := sfsconfig["admin_write_token"]; found && v != nil {
+				if val, ok := v.(string); ok {
+					adminWriteToken = val
+				}
+			}
+			// audit log file configuration
+			if v, found := sfsconfig["audit_log"]; found && v != nil {
+				if val, ok := v.(string); ok {
+					auditLogPath = val
+				}
+			}
+			// slow-op logging threshold configuration
+			if v, found := sfsconfig["slow_op_threshold"]; found && v != nil {
+				if val, ok := v.(string); ok && val != "" {
+					slowOpThreshold, _ = time.ParseDuration(val)
+				}
+			}
+			// background eviction rate configuration
+			if v, found := sfsconfig["evictions_per_second"]; found && v != nil {
+				if val, ok := v.(int); ok && val > 0 {
+					evictionsPerSecond = val
+				} else if val, ok := v.(float64); ok && val > 0 {
+					evictionsPerSecond = int(val)
+				} else if val, ok := v.(string); ok {
+					evictionsPerSecond, _ = strconv.Atoi(val)
+				}
+			}
+			// soft write overshoot configuration
+			if v, found := sfsconfig["eviction_overshoot"]; found && v != nil {
+				if val, ok := v.(int64); ok && val > 0 {
+					evictionOvershoot = val
+				} else if val, ok := v.(float64); ok && val > 0 {
+					evictionOvershoot = int64(val)
+				} else if val, ok := v.(string); ok && val != "" {
+					s, _ := humanize.ParseBytes(val)
+					//nolint:gosec
+					evictionOvershoot = int64(s)
+				}
+			}
+			// integrity check interval configuration
+			if v, found := sfsconfig["scrub_interval"]; found && v != nil {
+				if val, ok := v.(string); ok && val != "" {
+					scrubInterval, _ = time.ParseDuration(val)
+				}
+			}
+			// fraction of entries checked per integrity pass configuration
+			if v, found := sfsconfig["scrub_fraction"]; found && v != nil {
+				if val, ok := v.(float64); ok && val > 0 && val <= 1 {
+					scrubFraction = val
+				}
+			}
+			// decompression memory budget configuration
+			if v, found := sfsconfig["decompression_budget"]; found && v != nil {
+				if val, ok := v.(int64); ok && val > 0 {
+					decompressionBudgetSize = val
+				} else if val, ok := v.(float64); ok && val > 0 {
+					decompressionBudgetSize = int64(val)
+				} else if val, ok := v.(string); ok && val != "" {
+					s, _ := humanize.ParseBytes(val)
+					//nolint:gosec
+					decompressionBudgetSize = int64(s)
 				}
 			}
 		}
@@ -97,347 +716,759 @@ func Factory(simplefsCfg core.CacheProvider, logger core.Logger, stale time.Dura
 
 	var err error
 
-	// 如果没有配置路径，则回退到当前工作目录
+	// Fall back to the current working directory when no path is configured.
 	if storagePath == "" {
-		logger.Info("未提供配置路径，回退到当前工作目录。")
+		logger.Info("No configuration path given, fallback to the current working directory.")
 
 		storagePath, err = os.Getwd()
 		if err != nil {
-			logger.Errorf("无法初始化此工作目录中的存储路径: %#v", err)
+			logger.Errorf("Impossible to initialize the storage path in this working directory: %#v", err)
 
 			return nil, err
 		}
 	}
 
-	// 初始化 TTL 缓存
-	cache := ttlcache.New(
+	// The low watermark defaults to 90% of the high watermark when unset.
+	if highWatermark > 0 && (lowWatermark <= 0 || lowWatermark >= highWatermark) {
+		lowWatermark = highWatermark * 9 / 10
+	}
+
+	// Initialize the TTL cache. With watermarks configured there is no hard
+	// capacity cap: inserts are never dropped for capacity, the background
+	// sweep shrinks the entry count back to the low watermark instead.
+	cacheOptions := []ttlcache.Option[string, []byte]{}
+	if highWatermark <= 0 {
 		//nolint:gosec
-		ttlcache.WithCapacity[string, []byte](uint64(size)),
-	)
+		cacheOptions = append(cacheOptions, ttlcache.WithCapacity[string, []byte](uint64(size)))
+	}
+
+	cache := ttlcache.New(cacheOptions...)
 
 	if cache == nil {
-		err = errors.New("无法初始化 simplefs 存储。")
+		err = errors.New("Impossible to initialize the simplefs storage.")
 		logger.Error(err)
 
 		return nil, err
 	}
 
-	// 创建存储目录，如果不存在
+	// Create the storage directory if it doesn't exist.
 	if err := os.MkdirAll(storagePath, 0o777); err != nil {
-		logger.Errorf("无法创建存储目录: %#v", err)
+		logger.Errorf("Impossible to create the storage directory: %#v", err)
 
 		return nil, err
 	}
 
-	logger.Infof("如果需要，已创建存储目录 %s", storagePath)
+	logger.Infof("Created the storage directory %s if needed", storagePath)
+
+	// Check the storage path plus the worst-case file name against the
+	// platform limits, falling back to hashed file names when needed.
+	keyEncoding = validateKeyEncodingForPath(storagePath, keyEncoding, logger)
 
-	go cache.Start() // 启动 TTL 缓存
+	go cache.Start() // start the TTL cache
 
-	// 返回新创建的 Simplefs 实例
+	// Initialize the weighted semaphore when a decompression budget is configured.
+	var decompressionBudget *semaphore.Weighted
+	if decompressionBudgetSize > 0 {
+		decompressionBudget = semaphore.NewWeighted(decompressionBudgetSize)
+	}
+
+	// Initialize the TinyLFU admission filter when a threshold is configured.
+	var admission *admissionFilter
+	if admissionMinHits > 0 {
+		admission = newAdmissionFilter(admissionMinHits, admissionSketchSize, admissionBypass)
+	}
+
+	// Return the freshly created Simplefs instance.
 	return &Simplefs{
-		cache:         cache,
-		directorySize: directorySize,
-		logger:        logger,
-		mu:            sync.Mutex{},
-		path:          storagePath,
-		size:          size,
-		stale:         stale,
-		compression:   compression, // 保存压缩选项
+		cache:                   cache,
+		charges:                 map[string]int64{},
+		directorySize:           directorySize,
+		logger:                  logger,
+		mu:                      sync.Mutex{},
+		path:                    storagePath,
+		size:                    size,
+		stale:                   stale,
+		compression:             compression, // keep the compression option
+		compressionLevel:        compressionLevel,
+		compressionDict:         compressionDict,
+		encryption:              encryption,
+		tenants:                 newTenantStats(tenantStatsEnabled, tenantStatsSegments),
+		metrics:                 nopMetrics{},
+		decompressionBudget:     decompressionBudget,
+		decompressionBudgetSize: decompressionBudgetSize,
+		deleteManyMaxScan:       deleteManyMaxScan,
+		deleteManyTimeout:       deleteManyTimeout,
+		ttlOverrides:            ttlOverrides,
+		etagIndex:               newEtagIndex(),
+		ownerIndex:              newOwnerIndex(),
+		auditLog:                newAuditLog(auditLogPath, logger),
+		adminReadToken:          adminReadToken,
+		adminWriteToken:         adminWriteToken,
+		pressure:                pressureMonitor{webhook: pressureWebhook, evictionAlert: evictionRateAlert},
+		events:                  newEventNotifier(eventsWebhook, logger),
+		snapshotPath:            snapshotPath,
+		snapshotInterval:        snapshotInterval,
+		selfTest:                selfTest,
+		syncWrites:              syncWrites,
+		maxVariants:             maxVariants,
+		rangeIdx:                newRangeIndex(),
+		transcoded:              newTranscodeCache(transcodeCacheEntries),
+		sizes:                   newSizeIndex(),
+		admission:               admission,
+		compressionRules:        compressionRules,
+		autoBypass:              autoBypass,
+		ratioTracker:            newCompressionTracker(),
+		maxGrace:                maxGrace,
+		accessCheckpoint:        accessCheckpoint,
+		indexCheckpoint:         indexCheckpoint,
+		readTimeout:             readTimeout,
+		writeTimeout:            writeTimeout,
+		highWatermark:           highWatermark,
+		lowWatermark:            lowWatermark,
+		blockSize:               blockSize,
+		whenFull:                whenFull,
+		startupMode:             startupMode,
+		ghosts:                  newGhostList(ghostListSize, ghostListTTL),
+		pins:                    newPinSet(),
+		shardDepth:              shardDepth,
+		priorities:              newPriorityIndex(),
+		priorityRules:           priorityRules,
+		priorityHeader:          priorityHeader,
+		maxPinnedBytes:          maxPinnedBytes,
+		fs:                      osFS{},
+		ownerHeader:             ownerHeader,
+		ownerCookie:             ownerCookie,
+		varyOptions:             varyOpts,
+		keyEncoding:             keyEncoding,
+		latency:                 newLatencyTracker(slowOpThreshold, logger),
+		scrubInterval:           scrubInterval,
+		scrubFraction:           scrubFraction,
+		evictWake:               make(chan struct{}, 1),
+		evictionsPerSecond:      evictionsPerSecond,
+		evictionOvershoot:       evictionOvershoot,
 	}, nil
 }
 
-// Name 返回存储器的名称。
+// Name returns the storer name.
 func (provider *Simplefs) Name() string {
 	return "SIMPLEFS"
 }
 
-// Uuid 返回唯一标识符。
+// Uuid returns an unique identifier.
 func (provider *Simplefs) Uuid() string {
 	return fmt.Sprintf("%s-%d", provider.path, provider.size)
 }
 
-// MapKeys 方法返回带有键和值的 map。
+// MapKeys method returns a map with the key and value.
 func (provider *Simplefs) MapKeys(prefix string) map[string]string {
 	keys := map[string]string{}
 
-	// 遍历缓存并收集带有前缀的键
+	// Iterate over the cache and collect the prefixed keys.
 	provider.cache.Range(func(item *ttlcache.Item[string, []byte]) bool {
 		if strings.HasPrefix(item.Key(), prefix) {
 			k, _ := strings.CutPrefix(item.Key(), prefix)
-			keys[k] = string(item.Value()) // 将文件路径存储为值
+
+			// Entries pointing at a physical file return structured metadata
+			// instead of the internal file path; other entries (mapping keys,
+			// surrogate keys...) keep their raw value.
+			if meta, ok := provider.entryMeta(item); ok {
+				keys[k] = encodeEntryMeta(meta)
+			} else {
+				keys[k] = string(item.Value())
+			}
 		}
 
-		return true // 继续迭代
+		return true // continue the iteration
 	})
 
 	return keys
 }
 
-// ListKeys 方法返回现有键的列表。
+// ListKeys method returns the list of existing keys.
 func (provider *Simplefs) ListKeys() []string {
 	return provider.cache.Keys()
 }
 
-// Get 方法返回存储在 Simplefs 中与键对应的响应。
+// ListKeysWithPrefix method returns only the keys with the given prefix, so
+// callers don't pull the whole key space and filter it themselves for a
+// single host/path.
+func (provider *Simplefs) ListKeysWithPrefix(prefix string) []string {
+	keys := []string{}
+
+	// Iterate over the cache and collect the prefixed keys.
+	provider.cache.Range(func(item *ttlcache.Item[string, []byte]) bool {
+		if strings.HasPrefix(item.Key(), prefix) {
+			keys = append(keys, item.Key())
+		}
+
+		return true // continue the iteration
+	})
+
+	return keys
+}
+
+// Get method returns the populated response if exists, empty response then.
 func (provider *Simplefs) Get(key string) []byte {
-	result := provider.cache.Get(key) // 从缓存中获取文件路径
+	result := provider.cache.Get(key) // get the file path from the cache
 	if result == nil {
-		provider.logger.Warnf("无法在 Simplefs 中获取键 %s", key)
+		// In lazy startup mode, try to adopt an entry file already on disk.
+		result = provider.adoptEntry(key)
+	}
+
+	if result == nil {
+		// Requested right after being evicted: count it as an eviction
+		// mistake and boost the admission priority of the next fill.
+		if provider.ghosts.hit(key) {
+			provider.evictionMistakes.Add(1)
+
+			if provider.admission != nil {
+				provider.admission.boost(key)
+			}
+		}
 
-		return nil // 缓存中未找到键
+		provider.logger.Warnf("Impossible to get the key %s in Simplefs", key)
+
+		return nil // key not found in the cache
 	}
 
 	filePath := string(result.Value())
 
-	byteValue, err := os.ReadFile(filePath) // 从文件系统读取文件
+	provider.touchAccess(key) // record the access time for access-sorted listings
+
+	readStart := time.Now()
+
+	byteValue, err := provider.readEntryFile(filePath) // read the file from the file system (with optional time limit)
+
+	provider.latency.observe("read", key, len(byteValue), time.Since(readStart))
+	provider.metrics.ObserveGet(time.Since(readStart))
+
 	if err != nil {
-		provider.logger.Errorf("无法从 Simplefs 读取文件 %s: %#v", filePath, err)
+		provider.logger.Errorf("Impossible to read the file %s from Simplefs: %#v", filePath, err)
 
-		return result.Value() // 如果读取文件失败，则返回文件路径 (回退)
+		return result.Value() // reading the file failed, return the file path (fallback)
+	}
+
+	// With at-rest encryption enabled, decrypt first; plaintext entries
+	// written before encryption was enabled pass through untouched.
+	if provider.encryption != nil {
+		byteValue, err = provider.encryption.open(byteValue)
+		if err != nil {
+			provider.logger.Errorf("Impossible to decrypt the entry for the key %s: %v", key, err)
+
+			return nil
+		}
 	}
 
 	var decompressedData []byte
 
-	switch provider.compression {
+	// Claim memory budget before decompressing, keeping the peak memory of
+	// concurrent decompressions predictable. The claim uses the recorded
+	// uncompressed size when available, so highly compressible entries
+	// cannot trade a tiny disk footprint for a huge memory spike.
+	budget := int64(len(byteValue))
+	if recorded, found := provider.sizes.lookup(key); found && recorded.Uncompressed > budget {
+		budget = recorded.Uncompressed
+	}
+
+	acquired := provider.acquireDecompressionBudget(budget)
+	defer provider.releaseDecompressionBudget(acquired)
+
+	// With per content-type compression rules the same directory mixes
+	// several formats: prefer the entry file header to identify the method,
+	// legacy files fall back to magic byte sniffing.
+	method, byteValue := provider.resolveEntryMethod(byteValue)
+
+	switch method {
 	case "lz4":
-		provider.logger.Debugf("尝试使用 lz4 解压缩键 %s", key)
+		provider.logger.Debugf("Trying to decompress the key %s with lz4", key)
 		r := lz4.NewReader(bytes.NewReader(byteValue))
 		decompressedData, err = io.ReadAll(r)
 		if err != nil {
-			provider.logger.Errorf("无法使用 lz4 解压缩键 %s 的数据: %v", key, err)
-			return nil // 解压缩失败，返回 nil
+			provider.logger.Errorf("Impossible to decompress the data with lz4 for the key %s: %v", key, err)
+			return nil // decompression failed, return nil
 		}
-	case "gzip": // 添加 gzip 解压缩
-		provider.logger.Debugf("尝试使用 gzip 解压缩键 %s", key)
-		r, err := gzip.NewReader(bytes.NewReader(byteValue))
+	case "gzip", methodGzipDict: // gzip decompression
+		provider.logger.Debugf("Trying to decompress the key %s with gzip", key)
+		r, err := provider.entryGzipReader(bytes.NewReader(byteValue), method == methodGzipDict)
 		if err != nil {
-			provider.logger.Errorf("无法创建 gzip 解压缩读取器: %v", err)
-			return nil // 解压缩失败，返回 nil
+			provider.logger.Errorf("Impossible to create the gzip decompression reader: %v", err)
+			return nil // decompression failed, return nil
 		}
 		defer r.Close()
 		decompressedData, err = io.ReadAll(r)
 		if err != nil {
-			provider.logger.Errorf("无法使用 gzip 解压缩键 %s 的数据: %v", key, err)
-			return nil // 解压缩失败，返回 nil
+			provider.logger.Errorf("Impossible to decompress the data with gzip for the key %s: %v", key, err)
+			return nil // decompression failed, return nil
 		}
-	case "": // 未压缩的情况
-		provider.logger.Debugf("键 %s 未使用压缩", key)
+	case "", "none": // uncompressed entry
+		provider.logger.Debugf("No compression used for the key %s", key)
 		decompressedData = byteValue
 	default:
-		provider.logger.Errorf("不支持的压缩方法: %s", provider.compression)
-		return nil // 不支持的压缩方法，返回 nil
+		provider.logger.Errorf("Unsupported compression method: %s", method)
+		return nil // unsupported compression method, return nil
 	}
 
 	return decompressedData
 
 }
 
-// GetMultiLevel 尝试加载键并检查其中一个链接键是否为 fresh/stale 候选者。
+// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
 func (provider *Simplefs) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
-	// 从缓存中获取映射键
+	// When the request carries a trace ID, every log line of this operation carries it too.
+	logger := provider.requestLogger(req)
+
+	// Get the mapping key from the cache.
 	val := provider.cache.Get(core.MappingKeyPrefix + key)
 	if val == nil {
-		provider.logger.Errorf("无法在 Simplefs 中获取映射键 %s", core.MappingKeyPrefix+key)
+		// In lazy startup mode, try to restore the mapping from the mappings directory.
+		val = provider.adoptMapping(core.MappingKeyPrefix + key)
+	}
+
+	if val == nil {
+		logger.Errorf("Impossible to get the mapping key %s in Simplefs", core.MappingKeyPrefix+key)
+		provider.hitStats.misses.Add(1)
+		provider.tenants.recordMiss(key)
+		provider.metrics.CacheMiss()
+
+		return fresh, stale // mapping key not found
+	}
 
-		return fresh, stale // 未找到映射键
+	// Record the compression method used by this backend in debug mode.
+	if provider.compression != "" {
+		core.RecordDecision(req, "Compression", provider.compression)
 	}
 
-	// 基于映射执行 fresh/stale 选举
-	fresh, stale, _ = core.MappingElection(provider, val.Value(), req, validator, provider.logger)
+	// Run the fresh/stale election over the mapping, normalizing the request headers first.
+	fresh, stale, _ = core.MappingElection(provider, val.Value(), provider.varyOptions.canonicalRequest(req), validator, logger)
+
+	// When the origin is unhealthy, elect expired entries within the grace
+	// window instead of reporting a miss.
+	if fresh == nil && stale == nil && provider.maxGrace > 0 && provider.originDown.Load() {
+		stale = provider.graceElection(val.Value(), provider.varyOptions.canonicalRequest(req))
+	}
+
+	if fresh != nil || stale != nil {
+		provider.hitStats.hits.Add(1)
+		provider.tenants.recordHit(key)
+		provider.metrics.CacheHit()
+		provider.notifyTraced("hit", key, 0, TraceIDFromRequest(req)) // hit event, lets subscribers track hit rates
+	} else {
+		provider.hitStats.misses.Add(1)
+		provider.tenants.recordMiss(key)
+		provider.metrics.CacheMiss()
+	}
 
 	return fresh, stale
 }
 
-// recoverEnoughSpaceIfNeeded 在存储新项目之前检查并回收足够的磁盘空间（如果需要）。
-func (provider *Simplefs) recoverEnoughSpaceIfNeeded(size int64) {
-	// 检查是否强制了目录大小限制，以及存储是否会超出限制
-	if provider.directorySize > -1 && provider.actualSize+size > provider.directorySize {
-		// 反向迭代缓存（LRU 顺序）
-		provider.cache.RangeBackwards(func(item *ttlcache.Item[string, []byte]) bool {
-			// 如果没有足够的空间，则删除最旧的项目。
-			//nolint:godox
-			// TODO: 打开 PR 以公开一个在 LRU 项目上迭代的范围。
-			provider.cache.Delete(string(item.Value())) // 从缓存（和物理文件）中删除项目
+// SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
+func (provider *Simplefs) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	now := core.Now() // injectable clock for deterministic time behavior in tests
 
-			return false // 删除一个项目后停止 (可以调整为删除更多项目)
-		})
+	// Admission filtering: a key not seen often enough isn't worth the disk
+	// write yet; it is only persisted once it shows up again past the threshold.
+	if provider.admission != nil && !provider.admission.admit(variedKey) {
+		provider.logger.Debugf("The key %s did not pass the admission filter, skipping the disk write", variedKey)
 
-		provider.recoverEnoughSpaceIfNeeded(size) // 在删除项目后递归调用自身
+		return nil
 	}
-}
 
-// SetMultiLevel 将响应存储到 Simplefs 中，并更新映射键以存储元数据。
-func (provider *Simplefs) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
-	now := time.Now()
+	// Apply the response-header based TTL override rules.
+	duration = provider.overrideTTL(value, duration)
 
-	var compressed bytes.Buffer
-	var w *lz4.Writer   // 在 if 块外声明 lz4 压缩写入器
-	var zw *gzip.Writer // 在 if 块外声明 gzip 压缩写入器
-	var err error
+	// Normalize the Vary-related request headers before writing the mapping.
+	variedHeaders = provider.varyOptions.canonicalHeaders(variedHeaders)
 
-	// 根据压缩选项压缩数据
-	switch provider.compression {
-	case "gzip": // 添加 gzip 压缩
-		provider.logger.Debugf("尝试使用 gzip 压缩键 %s", variedKey)
-		zw, err = gzip.NewWriterLevel(&compressed, gzip.DefaultCompression) // 使用默认压缩级别
-		if err != nil {
-			provider.logger.Errorf("无法为键 %s 创建 gzip 压缩写入器: %v", variedKey, err)
-			return err
-		}
-		defer zw.Close()
-		if _, err = zw.Write(value); err != nil {
-			provider.logger.Errorf("无法使用 gzip 压缩键 %s 的数据: %v", variedKey, err)
-			return err
-		}
-	case "lz4", "": // "lz4" 或 不压缩 (默认为 "lz4" 以保持向后兼容)
-		provider.logger.Debugf("尝试使用 lz4 压缩键 %s", variedKey)
-		w = lz4.NewWriter(&compressed)
-		defer w.Close()
-		_, err = w.ReadFrom(bytes.NewReader(value))
-		if err != nil {
-			provider.logger.Errorf("无法使用 lz4 压缩键 %s 的数据: %v", variedKey, err)
-			return err
-		}
-	default:
-		provider.logger.Warnf("未知的压缩方法: %s, 不进行压缩存储", provider.compression)
-		compressed.Write(value) // 如果方法未知，则不压缩存储
+	// Pick the compression method from the content-type rules or the global configuration.
+	method := provider.compressionFor(value)
+
+	// Check the space against the uncompressed upper bound: when full, evict
+	// or reject according to the when_full policy.
+	if !provider.admitWrite(int64(len(value))) {
+		provider.logger.Debugf("Cache full and when_full=%s, rejecting the write for the key %s", provider.whenFull, variedKey)
+		provider.notify("reject", variedKey, int64(len(value)))
+
+		return nil
 	}
 
-	provider.recoverEnoughSpaceIfNeeded(int64(compressed.Len())) // 如果需要，回收磁盘空间
+	joinedFP := provider.entryFilePath(variedKey) // join the directory path and the safe file name
 
-	joinedFP := filepath.Join(provider.path, url.PathEscape(variedKey)) // 连接目录路径和转义后的键
-	//nolint:gosec
-	if err := os.WriteFile(joinedFP, compressed.Bytes(), 0o644); err != nil {
-		provider.logger.Errorf("无法将文件 %s 写入 Simplefs: %#v", variedKey, err)
+	writeStart := time.Now()
 
-		return nil // 写入文件失败
+	// Compression streams straight into the target file through io.Copy, the
+	// body is never buffered again as a compressed copy in memory.
+	compressedLen, _, writeErr := provider.compressToFileTimed(joinedFP, method, bytes.NewReader(value))
+
+	provider.latency.observe("write", variedKey, int(compressedLen), time.Since(writeStart))
+	provider.metrics.ObserveSet(time.Since(writeStart))
+
+	if writeErr != nil {
+		provider.logger.Errorf("Impossible to write the file %s in Simplefs: %#v", variedKey, writeErr)
+
+		return nil // writing the file failed
+	}
+
+	// Report the compression ratio achieved by this write.
+	if len(value) > 0 {
+		provider.metrics.CompressionRatio(float64(compressedLen) / float64(len(value)))
+	}
+
+	// Record the ratio achieved for this content-type group, feeding the
+	// automatic bypass decision.
+	if provider.autoBypass && method != "none" {
+		provider.ratioTracker.observe(contentTypeGroup(value), len(value), int(compressedLen))
 	}
 
-	_ = provider.cache.Set(variedKey, []byte(joinedFP), duration) // 将文件路径存储到缓存中，并设置 TTL
+	// Record the compressed/uncompressed sizes for Meta and the Get memory budget.
+	provider.sizes.record(variedKey, compressedLen, int64(len(value)))
 
-	// 更新映射键
+	// Register the entry priority, low priorities are evicted first.
+	provider.priorities.set(variedKey, provider.priorityFor(variedKey, value))
+
+	// With grace mode configured, extend the cache entry lifetime so expired
+	// entries remain electable within the grace window.
+	cacheTTL := duration
+	if provider.maxGrace > 0 {
+		cacheTTL = duration + provider.stale + provider.maxGrace
+	}
+
+	_ = provider.setEntryFile(variedKey, joinedFP, cacheTTL) // store the file path in the cache with synchronous accounting, set the TTL
+
+	provider.etagIndex.add(etag, variedKey)                                      // update the etag reverse index
+	provider.ownerIndex.add(provider.ownerFromHeaders(variedHeaders), variedKey) // register the user identity ownership
+	provider.tenants.recordWrite(variedKey, compressedLen)                       // per-host write statistics
+	provider.notify("set", variedKey, compressedLen)                             // set event
+
+	// Update the mapping key.
 	mappingKey := core.MappingKeyPrefix + baseKey
 	item := provider.cache.Get(mappingKey)
 	if item == nil {
-		provider.logger.Warnf("无法在 Simplefs 中找到映射键 %s", mappingKey)
+		provider.logger.Warnf("Impossible to find the mapping key %s in Simplefs", mappingKey)
+
+		item = &ttlcache.Item[string, []byte]{} // create a new item when the mapping key is missing
+	}
 
-		item = &ttlcache.Item[string, []byte]{} // 如果未找到映射键，则创建新项目
+	// Extract the Last-Modified from the stored response headers, persisted
+	// in the mapping metadata alongside the ETag.
+	lastModified := ""
+	if headers := storedResponseHeaders(value); headers != nil {
+		lastModified = headers.Get("Last-Modified")
 	}
 
-	// 更新映射元数据
-	val, e := core.MappingUpdater(variedKey, item.Value(), provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	// Update the mapping metadata.
+	val, e := core.MappingUpdaterWithLastModified(variedKey, item.Value(), provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, lastModified, realKey)
 	if e != nil {
-		return e // 更新映射失败
+		return e // updating the mapping failed
 	}
 
-	provider.logger.Debugf("在 Simplefs 中为键 %s 存储新的映射", variedKey)
-	// 用于计算 -(now * 2)
+	val = provider.enforceVariantCap(val) // evict the least recently used variants past the cap
+
+	provider.logger.Debugf("Store the new mapping for the key %s in Simplefs", variedKey)
+	// Used to calculate -(now * 2)
 	negativeNow, err := time.ParseDuration(fmt.Sprintf("-%ds", time.Now().Nanosecond()*2))
 	if err != nil {
-		return fmt.Errorf("无法生成持续时间: %w", err) // 无法生成负持续时间
+		return fmt.Errorf("Impossible to generate the duration: %w", err)
 	}
 
-	_ = provider.cache.Set(mappingKey, val, negativeNow) // 将更新后的映射键存储回缓存，并设置负 TTL (使其立即过期)
+	_ = provider.setRawValue(mappingKey, val, negativeNow) // store the updated mapping key back with a negative TTL (it expires immediately)
 
-	return nil // 成功存储项目和映射键
+	provider.persistMapping(mappingKey, val) // persist the mapping, the election works right after a restart
+
+	return nil // item and mapping key stored successfully
 }
 
-// Set 方法将响应存储在 Simplefs 提供程序中。
+// Set method will store the response in Simplefs provider.
 func (provider *Simplefs) Set(key string, value []byte, duration time.Duration) error {
-	_ = provider.cache.Set(key, value, duration) // 将项目存储到缓存中并设置 TTL
+	_ = provider.setRawValue(key, value, duration) // store the item in the cache with the TTL
 
 	return nil
 }
 
-// Delete 方法将删除 Simplefs 提供程序中与 key 参数对应的响应（如果存在）。
+// PurgeReport describes what a delete operation actually removed, so the
+// Souin purge API and the admin endpoints can confirm the deletion outcome.
+type PurgeReport struct {
+	Keys       []string // removed keys
+	FreedBytes int64    // freed bytes
+}
+
+// Count returns the number of removed keys.
+func (r PurgeReport) Count() int {
+	return len(r.Keys)
+}
+
+// Delete method will delete the response in Simplefs provider if exists corresponding to key param.
 func (provider *Simplefs) Delete(key string) {
-	provider.cache.Delete(key) // 从缓存中删除项目
+	provider.audit("delete", key, "", provider.DeleteWithReport(key))
+}
+
+// DeleteWithReport deletes the key and reports the removed keys and freed bytes.
+func (provider *Simplefs) DeleteWithReport(key string) PurgeReport {
+	report := PurgeReport{}
+
+	item := provider.cache.Get(key, ttlcache.WithDisableTouchOnHit[string, []byte]())
+	if item == nil {
+		return report // the key doesn't exist, nothing to delete
+	}
+
+	// Prefer the physical file size, fall back to the cached value size.
+	if info, err := provider.fs.Stat(string(item.Value())); err == nil {
+		report.FreedBytes = info.Size()
+	} else {
+		report.FreedBytes = int64(len(item.Value()))
+	}
+
+	provider.deleteKey(key) // synchronously remove the cache item, the charge and the backing file
+	provider.notify("purge", key, report.FreedBytes)
+	report.Keys = append(report.Keys, key)
+
+	return report
 }
 
-// DeleteMany 方法将删除 Simplefs 提供程序中与 regex key 参数对应的多个响应（如果存在）。
+// measureKey reports what deleting the given key would free without deleting
+// it, for the dry-run mode.
+func (provider *Simplefs) measureKey(key string) PurgeReport {
+	report := PurgeReport{}
+
+	item := provider.cache.Get(key, ttlcache.WithDisableTouchOnHit[string, []byte]())
+	if item == nil {
+		return report // the key doesn't exist
+	}
+
+	// Prefer the physical file size, fall back to the cached value size.
+	if info, err := provider.fs.Stat(string(item.Value())); err == nil {
+		report.FreedBytes = info.Size()
+	} else {
+		report.FreedBytes = int64(len(item.Value()))
+	}
+
+	report.Keys = append(report.Keys, key)
+
+	return report
+}
+
+// maxDeleteManyPatternLength caps the DeleteMany regular expression length
+// against maliciously long patterns.
+const maxDeleteManyPatternLength = 1024
+
+// DeleteMany method will delete the responses in Simplefs provider if exists corresponding to the regex key param.
 func (provider *Simplefs) DeleteMany(key string) {
-	rgKey, e := regexp.Compile(key) // 编译正则表达式键
+	report, _ := provider.DeleteManyWithReport(key)
+
+	provider.audit("delete_many", key, "", report)
+}
+
+// DeleteManyWithReport deletes the keys matching the regular expression and
+// reports the removed keys and freed bytes.
+func (provider *Simplefs) DeleteManyWithReport(key string) (PurgeReport, string) {
+	report := &PurgeReport{}
+	next := provider.deleteManyFrom(key, "", report, false)
+
+	return *report, next
+}
+
+// DeleteManyDryRun reports the keys matching the regular expression and the
+// bytes a deletion would free without deleting anything, so operators can
+// validate the pattern before purging production.
+func (provider *Simplefs) DeleteManyDryRun(key string) (PurgeReport, string) {
+	report := &PurgeReport{}
+	next := provider.deleteManyFrom(key, "", report, true)
+
+	return *report, next
+}
+
+// DeleteManyFrom scans in lexicographic order starting at the after key
+// (inclusive) and deletes the keys matching the regular expression. A single
+// call is bounded by the scan cap and the deadline; when it stops early it
+// returns the last scanned key, which the caller passes back as after to
+// resume. It returns an empty string once the scan is complete.
+func (provider *Simplefs) DeleteManyFrom(key string, after string) string {
+	return provider.deleteManyFrom(key, after, &PurgeReport{}, false)
+}
+
+// deleteManyFrom is the scan-and-delete logic shared by the DeleteMany family,
+// accumulating into report. With dryRun set it only counts the matching keys
+// and bytes without deleting.
+func (provider *Simplefs) deleteManyFrom(key string, after string, report *PurgeReport, dryRun bool) string {
+	// Reject overlong patterns to avoid compiling pathological regular expressions.
+	if len(key) > maxDeleteManyPatternLength {
+		provider.logger.Warnf("Rejecting a DeleteMany pattern longer than %d", maxDeleteManyPatternLength)
+
+		return ""
+	}
+
+	rgKey, e := regexp.Compile(key) // compile the regular expression key
 	if e != nil {
-		return // 正则表达式无效，忽略
+		return "" // invalid regular expression, ignore it
 	}
 
-	// 遍历缓存并删除键与正则表达式匹配的项目
-	provider.cache.Range(func(item *ttlcache.Item[string, []byte]) bool {
-		if rgKey.MatchString(item.Key()) {
-			provider.Delete(item.Key()) // 如果键与正则表达式匹配，则删除项目
+	deadline := time.Now().Add(provider.deleteManyTimeout)
+
+	var scanned int
+
+	var next string
+
+	// Keys actually deleted by this call, used to prune the mapping
+	// references once the scan is done.
+	deleted := map[string]bool{}
+
+	// Scan over a sorted snapshot of the keys: the cache-internal iteration
+	// order shifts with touch-on-hit, only a lexicographic resume guarantees
+	// every key is checked exactly once across calls, and deleting the
+	// resume key itself doesn't lose track of the following ones.
+	keys := provider.cache.Keys()
+	sort.Strings(keys)
+
+	for _, storedKey := range keys {
+		// Skip the keys already processed by the previous call (the resume
+		// key itself has not been processed yet and is included).
+		if after != "" && storedKey < after {
+			continue
 		}
 
-		return true // 继续迭代
-	})
-}
+		// Scan cap or deadline reached: record the first unprocessed key and stop.
+		if scanned >= provider.deleteManyMaxScan || time.Now().After(deadline) {
+			next = storedKey
+			provider.logger.Warnf("DeleteMany stopped early after scanning %d keys, it can resume from the key %s", scanned, next)
 
-// Init 方法将在启动时初始化 Simplefs 提供程序。
-func (provider *Simplefs) Init() error {
-	// 在每次将项目插入缓存时调用的回调
-	provider.cache.OnInsertion(func(_ context.Context, item *ttlcache.Item[string, []byte]) {
-		if strings.Contains(item.Key(), core.MappingKeyPrefix) {
-			return // 忽略映射键
+			break
+		}
+
+		scanned++
+
+		if rgKey.MatchString(storedKey) {
+			// The key matches the regular expression: delete (or just
+			// measure) the item and accumulate the report.
+			var itemReport PurgeReport
+			if dryRun {
+				itemReport = provider.measureKey(storedKey)
+			} else {
+				itemReport = provider.DeleteWithReport(storedKey)
+			}
+
+			report.Keys = append(report.Keys, itemReport.Keys...)
+			report.FreedBytes += itemReport.FreedBytes
+
+			if !dryRun {
+				for _, removedKey := range itemReport.Keys {
+					deleted[removedKey] = true
+				}
+			}
 		}
+	}
 
-		// 获取文件信息以计算实际大小
-		info, err := os.Stat(string(item.Value()))
+	// Prune the mappings still referencing deleted variants, dropping the
+	// mappings left empty.
+	if len(deleted) > 0 {
+		provider.pruneMappings(deleted)
+	}
+
+	return next
+}
+
+// Init method will initialize the Simplefs provider at startup.
+func (provider *Simplefs) Init() error {
+	// Run the startup self-test when configured, surfacing configuration or
+	// disk problems before taking traffic.
+	if provider.selfTest {
+		report, err := provider.SelfTest()
 		if err != nil {
-			provider.logger.Errorf("无法获取文件大小 %s: %#v", item.Key(), err)
+			provider.logger.Errorf("The startup self-test failed: %v", err)
 
-			return // 获取文件信息失败
+			return err
 		}
 
-		// 更新实际大小并记录调试日志
-		provider.mu.Lock()
-		provider.actualSize += info.Size()
-		provider.logger.Debugf("实际大小增加: %d, 总计: %d 字节", info.Size(), provider.actualSize)
-		provider.mu.Unlock()
-	})
+		provider.logger.Infof("Startup self-test passed: write %v, read %v, %d bytes free", report.WriteLatency, report.ReadLatency, report.FreeBytes)
+	}
 
-	// 在每次从缓存中驱逐项目时调用的回调
-	provider.cache.OnEviction(func(_ context.Context, _ ttlcache.EvictionReason, item *ttlcache.Item[string, []byte]) {
-		if strings.Contains(string(item.Value()), core.MappingKeyPrefix) {
-			return // 忽略映射键
+	// Callback invoked on every cache eviction. It runs asynchronously in
+	// its own goroutine, so it only performs idempotent auxiliary index
+	// cleanup; accounting and file removal happen on the synchronous delete
+	// paths, with only the cache-internal expiry and capacity evictions
+	// handled here as a backstop.
+	provider.cache.OnEviction(func(_ context.Context, reason ttlcache.EvictionReason, item *ttlcache.Item[string, []byte]) {
+		provider.etagIndex.removeKey(item.Key())  // drop the etag reverse index entry
+		provider.ownerIndex.removeKey(item.Key()) // drop the user identity reverse index entry
+		provider.access.drop(item.Key())          // drop the access record
+		provider.sizes.drop(item.Key())           // drop the size record
+		provider.pins.remove(item.Key())          // drop the pin record
+		provider.priorities.drop(item.Key())      // drop the priority record
+		provider.rangeIdx.removePiece(item.Key()) // drop the range piece index entry
+		provider.transcoded.drop(item.Key())      // drop the re-compressed variants
+		provider.noteEviction()                   // count the eviction rate
+
+		// Explicit deletions (evictOne, DeleteWithReport...) already rolled
+		// back the accounting and removed the file synchronously on the
+		// delete path, nothing left to do here.
+		if reason == ttlcache.EvictionReasonDeleted {
+			return
 		}
-		// 获取文件信息以更新实际大小
-		info, err := os.Stat(string(item.Value()))
-		if err != nil {
-			provider.logger.Errorf("无法获取文件大小 %s: %#v", item.Key(), err)
 
-			return // 获取文件信息失败
+		path := ""
+		if !strings.Contains(item.Key(), core.MappingKeyPrefix) {
+			path = string(item.Value())
 		}
 
-		// 更新实际大小并记录调试日志
-		provider.mu.Lock()
-		provider.actualSize -= info.Size()
-		provider.logger.Debugf("实际大小减少: %d, 总计: %d 字节", info.Size(), provider.actualSize)
-		provider.mu.Unlock()
+		freed := provider.discardKeyData(item.Key(), path)
 
-		// 调用 onEvict 函数删除物理文件
-		if err := onEvict(string(item.Value())); err != nil {
-			provider.logger.Errorf("无法删除文件 %s: %#v", item.Key(), err)
+		if path != "" {
+			provider.tenants.recordEviction(item.Key())
+			provider.ghosts.add(item.Key()) // feed the ghost list to detect eviction mistakes
+			provider.metrics.Eviction()
+			provider.notify("evict", item.Key(), freed)
 		}
 	})
 
-	// 从给定目录中的文件重新生成 simplefs 缓存。
-	files, _ := os.ReadDir(provider.path)
-	provider.logger.Debugf("从给定目录中的文件重新生成 simplefs 缓存。")
+	// Restore the state according to the configured startup mode: block
+	// restores synchronously, background rebuilds the index in the
+	// background while serving from a cold cache, lazy skips the full scan
+	// and adopts disk files on first access.
+	switch provider.startupMode {
+	case startupBackground:
+		go provider.restoreState()
+	case startupLazy:
+		provider.logger.Info("lazy startup mode: skipping the startup recovery, existing entries are adopted on first access")
+	default:
+		provider.restoreState()
+	}
 
-	for _, f := range files {
-		if !f.IsDir() {
-			info, _ := f.Info()
-			provider.actualSize += info.Size() // 从现有文件计算实际大小
-			provider.logger.Debugf("向实际大小添加 %v 字节，总计 %v 字节。", info.Size(), provider.actualSize)
-		}
+	// Periodically flush the key index checkpoint (runs in every startup mode).
+	provider.startIndexCheckpoint(provider.indexCheckpoint)
+
+	// Start the background integrity scrubber when configured.
+	provider.startScrubber(provider.scrubInterval, provider.scrubFraction)
+
+	// Start the periodic statistics snapshots when configured.
+	provider.startSnapshots(provider.snapshotPath, provider.snapshotInterval)
+
+	// Restore and keep flushing the access times so the post-restart
+	// eviction order stays sensible, when configured.
+	if provider.accessCheckpoint > 0 {
+		provider.loadAccessCheckpoint()
+		provider.startAccessCheckpoint(provider.accessCheckpoint)
+	}
+
+	// The soft overshoot defaults to 5% of the directory size.
+	if provider.evictionOvershoot <= 0 && provider.directorySize > 0 {
+		provider.evictionOvershoot = provider.directorySize / 20
 	}
 
-	return nil // 初始化成功
+	go provider.evictionLoop() // start the background evictor
+
+	return nil // initialization succeeded
 }
 
-// Reset 方法将重置或关闭提供程序。
+// Reset method will reset or close provider.
 func (provider *Simplefs) Reset() error {
-	provider.cache.DeleteAll() // 删除缓存中的所有项目
-	// TODO: 如果需要，添加从存储目录中删除所有文件的功能
+	provider.audit("reset", "", "", PurgeReport{Keys: provider.cache.Keys()})
+
+	provider.cache.DeleteAll() // delete every item in the cache
+
+	// Reset the accounting, consistent with the emptied cache.
+	provider.mu.Lock()
+	provider.charges = map[string]int64{}
+	provider.actualSize = 0
+	provider.mu.Unlock()
+	// TODO: add the removal of every file in the storage directory if needed
 
-	return nil // 重置成功
+	return nil // reset succeeded
 }
@@ -0,0 +1,68 @@
+package simplefs
+
+import "sync"
+
+// entrySizes records the size of one entry before and after compression.
+type entrySizes struct {
+	Compressed   int64 `json:"compressed"`
+	Uncompressed int64 `json:"uncompressed"`
+}
+
+// sizeIndex keeps the pre- and post-compression sizes of each entry in
+// memory. The disk only holds the compressed bytes; without this record Get
+// could only claim the compressed size from the memory budget, letting a
+// highly compressed entry (a decompression bomb) bypass the budget and
+// exhaust memory.
+type sizeIndex struct {
+	mu                sync.Mutex
+	entries           map[string]entrySizes
+	totalUncompressed int64
+}
+
+// newSizeIndex creates an empty size index.
+func newSizeIndex() *sizeIndex {
+	return &sizeIndex{entries: map[string]entrySizes{}}
+}
+
+// record registers the pre- and post-compression sizes of an entry, rolling
+// back the previous record when the same key is written again.
+func (index *sizeIndex) record(key string, compressed, uncompressed int64) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	if existing, found := index.entries[key]; found {
+		index.totalUncompressed -= existing.Uncompressed
+	}
+
+	index.entries[key] = entrySizes{Compressed: compressed, Uncompressed: uncompressed}
+	index.totalUncompressed += uncompressed
+}
+
+// lookup returns the recorded sizes of an entry.
+func (index *sizeIndex) lookup(key string) (entrySizes, bool) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	sizes, found := index.entries[key]
+
+	return sizes, found
+}
+
+// drop cleans the size record when an entry is deleted or evicted.
+func (index *sizeIndex) drop(key string) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	if existing, found := index.entries[key]; found {
+		index.totalUncompressed -= existing.Uncompressed
+		delete(index.entries, key)
+	}
+}
+
+// uncompressedTotal returns the sum of the decompressed sizes of every entry.
+func (index *sizeIndex) uncompressedTotal() int64 {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	return index.totalUncompressed
+}
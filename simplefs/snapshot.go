@@ -0,0 +1,162 @@
+package simplefs
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jellydator/ttlcache/v3"
+)
+
+// hitStats counts the GetMultiLevel hits and misses, feeding the hit ratio.
+type hitStats struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// prefixUsage is the footprint of a single key prefix in a snapshot.
+type prefixUsage struct {
+	Prefix string `json:"prefix"`
+	Count  int    `json:"count"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// StatsSnapshot is the statistics snapshot periodically written to disk, so
+// operators without Prometheus can still graph the cache over time and
+// inspect the last state after a crash.
+type StatsSnapshot struct {
+	Time          time.Time     `json:"time"`
+	Count         int           `json:"count"`
+	ActualSize    int64         `json:"actual_size"`
+	DirectorySize int64         `json:"directory_size"`
+	Hits          int64         `json:"hits"`
+	Misses        int64         `json:"misses"`
+	Uncompressed  int64         `json:"uncompressed_bytes"`
+	RejectedFills int64         `json:"rejected_fills"`    // writes rejected by the when_full policy
+	EvictMistakes int64         `json:"eviction_mistakes"` // keys requested again shortly after their eviction
+	HitRatio      float64       `json:"hit_ratio"`
+	TopPrefixes   []prefixUsage `json:"top_prefixes"`
+	Tenants       []tenantUsage `json:"tenants,omitempty"` // grouped by host when tenant_stats is enabled
+	Scrub         ScrubStats    `json:"scrub"`
+}
+
+// Snapshot aggregates the current statistics snapshot.
+func (provider *Simplefs) Snapshot() StatsSnapshot {
+	provider.mu.Lock()
+	actualSize := provider.actualSize
+	provider.mu.Unlock()
+
+	snapshot := StatsSnapshot{
+		Time:          time.Now(),
+		Count:         provider.cache.Len(),
+		ActualSize:    actualSize,
+		DirectorySize: provider.directorySize,
+		Hits:          provider.hitStats.hits.Load(),
+		Misses:        provider.hitStats.misses.Load(),
+		Uncompressed:  provider.sizes.uncompressedTotal(),
+		RejectedFills: provider.rejectedFills.Load(),
+		EvictMistakes: provider.evictionMistakes.Load(),
+		Scrub:         provider.ScrubberStats(),
+	}
+
+	if total := snapshot.Hits + snapshot.Misses; total > 0 {
+		snapshot.HitRatio = float64(snapshot.Hits) / float64(total)
+	}
+
+	snapshot.TopPrefixes = provider.topPrefixes(10)
+	snapshot.Tenants = provider.tenants.snapshot()
+
+	return snapshot
+}
+
+// topPrefixes aggregates the key prefixes (method-scheme-host) by occupied
+// bytes, returning the top limit ones.
+func (provider *Simplefs) topPrefixes(limit int) []prefixUsage {
+	usage := map[string]*prefixUsage{}
+
+	provider.cache.Range(func(item *ttlcache.Item[string, []byte]) bool {
+		meta, isFile := provider.entryMeta(item)
+		if !isFile {
+			return true // mapping keys do not count towards the prefix statistics
+		}
+
+		prefix := keyPrefix(meta.Key)
+		if usage[prefix] == nil {
+			usage[prefix] = &prefixUsage{Prefix: prefix}
+		}
+
+		usage[prefix].Count++
+		usage[prefix].Bytes += meta.Size
+
+		return true
+	})
+
+	prefixes := make([]prefixUsage, 0, len(usage))
+	for _, entry := range usage {
+		prefixes = append(prefixes, *entry)
+	}
+
+	sort.Slice(prefixes, func(i, j int) bool {
+		return prefixes[i].Bytes > prefixes[j].Bytes
+	})
+
+	if len(prefixes) > limit {
+		prefixes = prefixes[:limit]
+	}
+
+	return prefixes
+}
+
+// keyPrefix takes the first three segments of a key (e.g.
+// GET-https-example.com) as the grouping prefix.
+func keyPrefix(key string) string {
+	parts := strings.SplitN(key, "-", 4)
+	if len(parts) < 4 {
+		return key
+	}
+
+	return strings.Join(parts[:3], "-")
+}
+
+// writeSnapshot atomically writes the snapshot to the configured file
+// (temporary file then rename).
+func (provider *Simplefs) writeSnapshot(path string) {
+	payload, err := json.MarshalIndent(provider.Snapshot(), "", "  ")
+	if err != nil {
+		return
+	}
+
+	tmpPath := path + ".tmp"
+	if err := provider.fs.WriteFile(tmpPath, payload, 0o644); err != nil {
+		provider.logger.Errorf("Impossible to write the statistics snapshot %s: %v", tmpPath, err)
+
+		return
+	}
+
+	if err := provider.fs.Rename(tmpPath, path); err != nil {
+		provider.logger.Errorf("Impossible to replace the statistics snapshot %s: %v", path, err)
+	}
+}
+
+// startSnapshots starts the periodic statistics snapshot loop, an empty path
+// disables it.
+func (provider *Simplefs) startSnapshots(path string, interval time.Duration) {
+	if path == "" {
+		return
+	}
+
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			provider.writeSnapshot(path)
+		}
+	}()
+}
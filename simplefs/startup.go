@@ -0,0 +1,102 @@
+package simplefs
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/jellydator/ttlcache/v3"
+)
+
+// Values of the startup mode: blocking restore (the default), serving from a
+// cold cache while the index rebuilds in the background, or no full scan at
+// all with disk files adopted on first access.
+const (
+	startupBlock      = "block"
+	startupBackground = "background"
+	startupLazy       = "lazy"
+)
+
+// restoreState runs the startup restore: migrate the layout, restore the key
+// index and the mappings, rebuild the size accounting. The block mode calls
+// it synchronously from Init, the background mode from a background goroutine.
+func (provider *Simplefs) restoreState() {
+	// With the sharded layout migrate the old flat files first, the rest of
+	// the restore looks entries up at their sharded path.
+	provider.migrateFlatLayout()
+
+	// Restore the entry keys and their remaining TTL from the index
+	// checkpoint, making the entries reachable again after a restart.
+	provider.loadIndexCheckpoint()
+
+	// Restore the persisted mappings from the mappings directory, so
+	// GetMultiLevel works right after the restart.
+	provider.loadMappings()
+
+	// The restore paths already charged every restored key synchronously.
+	// Scan the directory to reconcile the disk usage: anything beyond the
+	// ledger is orphan files no key references anymore, only warn without
+	// charging the budget — eviction cannot reclaim them and charging them
+	// would keep the cache "full" forever.
+	size := provider.scanDirSize(provider.path, true)
+
+	provider.mu.Lock()
+	tracked := provider.actualSize
+	provider.mu.Unlock()
+
+	if size > tracked {
+		provider.logger.Warnf("%d bytes on disk are not referenced by any restored key", size-tracked)
+	}
+}
+
+// adoptEntry adopts an existing entry file from disk on first access in the
+// lazy startup mode. The original TTL is unknowable, so the entry is adopted
+// with the stale window; old flat-layout files are migrated to the sharded
+// path along the way. Other modes return nil.
+func (provider *Simplefs) adoptEntry(key string) *ttlcache.Item[string, []byte] {
+	if provider.startupMode != startupLazy {
+		return nil
+	}
+
+	path := provider.entryFilePath(key)
+
+	if _, err := provider.fs.Stat(path); err != nil {
+		flat := filepath.Join(provider.path, provider.safeFileName(key))
+		if flat == path {
+			return nil
+		}
+
+		if _, statErr := provider.fs.Stat(flat); statErr != nil {
+			return nil
+		}
+
+		if renameErr := provider.fs.Rename(flat, path); renameErr != nil {
+			return nil
+		}
+	}
+
+	provider.logger.Debugf("lazy startup: adopting the key %s on first access", key)
+
+	return provider.setEntryFile(key, path, provider.stale)
+}
+
+// adoptMapping restores a single mapping key from the mappings directory on
+// first access in the lazy startup mode.
+func (provider *Simplefs) adoptMapping(mappingKey string) *ttlcache.Item[string, []byte] {
+	if provider.startupMode != startupLazy {
+		return nil
+	}
+
+	content, err := provider.fs.ReadFile(provider.mappingFilePath(mappingKey))
+	if err != nil {
+		return nil
+	}
+
+	storedKey, value, ok := decodeMappingEnvelope(content)
+	if !ok || storedKey != mappingKey {
+		return nil
+	}
+
+	// Same as loadMappings: an expired mapping still inside the grace window
+	// is restored with a negative TTL.
+	return provider.setRawValue(mappingKey, value, -time.Nanosecond)
+}
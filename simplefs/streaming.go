@@ -0,0 +1,299 @@
+package simplefs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pierrec/lz4/v4"
+)
+
+// countingWriter wraps a writer and counts the written bytes.
+type countingWriter struct {
+	target  io.Writer
+	written int64
+}
+
+// Write forwards the write and accumulates the byte count.
+func (writer *countingWriter) Write(p []byte) (int, error) {
+	n, err := writer.target.Write(p)
+	writer.written += int64(n)
+
+	return n, err
+}
+
+// streamReader combines a (possibly decompression-wrapped) reader with a
+// custom close function.
+type streamReader struct {
+	io.Reader
+	closeFunc func() error
+}
+
+// Close closes the underlying resources.
+func (reader streamReader) Close() error {
+	return reader.closeFunc()
+}
+
+// compressToFile streams source compressed with method into path: a temporary
+// file in the same directory is written first then atomically renamed, the
+// compression happens through io.Copy and the body is never fully buffered in
+// memory. It returns the compressed and uncompressed byte counts. Like
+// GetFile, this path relies on a real *os.File; with an injected filesystem or
+// encryption at rest enabled (the AEAD needs the full plaintext) it falls back
+// to the buffered write.
+func (provider *Simplefs) compressToFile(path, method string, source io.Reader) (int64, int64, error) {
+	if _, isOS := provider.fs.(osFS); !isOS || provider.encryption != nil {
+		return provider.compressToFileBuffered(path, method, source)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".simplefs-*")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	tmpPath := file.Name()
+
+	discard := func(cause error) (int64, int64, error) {
+		_ = file.Close()
+		_ = os.Remove(tmpPath)
+
+		return 0, 0, cause
+	}
+
+	counter := &countingWriter{target: file}
+
+	// Compressed entries lead with a header the read path uses to identify the compression method.
+	if header := provider.entryHeaderFor(method); header != nil {
+		if _, err := counter.Write(header); err != nil {
+			return discard(err)
+		}
+	}
+
+	// finish flushes the compression stream (writes the trailing block), nil means written as-is.
+	destination, finish, err := provider.newCompressionWriter(method, counter)
+	if err != nil {
+		return discard(err)
+	}
+
+	read, err := io.Copy(destination, source)
+	if err != nil {
+		if finish != nil {
+			_ = finish.Close()
+		}
+
+		return discard(err)
+	}
+
+	if finish != nil {
+		if err := finish.Close(); err != nil {
+			return discard(err)
+		}
+	}
+
+	// sync_writes forces the bytes to disk.
+	if provider.syncWrites {
+		if err := file.Sync(); err != nil {
+			return discard(err)
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+
+		return 0, 0, err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+
+		return 0, 0, err
+	}
+
+	return counter.written, read, nil
+}
+
+// compressToFileBuffered is the fallback with an injected filesystem:
+// compress in memory then write in one shot.
+func (provider *Simplefs) compressToFileBuffered(path, method string, source io.Reader) (int64, int64, error) {
+	data, err := io.ReadAll(source)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var compressed bytes.Buffer
+
+	counter := &countingWriter{target: &compressed}
+
+	if header := provider.entryHeaderFor(method); header != nil {
+		if _, err := counter.Write(header); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	destination, finish, err := provider.newCompressionWriter(method, counter)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if _, err := destination.Write(data); err != nil {
+		return 0, 0, err
+	}
+
+	if finish != nil {
+		if err := finish.Close(); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	output := compressed.Bytes()
+
+	// With encryption at rest enabled the whole payload is encrypted after compression.
+	if provider.encryption != nil {
+		sealed, sealErr := provider.encryption.seal(output)
+		if sealErr != nil {
+			return 0, 0, sealErr
+		}
+
+		output = sealed
+	}
+
+	if err := provider.fs.WriteFile(path, output, 0o644); err != nil {
+		return 0, 0, err
+	}
+
+	return int64(len(output)), int64(len(data)), nil
+}
+
+// compressToFileTimed is the watchdog wrapper around compressToFile, behaving
+// like writeEntryFileTimed: stop waiting once write_timeout is exceeded.
+func (provider *Simplefs) compressToFileTimed(path, method string, source io.Reader) (int64, int64, error) {
+	if provider.writeTimeout <= 0 {
+		return provider.compressToFile(path, method, source)
+	}
+
+	type writeResult struct {
+		compressed   int64
+		uncompressed int64
+		err          error
+	}
+
+	done := make(chan writeResult, 1)
+
+	go func() {
+		compressed, uncompressed, err := provider.compressToFile(path, method, source)
+		done <- writeResult{compressed: compressed, uncompressed: uncompressed, err: err}
+	}()
+
+	timer := time.NewTimer(provider.writeTimeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-done:
+		return result.compressed, result.uncompressed, result.err
+	case <-timer.C:
+		provider.logger.Errorf("Abandoned the write of the file %s after %s", path, provider.writeTimeout)
+
+		return 0, 0, errOpTimeout
+	}
+}
+
+// SetReader stores an entry as a stream: the body flows from source straight
+// to disk through a compressing io.Copy, suited to response bodies of
+// hundreds of megabytes, with a memory footprint independent of the body
+// size. The entry uses the globally configured compression method (streaming
+// writes cannot sniff the content).
+func (provider *Simplefs) SetReader(key string, source io.Reader, duration time.Duration) error {
+	method := provider.compression
+
+	joinedFP := provider.entryFilePath(key)
+
+	writeStart := time.Now()
+
+	compressedLen, uncompressedLen, err := provider.compressToFileTimed(joinedFP, method, source)
+
+	provider.latency.observe("write", key, int(compressedLen), time.Since(writeStart))
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the file %s: %v", key, err)
+
+		return err
+	}
+
+	provider.sizes.record(key, compressedLen, uncompressedLen)
+
+	_ = provider.setEntryFile(key, joinedFP, duration)
+
+	provider.ensureCapacity(0) // check the space pressure after a write, waking the evictor if needed
+	provider.notify("set", key, compressedLen)
+
+	return nil
+}
+
+// GetReader returns an io.ReadCloser streaming the entry body: the disk bytes
+// are read chunk by chunk through the decompression stream, without
+// decompressing the whole body into memory like Get does. The caller is
+// responsible for closing the returned reader.
+func (provider *Simplefs) GetReader(key string) (io.ReadCloser, error) {
+	item := provider.cache.Get(key)
+	if item == nil {
+		return nil, errors.New("impossible to find the key " + key + " in Simplefs")
+	}
+
+	provider.touchAccess(key)
+
+	// Encrypted entries cannot be streamed before the authentication completes, fall back to the buffered read.
+	if provider.encryption != nil {
+		content := provider.Get(key)
+		if content == nil {
+			return nil, errors.New("impossible to read the encrypted entry for the key " + key)
+		}
+
+		return io.NopCloser(bytes.NewReader(content)), nil
+	}
+
+	file, err := os.Open(string(item.Value()))
+	if err != nil {
+		return nil, err
+	}
+
+	// Sniff the entry header to identify the compression method actually used,
+	// legacy files fall back to magic-byte sniffing.
+	header := make([]byte, entryHeaderLen)
+	n, _ := io.ReadFull(file, header)
+
+	method := detectCompression(header[:n], provider.compression)
+	offset := int64(0)
+
+	if m, _, ok := splitEntryHeader(header[:n]); ok {
+		method, offset = m, entryHeaderLen
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		_ = file.Close()
+
+		return nil, err
+	}
+
+	switch method {
+	case "lz4":
+		return streamReader{Reader: lz4.NewReader(file), closeFunc: file.Close}, nil
+	case "gzip", methodGzipDict:
+		zr, err := provider.entryGzipReader(file, method == methodGzipDict)
+		if err != nil {
+			_ = file.Close()
+
+			return nil, err
+		}
+
+		return streamReader{Reader: zr, closeFunc: func() error {
+			_ = zr.Close()
+
+			return file.Close()
+		}}, nil
+	default:
+		return file, nil
+	}
+}
@@ -0,0 +1,158 @@
+package simplefs
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// tenantCounters holds the running counters of a single group (the host by default).
+type tenantCounters struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Bytes     int64 `json:"bytes"` // compressed bytes written
+	Evictions int64 `json:"evictions"`
+}
+
+// tenantUsage is the statistics entry of a single group in a snapshot.
+type tenantUsage struct {
+	Tenant string `json:"tenant"`
+	tenantCounters
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// tenantStats aggregates the hits, misses, written bytes and evictions per
+// host (or per configured number of key prefix segments), so multi-site
+// operators can see the cache consumption and the hit ratio of each site.
+// nil means disabled, every method is a no-op on a nil receiver.
+type tenantStats struct {
+	mu       sync.Mutex
+	segments int // key prefix segments used for the grouping, 0 groups by host only
+	groups   map[string]*tenantCounters
+}
+
+// newTenantStats creates the multi-tenant statistics, returning nil when disabled.
+func newTenantStats(enabled bool, segments int) *tenantStats {
+	if !enabled {
+		return nil
+	}
+
+	return &tenantStats{segments: segments, groups: map[string]*tenantCounters{}}
+}
+
+// group derives the group name from a key. Keys look like
+// GET-https-example.com-/path: by default the third segment (the host) is
+// used, with a configured segment count the first segments are.
+func (stats *tenantStats) group(key string) string {
+	parts := strings.SplitN(key, "-", 4)
+
+	if stats.segments > 0 {
+		if len(parts) > stats.segments {
+			return strings.Join(parts[:stats.segments], "-")
+		}
+
+		return key
+	}
+
+	if len(parts) < 3 {
+		return key
+	}
+
+	return parts[2]
+}
+
+// counters returns the counters of a group, created on demand. The caller
+// must hold the lock.
+func (stats *tenantStats) counters(key string) *tenantCounters {
+	name := stats.group(key)
+
+	counters := stats.groups[name]
+	if counters == nil {
+		counters = &tenantCounters{}
+		stats.groups[name] = counters
+	}
+
+	return counters
+}
+
+// recordHit records one hit.
+func (stats *tenantStats) recordHit(key string) {
+	if stats == nil {
+		return
+	}
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	stats.counters(key).Hits++
+}
+
+// recordMiss records one miss.
+func (stats *tenantStats) recordMiss(key string) {
+	if stats == nil {
+		return
+	}
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	stats.counters(key).Misses++
+}
+
+// recordWrite records one write and its compressed byte count.
+func (stats *tenantStats) recordWrite(key string, size int64) {
+	if stats == nil {
+		return
+	}
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	counters := stats.counters(key)
+	counters.Bytes += size
+}
+
+// recordEviction records one eviction.
+func (stats *tenantStats) recordEviction(key string) {
+	if stats == nil {
+		return
+	}
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	stats.counters(key).Evictions++
+}
+
+// snapshot returns the per-group statistics sorted by written bytes, descending.
+func (stats *tenantStats) snapshot() []tenantUsage {
+	if stats == nil {
+		return nil
+	}
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	usage := make([]tenantUsage, 0, len(stats.groups))
+
+	for name, counters := range stats.groups {
+		entry := tenantUsage{Tenant: name, tenantCounters: *counters}
+		if total := counters.Hits + counters.Misses; total > 0 {
+			entry.HitRatio = float64(counters.Hits) / float64(total)
+		}
+
+		usage = append(usage, entry)
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Bytes > usage[j].Bytes
+	})
+
+	return usage
+}
+
+// TenantStats returns the statistics grouped by host (or by the configured
+// prefix), nil when disabled.
+func (provider *Simplefs) TenantStats() []tenantUsage {
+	return provider.tenants.snapshot()
+}
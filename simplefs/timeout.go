@@ -0,0 +1,70 @@
+package simplefs
+
+import (
+	"errors"
+	"time"
+)
+
+// errOpTimeout reports a disk operation abandoned after the configured deadline.
+var errOpTimeout = errors.New("disk operation timed out")
+
+// readResult is the read outcome handed back by the watchdog goroutine.
+type readResult struct {
+	content []byte
+	err     error
+}
+
+// readEntryFile reads an entry file, under a watchdog when read_timeout is
+// configured: on NFS or a failing disk a single read can hang indefinitely,
+// so after the timeout the wait is abandoned and treated as a miss, the
+// background goroutine result being discarded once it completes.
+func (provider *Simplefs) readEntryFile(path string) ([]byte, error) {
+	if provider.readTimeout <= 0 {
+		return provider.readFileRaw(path)
+	}
+
+	done := make(chan readResult, 1)
+
+	go func() {
+		content, err := provider.readFileRaw(path)
+		done <- readResult{content: content, err: err}
+	}()
+
+	timer := time.NewTimer(provider.readTimeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-done:
+		return result.content, result.err
+	case <-timer.C:
+		provider.logger.Errorf("Abandoned the read of the file %s after %s", path, provider.readTimeout)
+
+		return nil, errOpTimeout
+	}
+}
+
+// writeEntryFileTimed writes an entry file, under a watchdog when
+// write_timeout is configured, a timeout counting as a write failure.
+func (provider *Simplefs) writeEntryFileTimed(path string, data []byte) error {
+	if provider.writeTimeout <= 0 {
+		return provider.writeEntryFile(path, data)
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- provider.writeEntryFile(path, data)
+	}()
+
+	timer := time.NewTimer(provider.writeTimeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		provider.logger.Errorf("Abandoned the write of the file %s after %s", path, provider.writeTimeout)
+
+		return errOpTimeout
+	}
+}
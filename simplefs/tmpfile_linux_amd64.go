@@ -0,0 +1,90 @@
+//go:build linux && amd64
+
+package simplefs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+// O_TMPFILE constants on Linux amd64, not all exported by the syscall package.
+const (
+	oTmpfile        = 0x410000 // O_TMPFILE
+	atFdcwd         = -0x64    // AT_FDCWD
+	atSymlinkFollow = 0x400    // AT_SYMLINK_FOLLOW
+)
+
+// linkat wraps the linkat syscall, landing an anonymous temporary file at the
+// target path.
+func linkat(olddirfd int, oldpath string, newdirfd int, newpath string, flags int) error {
+	oldPtr, err := syscall.BytePtrFromString(oldpath)
+	if err != nil {
+		return err
+	}
+
+	newPtr, err := syscall.BytePtrFromString(newpath)
+	if err != nil {
+		return err
+	}
+
+	_, _, errno := syscall.Syscall6(
+		syscall.SYS_LINKAT,
+		uintptr(olddirfd),
+		uintptr(unsafe.Pointer(oldPtr)),
+		uintptr(newdirfd),
+		uintptr(unsafe.Pointer(newPtr)),
+		uintptr(flags),
+		0,
+	)
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
+
+// writeEntryFileTmpfile writes an anonymous O_TMPFILE file then linkats it to
+// the target path: an in-flight write never shows up in the directory, and a
+// crashed write leaves no garbage file behind.
+func (provider *Simplefs) writeEntryFileTmpfile(path string, data []byte) error {
+	fd, err := syscall.Open(filepath.Dir(path), oTmpfile|syscall.O_WRONLY|syscall.O_CLOEXEC, 0o644)
+	if err != nil {
+		// The filesystem (e.g. NFS, tmpfs on older kernels) lacks O_TMPFILE.
+		return fmt.Errorf("%w: %v", errTmpfileUnsupported, err)
+	}
+
+	file := os.NewFile(uintptr(fd), path)
+
+	if len(data) >= preallocateThreshold {
+		_ = preallocate(file, int64(len(data)))
+	}
+
+	if err := provider.writeFileData(file, data); err != nil {
+		_ = file.Close()
+
+		return err
+	}
+
+	// With sync_writes force the bytes to disk before landing in the directory.
+	if provider.syncWrites {
+		if err := file.Sync(); err != nil {
+			_ = file.Close()
+
+			return err
+		}
+	}
+
+	// linkat refuses to overwrite an existing file, remove the old entry first.
+	_ = os.Remove(path)
+
+	if err := linkat(atFdcwd, fmt.Sprintf("/proc/self/fd/%d", fd), atFdcwd, path, atSymlinkFollow); err != nil {
+		_ = file.Close()
+
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,9 @@
+//go:build !(linux && amd64)
+
+package simplefs
+
+// writeEntryFileTmpfile always reports unsupported on platforms without
+// O_TMPFILE, so the callers fall back to the temporary file plus rename write.
+func (provider *Simplefs) writeEntryFileTmpfile(_ string, _ []byte) error {
+	return errTmpfileUnsupported
+}
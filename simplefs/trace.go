@@ -0,0 +1,80 @@
+package simplefs
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/darkweak/storages/core"
+)
+
+// traceIDKey is the context key type of the request trace id.
+type traceIDKey struct{}
+
+// WithTraceID puts the request trace id into the context. The caller (the
+// Caddy module or a middleware) injects it before entering the storage layer,
+// so the logs and events produced by the storage operations correlate with
+// the upstream access log.
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, id)
+}
+
+// TraceIDFromContext returns the request trace id from the context, an empty
+// string without one.
+func TraceIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(traceIDKey{}).(string); ok {
+		return id
+	}
+
+	return ""
+}
+
+// TraceIDFromRequest extracts the trace id of a request: the value injected
+// into the context wins, falling back to the X-Request-Id header.
+func TraceIDFromRequest(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+
+	if id := TraceIDFromContext(req.Context()); id != "" {
+		return id
+	}
+
+	return req.Header.Get("X-Request-Id")
+}
+
+// traceLogger wraps a core.Logger, prepending the trace id to the formatted
+// log lines, so a slow cache read lines up with the upstream access log by id.
+type traceLogger struct {
+	core.Logger
+	id string
+}
+
+// Debugf logs at the debug level with the trace id.
+func (logger traceLogger) Debugf(template string, args ...interface{}) {
+	logger.Logger.Debugf("[trace %s] "+template, append([]interface{}{logger.id}, args...)...)
+}
+
+// Infof logs at the info level with the trace id.
+func (logger traceLogger) Infof(template string, args ...interface{}) {
+	logger.Logger.Infof("[trace %s] "+template, append([]interface{}{logger.id}, args...)...)
+}
+
+// Warnf logs at the warning level with the trace id.
+func (logger traceLogger) Warnf(template string, args ...interface{}) {
+	logger.Logger.Warnf("[trace %s] "+template, append([]interface{}{logger.id}, args...)...)
+}
+
+// Errorf logs at the error level with the trace id.
+func (logger traceLogger) Errorf(template string, args ...interface{}) {
+	logger.Logger.Errorf("[trace %s] "+template, append([]interface{}{logger.id}, args...)...)
+}
+
+// requestLogger returns the logger to use while serving a request: a wrapper
+// carrying the trace id when the request has one, the default logger otherwise.
+func (provider *Simplefs) requestLogger(req *http.Request) core.Logger {
+	if id := TraceIDFromRequest(req); id != "" {
+		return traceLogger{Logger: provider.logger, id: id}
+	}
+
+	return provider.logger
+}
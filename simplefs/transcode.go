@@ -0,0 +1,140 @@
+package simplefs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"sync"
+)
+
+// transcodeCache keeps a handful of recompressed variants, so a hot entry is
+// not recompressed on every request. Over capacity the oldest variants are
+// evicted in insertion order.
+type transcodeCache struct {
+	mu       sync.Mutex
+	entries  map[string][]byte
+	order    []string
+	capacity int
+}
+
+// newTranscodeCache creates the bounded recompressed-variant cache, a zero
+// capacity disables it.
+func newTranscodeCache(capacity int) *transcodeCache {
+	return &transcodeCache{
+		entries:  map[string][]byte{},
+		capacity: capacity,
+	}
+}
+
+// get returns the cached recompressed variant.
+func (cache *transcodeCache) get(key string) ([]byte, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	value, found := cache.entries[key]
+
+	return value, found
+}
+
+// put caches a recompressed variant, evicting the oldest entries when full.
+func (cache *transcodeCache) put(key string, value []byte) {
+	if cache.capacity <= 0 {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if _, found := cache.entries[key]; found {
+		cache.entries[key] = value
+
+		return
+	}
+
+	for len(cache.entries) >= cache.capacity && len(cache.order) > 0 {
+		delete(cache.entries, cache.order[0])
+		cache.order = cache.order[1:]
+	}
+
+	cache.entries[key] = value
+	cache.order = append(cache.order, key)
+}
+
+// drop cleans the recompressed variants when an entry is deleted or evicted.
+func (cache *transcodeCache) drop(key string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	prefix := key + "|"
+
+	for cached := range cache.entries {
+		if strings.HasPrefix(cached, prefix) {
+			delete(cache.entries, cached)
+		}
+	}
+}
+
+// GetTranscoded returns the entry content and the encoding actually used,
+// following the Accept-Encoding of the client. When the stored encoding
+// matches what the client accepts, the disk bytes are returned directly (zero
+// recompression); when the client accepts gzip but the storage uses the
+// internal compression (lz4), the decompress→gzip transcoding path runs and
+// the result lands in the small variant cache; everything else falls back to
+// the decompressed content.
+func (provider *Simplefs) GetTranscoded(key, acceptEncoding string) ([]byte, string) {
+	acceptsGzip := strings.Contains(acceptEncoding, "gzip")
+
+	// When the storage encoding is gzip and the client accepts it, the disk
+	// bytes (stripped of the entry header) go out as-is; dictionary deflate
+	// streams and encrypted entries are unreadable by the client and take the
+	// transcoding path.
+	if provider.compression == "gzip" && acceptsGzip &&
+		len(provider.compressionDict) == 0 && provider.encryption == nil {
+		if item := provider.cache.Get(key); item != nil {
+			if content, err := provider.fs.ReadFile(string(item.Value())); err == nil {
+				if method, body, ok := splitEntryHeader(content); ok {
+					if method == "gzip" {
+						return body, "gzip"
+					}
+				} else {
+					return content, "gzip"
+				}
+			}
+		}
+	}
+
+	if !acceptsGzip {
+		return provider.Get(key), ""
+	}
+
+	variantKey := key + "|gzip"
+	if cached, found := provider.transcoded.get(variantKey); found {
+		return cached, "gzip"
+	}
+
+	identity := provider.Get(key)
+	if identity == nil {
+		return nil, ""
+	}
+
+	var compressed bytes.Buffer
+
+	writer, err := gzip.NewWriterLevel(&compressed, provider.gzipLevel())
+	if err != nil {
+		return identity, ""
+	}
+
+	if _, err := writer.Write(identity); err != nil {
+		_ = writer.Close()
+
+		return identity, ""
+	}
+
+	if err := writer.Close(); err != nil {
+		return identity, ""
+	}
+
+	provider.transcoded.put(variantKey, compressed.Bytes())
+
+	return compressed.Bytes(), "gzip"
+}
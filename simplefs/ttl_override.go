@@ -0,0 +1,118 @@
+package simplefs
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ttlOverride describes one header-based TTL override rule.
+type ttlOverride struct {
+	header  string         // response header name to match
+	pattern *regexp.Regexp // pattern matched against the header value
+	ttl     time.Duration  // TTL applied on a match
+}
+
+// TTLOverrideFunc adjusts the TTL programmatically from the response headers.
+type TTLOverrideFunc func(headers http.Header, duration time.Duration) time.Duration
+
+// wildcardToRegexp converts a configured wildcard pattern (e.g. "image/*")
+// into an anchored regular expression.
+func wildcardToRegexp(pattern string) (*regexp.Regexp, error) {
+	escaped := regexp.QuoteMeta(pattern)
+	escaped = strings.ReplaceAll(escaped, `\*`, `.*`)
+
+	return regexp.Compile("^" + escaped + "$")
+}
+
+// parseTTLOverrides parses the list of TTL override rules from the configuration.
+func parseTTLOverrides(value interface{}) []ttlOverride {
+	rawRules, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	overrides := make([]ttlOverride, 0, len(rawRules))
+
+	for _, rawRule := range rawRules {
+		rule, ok := rawRule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		header, _ := rule["header"].(string)
+		patternValue, _ := rule["pattern"].(string)
+		ttlValue, _ := rule["ttl"].(string)
+
+		if header == "" || patternValue == "" || ttlValue == "" {
+			continue
+		}
+
+		pattern, err := wildcardToRegexp(patternValue)
+		if err != nil {
+			continue
+		}
+
+		ttl, err := time.ParseDuration(ttlValue)
+		if err != nil {
+			continue
+		}
+
+		overrides = append(overrides, ttlOverride{
+			header:  header,
+			pattern: pattern,
+			ttl:     ttl,
+		})
+	}
+
+	return overrides
+}
+
+// storedResponseHeaders parses the response headers out of the stored
+// response bytes, nil when the parsing fails.
+func storedResponseHeaders(value []byte) http.Header {
+	response, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(value)), nil)
+	if err != nil {
+		return nil
+	}
+
+	defer response.Body.Close()
+
+	return response.Header
+}
+
+// overrideTTL evaluates the TTL override rules in order, returning the TTL of
+// the first matching rule; the original duration applies without a match.
+// The callback takes precedence over the configured rules.
+func (provider *Simplefs) overrideTTL(value []byte, duration time.Duration) time.Duration {
+	if provider.ttlOverrideFunc == nil && len(provider.ttlOverrides) == 0 {
+		return duration
+	}
+
+	headers := storedResponseHeaders(value)
+	if headers == nil {
+		return duration
+	}
+
+	if provider.ttlOverrideFunc != nil {
+		return provider.ttlOverrideFunc(headers, duration)
+	}
+
+	for _, override := range provider.ttlOverrides {
+		if override.pattern.MatchString(headers.Get(override.header)) {
+			provider.logger.Debugf("TTL override rule %s=%s matched, adjusting the TTL to %v", override.header, override.pattern, override.ttl)
+
+			return override.ttl
+		}
+	}
+
+	return duration
+}
+
+// SetTTLOverrideFunc sets the callback adjusting the TTL programmatically.
+func (provider *Simplefs) SetTTLOverrideFunc(overrideFunc TTLOverrideFunc) {
+	provider.ttlOverrideFunc = overrideFunc
+}
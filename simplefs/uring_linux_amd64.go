@@ -0,0 +1,339 @@
+//go:build linux && amd64 && simplefs_iouring
+
+package simplefs
+
+import (
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+// io_uring constants on Linux amd64, not exported by the syscall package.
+const (
+	sysIoUringSetup = 425 // io_uring_setup
+	sysIoUringEnter = 426 // io_uring_enter
+
+	offSqRing = 0x0        // IORING_OFF_SQ_RING
+	offCqRing = 0x8000000  // IORING_OFF_CQ_RING
+	offSqes   = 0x10000000 // IORING_OFF_SQES
+
+	opRead  = 22 // IORING_OP_READ
+	opWrite = 23 // IORING_OP_WRITE
+
+	enterGetevents = 1 // IORING_ENTER_GETEVENTS
+
+	sqeSize = 64
+	cqeSize = 16
+
+	// uringMaxChunk caps the bytes of one submission, keeping the len field
+	// from overflowing.
+	uringMaxChunk = 1 << 30
+)
+
+// ioSqringOffsets mirrors the kernel struct io_sqring_offsets.
+type ioSqringOffsets struct {
+	head        uint32
+	tail        uint32
+	ringMask    uint32
+	ringEntries uint32
+	flags       uint32
+	dropped     uint32
+	array       uint32
+	resv1       uint32
+	userAddr    uint64
+}
+
+// ioCqringOffsets mirrors the kernel struct io_cqring_offsets.
+type ioCqringOffsets struct {
+	head        uint32
+	tail        uint32
+	ringMask    uint32
+	ringEntries uint32
+	overflow    uint32
+	cqes        uint32
+	flags       uint32
+	resv1       uint32
+	userAddr    uint64
+}
+
+// ioUringParams mirrors the kernel struct io_uring_params.
+type ioUringParams struct {
+	sqEntries    uint32
+	cqEntries    uint32
+	flags        uint32
+	sqThreadCPU  uint32
+	sqThreadIdle uint32
+	features     uint32
+	wqFd         uint32
+	resv         [3]uint32
+	sqOff        ioSqringOffsets
+	cqOff        ioCqringOffsets
+}
+
+// ioUringSqe mirrors the kernel struct io_uring_sqe.
+type ioUringSqe struct {
+	opcode      uint8
+	flags       uint8
+	ioprio      uint16
+	fd          int32
+	off         uint64
+	addr        uint64
+	len         uint32
+	opcodeFlags uint32
+	userData    uint64
+	bufIndex    uint16
+	personality uint16
+	spliceFdIn  int32
+	pad         [2]uint64
+}
+
+// ioUringCqe mirrors the kernel struct io_uring_cqe.
+type ioUringCqe struct {
+	userData uint64
+	res      int32
+	flags    uint32
+}
+
+// ioUring is a synchronous ring of submission depth 1: each call submits one
+// SQE under the lock and waits for its completion. No batched submissions and
+// no separate reaping goroutine — the only goal is to move the reads and
+// writes onto the io_uring submission path, batching can wait until simplefs
+// owns a real asynchronous queue.
+type ioUring struct {
+	mu sync.Mutex
+
+	fd     int
+	sqRing []byte
+	cqRing []byte
+	sqes   []byte
+
+	sqTail  *uint32
+	sqMask  *uint32
+	sqArray *uint32
+
+	cqHead  *uint32
+	cqTail  *uint32
+	cqMask  *uint32
+	cqesOff uintptr
+}
+
+var (
+	uringOnce   sync.Once
+	uringShared *ioUring
+	uringErr    error
+)
+
+// sharedUring lazily initializes the process-wide shared ring. The
+// initialization fails when the kernel lacks io_uring (ENOSYS) or the
+// container disables it (EPERM), and the callers fall back to the regular
+// reads and writes.
+func sharedUring() (*ioUring, error) {
+	uringOnce.Do(func() {
+		uringShared, uringErr = newIoUring()
+	})
+
+	return uringShared, uringErr
+}
+
+// newIoUring creates the ring and maps the SQ/CQ rings and the SQE array.
+func newIoUring() (*ioUring, error) {
+	var params ioUringParams
+
+	fd, _, errno := syscall.Syscall(sysIoUringSetup, 1, uintptr(unsafe.Pointer(&params)), 0)
+	if errno != 0 {
+		return nil, errno
+	}
+
+	ring := &ioUring{fd: int(fd)}
+
+	sqSize := int(params.sqOff.array + params.sqEntries*4)
+	cqSize := int(params.cqOff.cqes + params.cqEntries*cqeSize)
+
+	var err error
+
+	ring.sqRing, err = syscall.Mmap(ring.fd, offSqRing, sqSize,
+		syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED|syscall.MAP_POPULATE)
+	if err != nil {
+		_ = syscall.Close(ring.fd)
+
+		return nil, err
+	}
+
+	ring.cqRing, err = syscall.Mmap(ring.fd, offCqRing, cqSize,
+		syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED|syscall.MAP_POPULATE)
+	if err != nil {
+		_ = syscall.Munmap(ring.sqRing)
+		_ = syscall.Close(ring.fd)
+
+		return nil, err
+	}
+
+	ring.sqes, err = syscall.Mmap(ring.fd, offSqes, int(params.sqEntries)*sqeSize,
+		syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED|syscall.MAP_POPULATE)
+	if err != nil {
+		_ = syscall.Munmap(ring.cqRing)
+		_ = syscall.Munmap(ring.sqRing)
+		_ = syscall.Close(ring.fd)
+
+		return nil, err
+	}
+
+	ring.sqTail = (*uint32)(unsafe.Pointer(&ring.sqRing[params.sqOff.tail]))
+	ring.sqMask = (*uint32)(unsafe.Pointer(&ring.sqRing[params.sqOff.ringMask]))
+	ring.sqArray = (*uint32)(unsafe.Pointer(&ring.sqRing[params.sqOff.array]))
+	ring.cqHead = (*uint32)(unsafe.Pointer(&ring.cqRing[params.cqOff.head]))
+	ring.cqTail = (*uint32)(unsafe.Pointer(&ring.cqRing[params.cqOff.tail]))
+	ring.cqMask = (*uint32)(unsafe.Pointer(&ring.cqRing[params.cqOff.ringMask]))
+	ring.cqesOff = uintptr(params.cqOff.cqes)
+
+	return ring, nil
+}
+
+// do submits one SQE and blocks for its CQE, returning the kernel res (a
+// negative value is the negated errno, interpreted by the caller).
+func (r *ioUring) do(op uint8, fd int32, addr unsafe.Pointer, length uint32, off uint64) (int32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tail := atomic.LoadUint32(r.sqTail)
+	index := tail & *r.sqMask
+
+	sqe := (*ioUringSqe)(unsafe.Pointer(&r.sqes[uintptr(index)*sqeSize]))
+	*sqe = ioUringSqe{
+		opcode: op,
+		fd:     fd,
+		off:    off,
+		addr:   uint64(uintptr(addr)),
+		len:    length,
+	}
+
+	arrayEntry := (*uint32)(unsafe.Pointer(uintptr(unsafe.Pointer(r.sqArray)) + uintptr(index)*4))
+	*arrayEntry = index
+
+	atomic.StoreUint32(r.sqTail, tail+1)
+
+	toSubmit := uintptr(1)
+
+	for {
+		// After a successful submission phase io_uring_enter returns the
+		// submitted count rather than -EINTR, so EINTR means this call has
+		// not submitted any SQE yet and can simply be retried.
+		_, _, errno := syscall.Syscall6(sysIoUringEnter,
+			uintptr(r.fd), toSubmit, 1, enterGetevents, 0, 0)
+		if errno == syscall.EINTR {
+			continue
+		}
+
+		if errno != 0 {
+			return 0, errno
+		}
+
+		toSubmit = 0
+
+		head := atomic.LoadUint32(r.cqHead)
+		if head == atomic.LoadUint32(r.cqTail) {
+			continue
+		}
+
+		cqe := (*ioUringCqe)(unsafe.Pointer(&r.cqRing[r.cqesOff+uintptr(head&*r.cqMask)*cqeSize]))
+		res := cqe.res
+
+		atomic.StoreUint32(r.cqHead, head+1)
+
+		return res, nil
+	}
+}
+
+// readEntryFileUring reads a whole entry file through io_uring.
+func (provider *Simplefs) readEntryFileUring(path string) ([]byte, error) {
+	ring, err := sharedUring()
+	if err != nil {
+		return nil, errUringUnsupported
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	size := info.Size()
+	buffer := make([]byte, size)
+
+	var done int64
+
+	for done < size {
+		chunk := size - done
+		if chunk > uringMaxChunk {
+			chunk = uringMaxChunk
+		}
+
+		//nolint:gosec
+		res, err := ring.do(opRead, int32(file.Fd()), unsafe.Pointer(&buffer[done]), uint32(chunk), uint64(done))
+		if err != nil {
+			return nil, err
+		}
+
+		if res < 0 {
+			return nil, syscall.Errno(-res)
+		}
+
+		if res == 0 {
+			// The file is shorter than at Stat time, return what was read.
+			break
+		}
+
+		done += int64(res)
+	}
+
+	runtime.KeepAlive(buffer)
+
+	return buffer[:done], nil
+}
+
+// writeEntryFileUring writes the entry data to an opened file through io_uring.
+func (provider *Simplefs) writeEntryFileUring(file *os.File, data []byte) error {
+	ring, err := sharedUring()
+	if err != nil {
+		return errUringUnsupported
+	}
+
+	var done int64
+
+	for done < int64(len(data)) {
+		chunk := int64(len(data)) - done
+		if chunk > uringMaxChunk {
+			chunk = uringMaxChunk
+		}
+
+		//nolint:gosec
+		res, err := ring.do(opWrite, int32(file.Fd()), unsafe.Pointer(&data[done]), uint32(chunk), uint64(done))
+		if err != nil {
+			return err
+		}
+
+		if res < 0 {
+			return syscall.Errno(-res)
+		}
+
+		if res == 0 {
+			return syscall.EIO
+		}
+
+		done += int64(res)
+	}
+
+	runtime.KeepAlive(data)
+
+	return nil
+}
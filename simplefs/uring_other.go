@@ -0,0 +1,19 @@
+//go:build !(linux && amd64 && simplefs_iouring)
+
+package simplefs
+
+import "os"
+
+// readEntryFileUring always reports unsupported without the simplefs_iouring
+// build tag or on an unsupported platform, so the callers fall back to the
+// regular file read.
+func (provider *Simplefs) readEntryFileUring(_ string) ([]byte, error) {
+	return nil, errUringUnsupported
+}
+
+// writeEntryFileUring always reports unsupported without the simplefs_iouring
+// build tag or on an unsupported platform, so the callers fall back to the
+// regular write.
+func (provider *Simplefs) writeEntryFileUring(_ *os.File, _ []byte) error {
+	return errUringUnsupported
+}
@@ -0,0 +1,66 @@
+package simplefs
+
+import (
+	"sort"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"google.golang.org/protobuf/proto"
+)
+
+// enforceVariantCap evicts the extra variants by last access time (falling
+// back to the write time without an access record) when a mapping exceeds the
+// configured variant cap, so a misconfigured Vary header never spawns
+// unbounded variant entries under a single base key. It returns the possibly
+// rewritten mapping bytes.
+func (provider *Simplefs) enforceVariantCap(value []byte) []byte {
+	if provider.maxVariants <= 0 {
+		return value
+	}
+
+	mapping, err := core.DecodeMapping(value)
+	if err != nil || len(mapping.GetMapping()) <= provider.maxVariants {
+		return value
+	}
+
+	type variantAge struct {
+		key  string
+		used time.Time
+	}
+
+	variants := make([]variantAge, 0, len(mapping.GetMapping()))
+
+	for variedKey, keyIndex := range mapping.GetMapping() {
+		used := provider.access.last(variedKey)
+		if used.IsZero() {
+			used = keyIndex.GetStoredAt().AsTime()
+		}
+
+		variants = append(variants, variantAge{key: variedKey, used: used})
+	}
+
+	sort.Slice(variants, func(i, j int) bool {
+		return variants[i].used.Before(variants[j].used)
+	})
+
+	// Evict the least recently used variants until back under the cap.
+	for _, victim := range variants[:len(variants)-provider.maxVariants] {
+		delete(mapping.GetMapping(), victim.key)
+		provider.deleteKey(victim.key)
+		provider.variantOverflows.Add(1)
+		provider.logger.Warnf("The base key exceeds the variant cap %d, evicting the variant %s", provider.maxVariants, victim.key)
+	}
+
+	rewritten, err := proto.Marshal(mapping)
+	if err != nil {
+		return value // keep the original mapping when the re-encoding fails
+	}
+
+	return rewritten
+}
+
+// VariantOverflows returns the total number of variants evicted for
+// exceeding the variant cap.
+func (provider *Simplefs) VariantOverflows() int64 {
+	return provider.variantOverflows.Load()
+}
@@ -0,0 +1,105 @@
+package simplefs
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// varyOptions controls the normalization of the Vary-related request headers
+// before the variant matching, so irrelevant differences like casing or the
+// encoding token order never fragment the cache.
+type varyOptions struct {
+	casefold           bool                // lowercase the header values
+	sortAcceptEncoding bool                // sort the Accept-Encoding tokens
+	ignore             map[string]struct{} // header names ignored during the matching (lowercase)
+}
+
+// parseVaryOptions parses the Vary normalization options from the configuration.
+func parseVaryOptions(sfsconfig map[string]interface{}) varyOptions {
+	options := varyOptions{}
+
+	if v, found := sfsconfig["vary_casefold"]; found && v != nil {
+		if val, ok := v.(bool); ok {
+			options.casefold = val
+		}
+	}
+
+	if v, found := sfsconfig["vary_sort_accept_encoding"]; found && v != nil {
+		if val, ok := v.(bool); ok {
+			options.sortAcceptEncoding = val
+		}
+	}
+
+	if v, found := sfsconfig["vary_ignore"]; found && v != nil {
+		if list, ok := v.([]interface{}); ok {
+			options.ignore = map[string]struct{}{}
+			for _, entry := range list {
+				if name, ok := entry.(string); ok && name != "" {
+					options.ignore[strings.ToLower(name)] = struct{}{}
+				}
+			}
+		}
+	}
+
+	return options
+}
+
+// enabled reports whether any normalization behavior is configured.
+func (options varyOptions) enabled() bool {
+	return options.casefold || options.sortAcceptEncoding || len(options.ignore) > 0
+}
+
+// canonicalValue applies the normalization rules to a single header value.
+func (options varyOptions) canonicalValue(name, value string) string {
+	if options.sortAcceptEncoding && strings.EqualFold(name, "Accept-Encoding") {
+		tokens := strings.Split(value, ",")
+		for i := range tokens {
+			tokens[i] = strings.TrimSpace(tokens[i])
+		}
+
+		sort.Strings(tokens)
+		value = strings.Join(tokens, ", ")
+	}
+
+	if options.casefold {
+		value = strings.ToLower(value)
+	}
+
+	return value
+}
+
+// canonicalHeaders returns a copy of the headers with the normalization rules
+// applied, the ignored headers removed.
+func (options varyOptions) canonicalHeaders(headers http.Header) http.Header {
+	if !options.enabled() || headers == nil {
+		return headers
+	}
+
+	canonical := http.Header{}
+
+	for name, values := range headers {
+		if _, ignored := options.ignore[strings.ToLower(name)]; ignored {
+			continue
+		}
+
+		for _, value := range values {
+			canonical.Add(name, options.canonicalValue(name, value))
+		}
+	}
+
+	return canonical
+}
+
+// canonicalRequest returns a copy of the request with normalized headers, for
+// the mapping election comparisons.
+func (options varyOptions) canonicalRequest(req *http.Request) *http.Request {
+	if !options.enabled() {
+		return req
+	}
+
+	canonical := req.Clone(req.Context())
+	canonical.Header = options.canonicalHeaders(req.Header)
+
+	return canonical
+}
@@ -0,0 +1,55 @@
+package caddy
+
+import (
+	"net/http"
+
+	caddy "github.com/caddyserver/caddy/v2"
+	"github.com/caddyserver/caddy/v2/modules/caddyhttp"
+	"github.com/darkweak/storages/core"
+	"github.com/darkweak/storages/sqlite"
+)
+
+const moduleName = "sqlite"
+
+// Sqlite storage.
+type Sqlite struct {
+	// Keep the handler configuration.
+	core.Configuration
+}
+
+//nolint:gochecknoinits
+func init() {
+	caddy.RegisterModule(Sqlite{})
+}
+
+// CaddyModule returns the Caddy module information.
+func (Sqlite) CaddyModule() caddy.ModuleInfo {
+	return caddy.ModuleInfo{
+		ID:  "storages.cache.sqlite",
+		New: func() caddy.Module { return new(Sqlite) },
+	}
+}
+
+// Provision to do the provisioning part.
+func (b *Sqlite) Provision(ctx caddy.Context) error {
+	logger := ctx.Logger(b)
+	storer, err := sqlite.Factory(b.Configuration.Provider, logger.Sugar(), b.Configuration.Stale)
+
+	if err != nil {
+		return err
+	}
+
+	core.RegisterStorage(storer)
+
+	return nil
+}
+
+func (b *Sqlite) ServeHTTP(rw http.ResponseWriter, rq *http.Request, next caddyhttp.Handler) error {
+	return next.ServeHTTP(rw, rq)
+}
+
+// Interface guards.
+var (
+	_ caddy.Provisioner           = (*Sqlite)(nil)
+	_ caddyhttp.MiddlewareHandler = (*Sqlite)(nil)
+)
@@ -0,0 +1,475 @@
+package sqlite
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	lz4 "github.com/pierrec/lz4/v4"
+	_ "modernc.org/sqlite" // CGO-free SQLite driver.
+)
+
+const (
+	// cleanupInterval is the pause between two expired keys cleanup passes.
+	cleanupInterval = time.Minute
+	// cleanupBatch bounds the deletions per cleanup transaction so the
+	// writer lock is never held long and the WAL stays small.
+	cleanupBatch = 4096
+	// defaultBusyTimeout is applied via PRAGMA busy_timeout so concurrent
+	// workers back off instead of failing with SQLITE_BUSY.
+	defaultBusyTimeout = 5 * time.Second
+	// defaultVacuumInterval is the pause between two incremental vacuum
+	// passes when auto_vacuum is INCREMENTAL.
+	defaultVacuumInterval = time.Hour
+	// defaultVacuumPages bounds the pages reclaimed per incremental vacuum
+	// pass so reclaiming space never stalls the request path.
+	defaultVacuumPages = 1024
+)
+
+// Sqlite provider type.
+type Sqlite struct {
+	*sql.DB
+	stale          time.Duration
+	logger         core.Logger
+	uuid           string
+	vacuumInterval time.Duration
+	vacuumPages    int
+}
+
+// parseStringOption reads a string from the configuration map.
+func parseStringOption(config map[string]interface{}, name, fallback string) string {
+	if value, ok := config[name]; ok {
+		if v, ok := value.(string); ok {
+			return v
+		}
+	}
+
+	return fallback
+}
+
+// parseDurationOption reads a duration from the configuration map, accepting
+// a duration string or a number of seconds.
+func parseDurationOption(config map[string]interface{}, name string, fallback time.Duration, logger core.Logger) time.Duration {
+	value, ok := config[name]
+	if !ok {
+		return fallback
+	}
+
+	switch durationValue := value.(type) {
+	case int:
+		return time.Duration(durationValue) * time.Second
+	case float64:
+		return time.Duration(durationValue) * time.Second
+	case string:
+		duration, err := time.ParseDuration(durationValue)
+		if err != nil {
+			logger.Errorf("Impossible to parse the %s value %v: %v", name, value, err)
+
+			return fallback
+		}
+
+		return duration
+	default:
+		return fallback
+	}
+}
+
+// parseIntOption reads an integer from the configuration map.
+func parseIntOption(config map[string]interface{}, name string, fallback int) int {
+	if value, ok := config[name]; ok {
+		switch v := value.(type) {
+		case int:
+			return v
+		case float64:
+			return int(v)
+		}
+	}
+
+	return fallback
+}
+
+// autoVacuumValue maps the auto_vacuum configuration names to the pragma
+// values; the pragma only takes effect on a new database or after a full
+// VACUUM, which SQLite handles by itself.
+func autoVacuumValue(name string) (string, bool) {
+	switch strings.ToUpper(name) {
+	case "", "NONE":
+		return "0", false
+	case "FULL":
+		return "1", false
+	case "INCREMENTAL":
+		return "2", true
+	default:
+		return "0", false
+	}
+}
+
+// Factory function create new Sqlite instance.
+func Factory(sqliteConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	path := "/tmp/souin-sqlite.db"
+	if sqliteConfiguration.Path != "" {
+		path = sqliteConfiguration.Path
+	}
+
+	journalMode := "WAL"
+	synchronous := "NORMAL"
+	busyTimeout := defaultBusyTimeout
+	autoVacuum := "NONE"
+	vacuumInterval := defaultVacuumInterval
+	vacuumPages := defaultVacuumPages
+
+	if sqliteConfig, ok := sqliteConfiguration.Configuration.(map[string]interface{}); ok && sqliteConfig != nil {
+		path = parseStringOption(sqliteConfig, "path", path)
+		journalMode = parseStringOption(sqliteConfig, "journal_mode", journalMode)
+		synchronous = parseStringOption(sqliteConfig, "synchronous", synchronous)
+		busyTimeout = parseDurationOption(sqliteConfig, "busy_timeout", busyTimeout, logger)
+		autoVacuum = parseStringOption(sqliteConfig, "auto_vacuum", autoVacuum)
+		vacuumInterval = parseDurationOption(sqliteConfig, "incremental_vacuum_interval", vacuumInterval, logger)
+		vacuumPages = parseIntOption(sqliteConfig, "incremental_vacuum_pages", vacuumPages)
+	}
+
+	vacuumValue, incremental := autoVacuumValue(autoVacuum)
+
+	// The pragmas ride the DSN so every pooled connection gets them, not
+	// only the first one.
+	dsn := "file:" + path + "?" + url.Values{
+		"_pragma": []string{
+			fmt.Sprintf("busy_timeout(%d)", busyTimeout.Milliseconds()),
+			fmt.Sprintf("journal_mode(%s)", journalMode),
+			fmt.Sprintf("synchronous(%s)", synchronous),
+			fmt.Sprintf("auto_vacuum(%s)", vacuumValue),
+		},
+	}.Encode()
+
+	database, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		logger.Error("Impossible to open the SQLite DB.", err)
+
+		return nil, err
+	}
+
+	if _, err := database.Exec(`CREATE TABLE IF NOT EXISTS souin (
+		key TEXT PRIMARY KEY,
+		value BLOB NOT NULL,
+		expires_at INTEGER NOT NULL DEFAULT 0
+	)`); err != nil {
+		logger.Error("Impossible to create the SQLite schema.", err)
+
+		return nil, err
+	}
+
+	// The expiry cleanup below walks this index, never the whole table.
+	if _, err := database.Exec(`CREATE INDEX IF NOT EXISTS souin_expires_at
+		ON souin (expires_at) WHERE expires_at > 0`); err != nil {
+		logger.Error("Impossible to create the SQLite expiry index.", err)
+
+		return nil, err
+	}
+
+	instance := &Sqlite{
+		DB:             database,
+		stale:          stale,
+		logger:         logger,
+		uuid:           fmt.Sprintf("%s-%s", path, stale),
+		vacuumInterval: vacuumInterval,
+		vacuumPages:    vacuumPages,
+	}
+
+	instance.startCleanup()
+
+	if incremental {
+		instance.startIncrementalVacuum()
+	}
+
+	return instance, nil
+}
+
+// startCleanup launches the background loop removing expired keys in batched
+// transactions, so the database file does not grow unbounded between vacuums.
+func (provider *Sqlite) startCleanup() {
+	go func() {
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			provider.cleanupExpired()
+		}
+	}()
+}
+
+// cleanupExpired removes expired keys, at most cleanupBatch per transaction,
+// looping until the backlog is drained.
+func (provider *Sqlite) cleanupExpired() {
+	for {
+		result, err := provider.DB.Exec(`DELETE FROM souin WHERE key IN (
+			SELECT key FROM souin WHERE expires_at > 0 AND expires_at < ? LIMIT ?
+		)`, time.Now().UnixNano(), cleanupBatch)
+		if err != nil {
+			provider.logger.Errorf("Impossible to cleanup the expired SQLite keys, %v", err)
+
+			return
+		}
+
+		removed, _ := result.RowsAffected()
+		if removed > 0 {
+			provider.logger.Debugf("Removed %d expired keys from SQLite", removed)
+		}
+
+		if removed < cleanupBatch {
+			return
+		}
+	}
+}
+
+// startIncrementalVacuum launches the loop reclaiming freelist pages with a
+// bounded page budget per pass.
+func (provider *Sqlite) startIncrementalVacuum() {
+	go func() {
+		ticker := time.NewTicker(provider.vacuumInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := provider.DB.Exec(fmt.Sprintf("PRAGMA incremental_vacuum(%d)", provider.vacuumPages)); err != nil {
+				provider.logger.Errorf("Impossible to run the SQLite incremental vacuum, %v", err)
+			}
+		}
+	}()
+}
+
+// Name returns the storer name.
+func (provider *Sqlite) Name() string {
+	return "SQLITE"
+}
+
+// Uuid returns an unique identifier.
+func (provider *Sqlite) Uuid() string {
+	return provider.uuid
+}
+
+// ListKeys method returns the list of existing keys.
+func (provider *Sqlite) ListKeys() []string {
+	keys := []string{}
+
+	rows, err := provider.DB.Query(`SELECT value FROM souin
+		WHERE substr(key, 1, ?) = ? AND (expires_at = 0 OR expires_at > ?)`,
+		len(core.MappingKeyPrefix), core.MappingKeyPrefix, time.Now().UnixNano())
+	if err != nil {
+		return keys
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var value []byte
+		if err := rows.Scan(&value); err != nil {
+			continue
+		}
+
+		mapping, err := core.DecodeMapping(value)
+		if err == nil {
+			for _, v := range mapping.GetMapping() {
+				keys = append(keys, v.GetRealKey())
+			}
+		}
+	}
+
+	return keys
+}
+
+// MapKeys method returns the map of existing keys.
+func (provider *Sqlite) MapKeys(prefix string) map[string]string {
+	keys := map[string]string{}
+
+	rows, err := provider.DB.Query(`SELECT key, value FROM souin
+		WHERE substr(key, 1, ?) = ? AND (expires_at = 0 OR expires_at > ?)`,
+		len(prefix), prefix, time.Now().UnixNano())
+	if err != nil {
+		return keys
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			key   string
+			value []byte
+		)
+
+		if err := rows.Scan(&key, &value); err != nil {
+			continue
+		}
+
+		k, _ := strings.CutPrefix(key, prefix)
+		keys[k] = string(value)
+	}
+
+	return keys
+}
+
+// Get method returns the populated response if exists, empty response then.
+func (provider *Sqlite) Get(key string) []byte {
+	var (
+		value     []byte
+		expiresAt int64
+	)
+
+	err := provider.DB.QueryRow("SELECT value, expires_at FROM souin WHERE key = ?", key).Scan(&value, &expiresAt)
+	if err != nil {
+		return nil
+	}
+
+	if expiresAt != 0 && time.Now().UnixNano() > expiresAt {
+		return nil
+	}
+
+	return value
+}
+
+// Set method will store the response in Sqlite provider.
+func (provider *Sqlite) Set(key string, value []byte, duration time.Duration) error {
+	var expiresAt int64
+	if duration > 0 {
+		expiresAt = time.Now().Add(duration).UnixNano()
+	}
+
+	_, err := provider.DB.Exec(`INSERT INTO souin (key, value, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at`,
+		key, value, expiresAt)
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s into SQLite, %v", key, err)
+	}
+
+	return err
+}
+
+// Delete method will delete the response in Sqlite provider if exists
+// corresponding to key param.
+func (provider *Sqlite) Delete(key string) {
+	_, _ = provider.DB.Exec("DELETE FROM souin WHERE key = ?", key)
+}
+
+// DeleteMany method will delete the responses in Sqlite provider if exists
+// corresponding to the regex key param.
+func (provider *Sqlite) DeleteMany(key string) {
+	rgKey, e := regexp.Compile(key)
+	if e != nil {
+		return
+	}
+
+	rows, err := provider.DB.Query("SELECT key FROM souin")
+	if err != nil {
+		return
+	}
+
+	matching := []string{}
+
+	for rows.Next() {
+		var storedKey string
+		if err := rows.Scan(&storedKey); err != nil {
+			continue
+		}
+
+		if rgKey.MatchString(storedKey) {
+			matching = append(matching, storedKey)
+		}
+	}
+
+	rows.Close()
+
+	for _, storedKey := range matching {
+		provider.Delete(storedKey)
+	}
+
+	core.CleanMappings(provider, rgKey.MatchString, provider.logger)
+}
+
+// Init method will.
+func (provider *Sqlite) Init() error {
+	return nil
+}
+
+// Reset method will reset or close provider.
+func (provider *Sqlite) Reset() error {
+	_, err := provider.DB.Exec("DELETE FROM souin")
+
+	return err
+}
+
+// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
+func (provider *Sqlite) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	value := provider.Get(core.MappingKeyPrefix + key)
+	if len(value) == 0 {
+		return fresh, stale
+	}
+
+	fresh, stale, _ = core.MappingElection(provider, value, req, validator, provider.logger)
+
+	return fresh, stale
+}
+
+// SetMultiLevel tries to store the key with the given value and update the
+// mapping key to store metadata. Both writes share one transaction, so a
+// crash can never leave a mapping pointing at a missing entry.
+func (provider *Sqlite) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	now := time.Now()
+
+	compressed := new(bytes.Buffer)
+	if _, err := lz4.NewWriter(compressed).ReadFrom(bytes.NewReader(value)); err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into SQLite, %v", variedKey, err)
+
+		return err
+	}
+
+	transaction, err := provider.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = transaction.Rollback()
+	}()
+
+	if _, err := transaction.Exec(`INSERT INTO souin (key, value, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at`,
+		variedKey, compressed.Bytes(), now.Add(duration+provider.stale).UnixNano()); err != nil {
+		return err
+	}
+
+	mappingKey := core.MappingKeyPrefix + baseKey
+
+	var item []byte
+
+	var expiresAt int64
+
+	err = transaction.QueryRow("SELECT value, expires_at FROM souin WHERE key = ?", mappingKey).Scan(&item, &expiresAt)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	val, err := core.MappingUpdaterWithLastModified(variedKey, item, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, core.ResponseLastModified(value), realKey)
+	if err != nil {
+		return err
+	}
+
+	if _, err := transaction.Exec(`INSERT INTO souin (key, value, expires_at) VALUES (?, ?, 0)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at`,
+		mappingKey, val); err != nil {
+		return err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		provider.logger.Errorf("Impossible to set value into SQLite, %v", err)
+
+		return err
+	}
+
+	return nil
+}
+
+var _ core.Storer = (*Sqlite)(nil)
@@ -0,0 +1,171 @@
+package sqlite_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/darkweak/storages/sqlite"
+	"go.uber.org/zap"
+)
+
+const (
+	byteKey        = "MyByteKey"
+	nonExistentKey = "NonExistentKey"
+	baseValue      = "My first data"
+)
+
+func getSqliteInstance(t *testing.T) (core.Storer, error) {
+	t.Helper()
+
+	return sqlite.Factory(core.CacheProvider{Path: filepath.Join(t.TempDir(), "souin-sqlite-test.db")}, zap.NewNop().Sugar(), 0)
+}
+
+func TestSqliteConnectionFactory(t *testing.T) {
+	instance, err := getSqliteInstance(t)
+
+	if nil != err {
+		t.Error("Shouldn't have panic")
+	}
+
+	if nil == instance {
+		t.Error("Sqlite should be instanciated")
+	}
+
+	if nil == instance.(*sqlite.Sqlite).DB {
+		t.Error("Sqlite database should be accesible")
+	}
+}
+
+func TestSqliteConnectionFactory_Configuration(t *testing.T) {
+	instance, err := sqlite.Factory(core.CacheProvider{
+		Configuration: map[string]interface{}{
+			"path":                        filepath.Join(t.TempDir(), "souin-sqlite-test.db"),
+			"journal_mode":                "WAL",
+			"busy_timeout":                "2s",
+			"synchronous":                 "FULL",
+			"auto_vacuum":                 "INCREMENTAL",
+			"incremental_vacuum_interval": "30m",
+			"incremental_vacuum_pages":    256,
+		},
+	}, zap.NewNop().Sugar(), 0)
+
+	if nil != err {
+		t.Errorf("The configured factory should not fail: %v", err)
+	}
+
+	if nil == instance {
+		t.Error("Sqlite should be instanciated")
+	}
+}
+
+func TestIShouldBeAbleToReadAndWriteDataInSqlite(t *testing.T) {
+	client, _ := getSqliteInstance(t)
+
+	_ = client.Set("Test", []byte(baseValue), time.Duration(20)*time.Second)
+
+	res := client.Get("Test")
+	if len(res) == 0 {
+		t.Errorf("Key %s should exist", baseValue)
+	}
+
+	if baseValue != string(res) {
+		t.Errorf("%s not corresponding to %s", string(res), baseValue)
+	}
+}
+
+func TestSqlite_GetRequestInCache(t *testing.T) {
+	client, _ := getSqliteInstance(t)
+	res := client.Get(nonExistentKey)
+
+	if 0 < len(res) {
+		t.Errorf("Key %s should not exist", nonExistentKey)
+	}
+}
+
+func TestSqlite_GetSetRequestInCache_OneByte(t *testing.T) {
+	client, _ := getSqliteInstance(t)
+	_ = client.Set(byteKey, []byte("A"), time.Duration(20)*time.Second)
+
+	res := client.Get(byteKey)
+	if len(res) == 0 {
+		t.Errorf("Key %s should exist", byteKey)
+	}
+
+	if string(res) != "A" {
+		t.Errorf("%s not corresponding to %v", res, 65)
+	}
+}
+
+func TestSqlite_DeleteRequestInCache(t *testing.T) {
+	client, _ := getSqliteInstance(t)
+	_ = client.Set(byteKey, []byte("A"), time.Duration(20)*time.Second)
+
+	client.Delete(byteKey)
+
+	if res := client.Get(byteKey); 0 < len(res) {
+		t.Errorf("Key %s should not exist", byteKey)
+	}
+}
+
+func TestSqlite_ExpiredKeyIsNotServed(t *testing.T) {
+	client, _ := getSqliteInstance(t)
+	_ = client.Set("ExpiredKey", []byte(baseValue), time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+
+	if res := client.Get("ExpiredKey"); 0 < len(res) {
+		t.Error("Key ExpiredKey should have expired")
+	}
+}
+
+const maxCounter = 10
+
+func TestSqlite_MapKeys(t *testing.T) {
+	client, _ := getSqliteInstance(t)
+	prefix := "MAP_KEYS_PREFIX_"
+
+	keys := client.MapKeys(prefix)
+	if len(keys) != 0 {
+		t.Error("The map should be empty")
+	}
+
+	for i := 0; i < maxCounter; i++ {
+		_ = client.Set(fmt.Sprintf("%s%d", prefix, i), []byte(fmt.Sprintf("Hello from %d", i)), time.Minute)
+	}
+
+	keys = client.MapKeys(prefix)
+
+	if len(keys) != maxCounter {
+		t.Errorf("The map should contain %d elements, %d given", maxCounter, len(keys))
+	}
+
+	for k, v := range keys {
+		if v != fmt.Sprintf("Hello from %s", k) {
+			t.Errorf("Expected Hello from %s, %s given", k, v)
+		}
+	}
+}
+
+func TestSqlite_DeleteManyRequestInCache(t *testing.T) {
+	client, _ := getSqliteInstance(t)
+
+	for i := 0; i < maxCounter; i++ {
+		_ = client.Set(fmt.Sprintf("DELETE_MANY_%d", i), []byte(baseValue), time.Minute)
+	}
+
+	_ = client.Set("Survivor", []byte(baseValue), time.Minute)
+
+	client.DeleteMany("DELETE_MANY_.*")
+
+	for i := 0; i < maxCounter; i++ {
+		if res := client.Get(fmt.Sprintf("DELETE_MANY_%d", i)); 0 < len(res) {
+			t.Errorf("Key DELETE_MANY_%d should not exist", i)
+		}
+	}
+
+	if res := client.Get("Survivor"); len(res) == 0 {
+		t.Error("Key Survivor should still exist")
+	}
+}